@@ -1,14 +1,21 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 	_ "github.com/lib/pq"
 	"hotel-booking-service/internal/app/config"
 	"hotel-booking-service/internal/app/connections"
 	"hotel-booking-service/internal/app/start"
 	"hotel-booking-service/internal/app/store"
+	"hotel-booking-service/internal/jobs"
 )
 
 func main() {
@@ -25,12 +32,30 @@ func main() {
 	log.Println("Connected to database")
 	
 	appStore := store.NewStore(db)
+	scheduler := jobs.NewScheduler()
 	
-	router := start.SetupRoutes(cfg, appStore)
+	router := start.SetupRoutes(cfg, appStore, scheduler)
+	
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	
+	scheduler.Start(ctx)
 	
 	addr := fmt.Sprintf(":%s", cfg.Server.Port)
+	server := &http.Server{Addr: addr, Handler: router}
+	
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+	
 	log.Printf("Starting server on %s", addr)
-	if err := http.ListenAndServe(addr, router); err != nil {
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Fatalf("Failed to start server: %v", err)
 	}
-}
\ No newline at end of file
+	
+	scheduler.Wait()
+	log.Println("Server stopped")
+}
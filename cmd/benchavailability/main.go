@@ -0,0 +1,123 @@
+// Command benchavailability times the hotel availability reads against a
+// seeded dataset. It compares loading each hotel's available rooms with a
+// query per hotel, as listings used to, with the set-based reads the
+// service makes now, whose query count does not grow with the number of
+// hotels. The benchmarks in internal/usecases run the same reads under go
+// test -bench.
+//
+// Point it at a scratch database that has been migrated but has no hotels
+// or users; with -seed it fills it first:
+//
+//	DB_NAME=hotel_booking_bench go run ./cmd/benchavailability -seed -hotels 2000
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"hotel-booking-service/internal/app/benchseed"
+	"hotel-booking-service/internal/app/config"
+	"hotel-booking-service/internal/app/connections"
+	"hotel-booking-service/internal/app/store"
+	"hotel-booking-service/internal/data"
+	"hotel-booking-service/internal/services"
+	"hotel-booking-service/internal/usecases"
+)
+
+func main() {
+	seed := flag.Bool("seed", false, "seed the database first; it must have no hotels")
+	hotels := flag.Int("hotels", 2000, "hotels to seed")
+	types := flag.Int("types", 3, "room types to seed per hotel")
+	rooms := flag.Int("rooms", 10, "rooms to seed per room type")
+	nights := flag.Int("nights", 3, "length of the searched stay")
+	runs := flag.Int("runs", 5, "times to run each read")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	db, err := connections.NewPostgresConnection(cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	if *seed {
+		start := time.Now()
+		if err := benchseed.Seed(db, *hotels, *types, *rooms); err != nil {
+			log.Fatalf("Failed to seed database: %v", err)
+		}
+		log.Printf("Seeded %d hotels in %s", *hotels, time.Since(start).Round(time.Millisecond))
+	}
+
+	appStore := store.NewStore(db)
+	currencyUsecase := usecases.NewCurrencyUsecase(appStore.RateRepo)
+	restrictionUsecase := usecases.NewRestrictionUsecase(appStore.RestrictRepo, appStore.HotelRepo, appStore.RoomTypeRepo)
+	hotelUsecase := usecases.NewHotelUsecase(appStore.HotelRepo, appStore.RoomRepo, appStore.RoomTypeRepo, appStore.AmenityRepo, currencyUsecase, restrictionUsecase)
+	hotelService := services.NewHotelService(appStore.HotelRepo, appStore.RoomRepo)
+
+	fromDate := time.Now().AddDate(0, 0, 1)
+	toDate := fromDate.AddDate(0, 0, *nights)
+
+	listed, err := appStore.HotelRepo.GetAllHotels(data.HotelFilter{})
+	if err != nil {
+		log.Fatalf("Failed to list hotels: %v", err)
+	}
+	if len(listed) == 0 {
+		log.Fatal("The database has no hotels; run with -seed")
+	}
+
+	benchmarks := []struct {
+		name string
+		run  func() error
+	}{
+		{"rooms, one query per hotel", func() error {
+			hotels, err := appStore.HotelRepo.GetAllHotels(data.HotelFilter{})
+			if err != nil {
+				return err
+			}
+			for _, hotel := range hotels {
+				if _, err := appStore.RoomRepo.GetAvailableRoomsByHotelID(hotel.ID, fromDate, toDate); err != nil {
+					return err
+				}
+			}
+			return nil
+		}},
+		{"rooms, set-based (HotelService)", func() error {
+			_, err := hotelService.GetAllHotels(fromDate, toDate)
+			return err
+		}},
+		{"hotels with types and rooms (GET /hotels)", func() error {
+			_, err := hotelUsecase.GetAllHotels(fromDate, toDate, "", data.HotelFilter{})
+			return err
+		}},
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%d hotels, %d-night stay, %d runs\n\n", len(listed), *nights, *runs)
+	fmt.Fprintln(w, "read\tfastest\tmedian")
+	for _, benchmark := range benchmarks {
+		durations := make([]time.Duration, 0, *runs)
+		for i := 0; i < *runs; i++ {
+			start := time.Now()
+			if err := benchmark.run(); err != nil {
+				log.Fatalf("%s failed: %v", benchmark.name, err)
+			}
+			durations = append(durations, time.Since(start))
+		}
+		sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+		fmt.Fprintf(w, "%s\t%s\t%s\n",
+			benchmark.name,
+			durations[0].Round(time.Microsecond),
+			durations[len(durations)/2].Round(time.Microsecond),
+		)
+	}
+	w.Flush()
+}
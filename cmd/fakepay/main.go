@@ -0,0 +1,40 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+
+	"hotel-booking-service/internal/payments/fakepay"
+)
+
+func main() {
+	port := getEnv("FAKEPAY_PORT", "8090")
+	publicURL := getEnv("FAKEPAY_PUBLIC_URL", "http://localhost:"+port)
+	webhookURL := getEnv("FAKEPAY_WEBHOOK_URL", "http://localhost:8080/webhooks/payments")
+
+	webhookSecret := os.Getenv("PAYMENT_WEBHOOK_SECRET")
+	if webhookSecret == "" {
+		log.Fatal("PAYMENT_WEBHOOK_SECRET must be set")
+	}
+
+	server := fakepay.NewServer(
+		publicURL,
+		os.Getenv("PAYMENT_API_KEY"),
+		webhookSecret,
+		fakepay.HTTPNotifier(webhookURL),
+	)
+
+	log.Printf("Fake payment provider listening on :%s, sending webhooks to %s", port, webhookURL)
+	if err := http.ListenAndServe(":"+port, server.Handler()); err != nil {
+		log.Fatalf("Failed to start fake payment provider: %v", err)
+	}
+}
+
+func getEnv(key, defaultValue string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
@@ -0,0 +1,70 @@
+// Package benchseed fills a scratch database with a dataset for timing the
+// availability reads, shared by cmd/benchavailability and the benchmarks.
+package benchseed
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Seed adds hotels with room types and rooms, and books about half of the
+// rooms over the next week so that availability has work to do. The
+// database must have been migrated and have no hotels or users, so that
+// every run times the same data.
+func Seed(db *sql.DB, hotels, types, rooms int) error {
+	var existingHotels, existingUsers int
+	err := db.QueryRow(`SELECT (SELECT COUNT(*) FROM hotels), (SELECT COUNT(*) FROM users)`).Scan(&existingHotels, &existingUsers)
+	if err != nil {
+		return err
+	}
+	if existingHotels > 0 || existingUsers > 0 {
+		return fmt.Errorf("the database already has %d hotels and %d users; seed an empty one", existingHotels, existingUsers)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	steps := []struct {
+		query string
+		args  []interface{}
+	}{
+		{`INSERT INTO hotels (name, city, currency)
+			SELECT 'Bench Hotel ' || i, 'Bench City ' || (i % 50), 'USD'
+			FROM generate_series(1, $1) AS i`, []interface{}{hotels}},
+		{`INSERT INTO room_types (hotel_id, name, capacity, price_amount)
+			SELECT h.id, 'Type ' || k, 1 + k % 4, 5000 + k * 2500 + (h.id % 20) * 500
+			FROM hotels h CROSS JOIN generate_series(1, $1) AS k`, []interface{}{types}},
+		{`INSERT INTO rooms (hotel_id, room_type_id, number)
+			SELECT t.hotel_id, t.id, t.id || '-' || n
+			FROM room_types t CROSS JOIN generate_series(1, $1) AS n`, []interface{}{rooms}},
+		{`INSERT INTO users (email, password, name)
+			VALUES ('bench@example.com', '-', 'Bench Guest')`, nil},
+		{`INSERT INTO bookings (confirmation_code, user_id, room_type_id, room_id, from_date, to_date, status)
+			SELECT 'BEN-' || r.id, u.id, r.room_type_id, r.id,
+				CURRENT_DATE + 1 + r.id % 5, CURRENT_DATE + 3 + r.id % 5, 'confirmed'
+			FROM rooms r CROSS JOIN users u
+			WHERE u.email = 'bench@example.com' AND r.id % 2 = 0`, nil},
+	}
+	for _, step := range steps {
+		if _, err := tx.Exec(step.query, step.args...); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`ANALYZE`)
+	return err
+}
+
+// Clear removes what Seed added, and everything else hanging off hotels
+// and users, leaving the database empty for the next run.
+func Clear(db *sql.DB) error {
+	_, err := db.Exec(`TRUNCATE hotels, users RESTART IDENTITY CASCADE`)
+	return err
+}
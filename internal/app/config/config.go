@@ -3,20 +3,32 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 	"time"
 	
 	"github.com/joho/godotenv"
 	"hotel-booking-service/internal/app/connections"
+	"hotel-booking-service/internal/notifications"
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database connections.PostgresConfig
-	JWT      JWTConfig
+	Server      ServerConfig
+	Database    connections.PostgresConfig
+	JWT         JWTConfig
+	Payments    PaymentsConfig
+	Waitlist    WaitlistConfig
+	Lookup      LookupConfig
+	Idempotency IdempotencyConfig
+	Loyalty     LoyaltyConfig
+	Messages    MessagesConfig
+	SMTP        notifications.SMTPConfig
 }
 
+// ServerConfig.PublicURL is where clients reach the service, used to build
+// the links sent in emails.
 type ServerConfig struct {
-	Port string
+	Port      string
+	PublicURL string
 }
 
 type JWTConfig struct {
@@ -24,6 +36,51 @@ type JWTConfig struct {
 	TokenExpiry  time.Duration
 }
 
+// PaymentsConfig points at the payment provider. With no ProviderURL and
+// EmbeddedFake set, the fake provider is served from this process under
+// /fakepay.
+type PaymentsConfig struct {
+	ProviderURL string
+	APIKey      string
+	// WebhookSecret has no default; the service does not start without it.
+	WebhookSecret string
+	// EmbeddedFake runs the fake gateway inside the service when no
+	// ProviderURL is set. It is for development only.
+	EmbeddedFake bool
+	PendingTTL   time.Duration
+}
+
+type WaitlistConfig struct {
+	OfferTTL time.Duration
+}
+
+// LookupConfig limits the public booking lookup per client address.
+type LookupConfig struct {
+	RatePerMinute int
+}
+
+// IdempotencyConfig.KeyTTL is how long responses are kept for replay to
+// requests retried with the same Idempotency-Key.
+type IdempotencyConfig struct {
+	KeyTTL time.Duration
+}
+
+// LoyaltyConfig.ExpiryMonths is how long a member may go without earning
+// or spending points before their balance expires. Points are earned and
+// redeemed in Currency, whatever the hotel charges in.
+type LoyaltyConfig struct {
+	ExpiryMonths int
+	Currency     string
+}
+
+// MessagesConfig sets where message attachments are kept and how large each
+// may be. StaffEmail, if set, is told about new messages from guests.
+type MessagesConfig struct {
+	AttachmentDir      string
+	MaxAttachmentBytes int64
+	StaffEmail         string
+}
+
 func LoadConfig() (*Config, error) {
 	if err := godotenv.Load(); err != nil {
 		if _, ok := os.LookupEnv("APP_ENV"); !ok {
@@ -35,9 +92,18 @@ func LoadConfig() (*Config, error) {
 	
 	tokenExpiryHours, _ := strconv.Atoi(getEnv("JWT_TOKEN_EXPIRY_HOURS", "24"))
 	
+	pendingTTLMinutes, _ := strconv.Atoi(getEnv("PAYMENT_PENDING_TTL_MINUTES", "30"))
+	offerTTLMinutes, _ := strconv.Atoi(getEnv("WAITLIST_OFFER_TTL_MINUTES", "60"))
+	lookupRate, _ := strconv.Atoi(getEnv("BOOKING_LOOKUP_RATE_PER_MINUTE", "10"))
+	idempotencyTTLHours, _ := strconv.Atoi(getEnv("IDEMPOTENCY_KEY_TTL_HOURS", "24"))
+	loyaltyExpiryMonths, _ := strconv.Atoi(getEnv("LOYALTY_POINTS_EXPIRY_MONTHS", "18"))
+	attachmentMaxMB, _ := strconv.Atoi(getEnv("MESSAGE_ATTACHMENT_MAX_MB", "10"))
+	embeddedFake, _ := strconv.ParseBool(getEnv("PAYMENT_FAKE_EMBEDDED", "false"))
+	
 	return &Config{
 		Server: ServerConfig{
-			Port: getEnv("SERVER_PORT", "8080"),
+			Port:      getEnv("SERVER_PORT", "8080"),
+			PublicURL: getEnv("PUBLIC_BASE_URL", "http://localhost:8080"),
 		},
 		Database: connections.PostgresConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -51,6 +117,38 @@ func LoadConfig() (*Config, error) {
 			Secret:      getEnv("JWT_SECRET", "your_secret_key"),
 			TokenExpiry: time.Duration(tokenExpiryHours) * time.Hour,
 		},
+		Payments: PaymentsConfig{
+			ProviderURL:   os.Getenv("PAYMENT_PROVIDER_URL"),
+			APIKey:        os.Getenv("PAYMENT_API_KEY"),
+			WebhookSecret: os.Getenv("PAYMENT_WEBHOOK_SECRET"),
+			EmbeddedFake:  embeddedFake,
+			PendingTTL:    time.Duration(pendingTTLMinutes) * time.Minute,
+		},
+		Waitlist: WaitlistConfig{
+			OfferTTL: time.Duration(offerTTLMinutes) * time.Minute,
+		},
+		Lookup: LookupConfig{
+			RatePerMinute: lookupRate,
+		},
+		Idempotency: IdempotencyConfig{
+			KeyTTL: time.Duration(idempotencyTTLHours) * time.Hour,
+		},
+		Loyalty: LoyaltyConfig{
+			ExpiryMonths: loyaltyExpiryMonths,
+			Currency:     strings.ToUpper(getEnv("LOYALTY_CURRENCY", "USD")),
+		},
+		Messages: MessagesConfig{
+			AttachmentDir:      getEnv("MESSAGE_ATTACHMENT_DIR", "data/attachments"),
+			MaxAttachmentBytes: int64(attachmentMaxMB) << 20,
+			StaffEmail:         os.Getenv("MESSAGE_STAFF_EMAIL"),
+		},
+		SMTP: notifications.SMTPConfig{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     getEnv("SMTP_PORT", "587"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     getEnv("SMTP_FROM", "bookings@example.com"),
+		},
 	}, nil
 }
 
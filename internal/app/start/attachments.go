@@ -0,0 +1,19 @@
+package start
+
+import (
+	"log"
+
+	"hotel-booking-service/internal/app/config"
+	"hotel-booking-service/internal/pkg/filestore"
+)
+
+// setupAttachmentStore keeps message attachments under the configured
+// directory. The service cannot take messages without it, so it stops if
+// the directory cannot be created.
+func setupAttachmentStore(cfg *config.Config) *filestore.Local {
+	store, err := filestore.NewLocal(cfg.Messages.AttachmentDir)
+	if err != nil {
+		log.Fatalf("Failed to set up message attachments: %v", err)
+	}
+	return store
+}
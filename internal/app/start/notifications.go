@@ -0,0 +1,18 @@
+package start
+
+import (
+	"log"
+
+	"hotel-booking-service/internal/app/config"
+	"hotel-booking-service/internal/notifications"
+)
+
+// setupNotifier sends mail when SMTP is configured and logs messages
+// otherwise.
+func setupNotifier(cfg *config.Config) notifications.Notifier {
+	if cfg.SMTP.Host == "" {
+		log.Println("SMTP_HOST not set, guest notifications will be logged")
+		return notifications.NewLogNotifier()
+	}
+	return notifications.NewSMTPNotifier(cfg.SMTP)
+}
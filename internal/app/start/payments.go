@@ -0,0 +1,39 @@
+package start
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"hotel-booking-service/internal/app/config"
+	"hotel-booking-service/internal/payments"
+	"hotel-booking-service/internal/payments/fakepay"
+)
+
+// setupPaymentProvider returns the configured provider. Without a provider
+// URL, and only when PAYMENT_FAKE_EMBEDDED asks for it, the fake gateway runs
+// inside this process under /fakepay, and the returned hook routes its
+// webhooks straight to the handler. Webhooks are only trusted when they are
+// signed with a secret that has been set.
+func setupPaymentProvider(cfg *config.Config, router *mux.Router) (payments.Provider, func(fakepay.Notifier)) {
+	if cfg.Payments.WebhookSecret == "" {
+		log.Fatal("PAYMENT_WEBHOOK_SECRET must be set")
+	}
+
+	if cfg.Payments.ProviderURL != "" {
+		return fakepay.NewClient(cfg.Payments.ProviderURL, cfg.Payments.APIKey), func(fakepay.Notifier) {}
+	}
+
+	if !cfg.Payments.EmbeddedFake {
+		log.Fatal("PAYMENT_PROVIDER_URL must be set, or PAYMENT_FAKE_EMBEDDED=true to run the fake payment provider in development")
+	}
+
+	baseURL := fmt.Sprintf("http://localhost:%s/fakepay", cfg.Server.Port)
+	server := fakepay.NewServer(baseURL, cfg.Payments.APIKey, cfg.Payments.WebhookSecret, nil)
+	router.PathPrefix("/fakepay").Handler(http.StripPrefix("/fakepay", server.Handler()))
+
+	log.Printf("Using the embedded fake payment provider at %s; anyone can confirm its payments, so never enable it in production", baseURL)
+	return fakepay.NewInProcessClient(server), server.SetNotifier
+}
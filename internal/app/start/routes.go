@@ -1,40 +1,134 @@
 package start
 
 import (
+	"context"
+	"net/http"
+	"time"
+
 	"github.com/gorilla/mux"
 
 	"hotel-booking-service/internal/app/config"
 	"hotel-booking-service/internal/app/store"
 	deliveries "hotel-booking-service/internal/deliveries/http"
 	"hotel-booking-service/internal/deliveries/http/middleware"
+	"hotel-booking-service/internal/events"
+	"hotel-booking-service/internal/jobs"
 	"hotel-booking-service/internal/usecases"
 )
 
-func SetupRoutes(cfg *config.Config, store *store.Store) *mux.Router {
+func SetupRoutes(cfg *config.Config, store *store.Store, scheduler *jobs.Scheduler) *mux.Router {
 	router := mux.NewRouter()
 
-	authUsecase := usecases.NewAuthUsecase(store.UserRepo, cfg.JWT.Secret, cfg.JWT.TokenExpiry)
-	hotelUsecase := usecases.NewHotelUsecase(store.HotelRepo, store.RoomRepo)
-	bookingUsecase := usecases.NewBookingUsecase(store.BookingRepo, store.RoomRepo)
+	paymentProvider, setWebhookTarget := setupPaymentProvider(cfg, router)
+	notifier := setupNotifier(cfg)
+	bus := events.NewBus()
+
+	authUsecase := usecases.NewAuthUsecase(store.UserRepo, cfg.JWT.Secret, cfg.JWT.TokenExpiry, notifier, cfg.Server.PublicURL)
+	currencyUsecase := usecases.NewCurrencyUsecase(store.RateRepo)
+	restrictionUsecase := usecases.NewRestrictionUsecase(store.RestrictRepo, store.HotelRepo, store.RoomTypeRepo)
+	hotelUsecase := usecases.NewHotelUsecase(store.HotelRepo, store.RoomRepo, store.RoomTypeRepo, store.AmenityRepo, currencyUsecase, restrictionUsecase)
+	paymentUsecase := usecases.NewPaymentUsecase(store.PaymentRepo, store.BookingRepo, paymentProvider, cfg.Payments.WebhookSecret, bus)
+	inventoryUsecase := usecases.NewInventoryUsecase(store.OverbookRepo, store.BlockRepo, store.HotelRepo, store.RoomRepo, store.RoomTypeRepo, store.BookingRepo)
+	invoiceUsecase := usecases.NewInvoiceUsecase(store.InvoiceRepo, store.BookingRepo, store.RoomRepo, store.RoomTypeRepo, store.HotelRepo, store.UserRepo, store.ExtraRepo)
+	loyaltyUsecase := usecases.NewLoyaltyUsecase(store.LoyaltyRepo, currencyUsecase, cfg.Loyalty.Currency, cfg.Loyalty.ExpiryMonths)
+	extraUsecase := usecases.NewExtraUsecase(store.ExtraRepo, store.BookingRepo, store.RoomTypeRepo, store.HotelRepo, store.PaymentRepo)
+	bookingUsecase := usecases.NewBookingUsecase(store.BookingRepo, store.RoomRepo, store.RoomTypeRepo, store.PolicyRepo, store.UserRepo, currencyUsecase, paymentUsecase, invoiceUsecase, bus, inventoryUsecase, restrictionUsecase, loyaltyUsecase, extraUsecase)
+	waitlistUsecase := usecases.NewWaitlistUsecase(store.WaitlistRepo, store.RoomRepo, store.RoomTypeRepo, store.HotelRepo, store.UserRepo, bookingUsecase, notifier, cfg.Waitlist.OfferTTL)
+	frontDeskUsecase := usecases.NewFrontDeskUsecase(store.BookingRepo, store.RoomRepo, store.UserRepo, bookingUsecase, paymentUsecase, invoiceUsecase, extraUsecase, bus)
+	policyUsecase := usecases.NewCancellationPolicyUsecase(store.PolicyRepo, store.HotelRepo, store.RoomTypeRepo)
 	userUsecase := usecases.NewUserUsecase(store.UserRepo) 
+	guestUsecase := usecases.NewGuestUsecase(store.UserRepo, bookingUsecase, notifier, cfg.JWT.Secret, cfg.Server.PublicURL)
+	reviewUsecase := usecases.NewReviewUsecase(store.ReviewRepo, store.BookingRepo, store.RoomTypeRepo, store.HotelRepo, store.UserRepo)
+	messageUsecase := usecases.NewMessageUsecase(store.MessageRepo, store.BookingRepo, store.UserRepo, setupAttachmentStore(cfg), cfg.Messages.MaxAttachmentBytes, notifier, cfg.Messages.StaffEmail, bus)
+	amenityUsecase := usecases.NewAmenityUsecase(store.AmenityRepo, store.HotelRepo, store.RoomTypeRepo, store.RoomRepo)
 
 	authController := deliveries.NewAuthController(authUsecase)
 	hotelController := deliveries.NewHotelController(hotelUsecase)
 	bookingController := deliveries.NewBookingController(bookingUsecase)
 	userController := deliveries.NewUserController(userUsecase, cfg.JWT.Secret)
+	exchangeRateController := deliveries.NewExchangeRateController(currencyUsecase)
+	policyController := deliveries.NewCancellationPolicyController(policyUsecase)
+	paymentController := deliveries.NewPaymentController(paymentUsecase)
+	invoiceController := deliveries.NewInvoiceController(invoiceUsecase)
+	waitlistController := deliveries.NewWaitlistController(waitlistUsecase)
+	inventoryController := deliveries.NewInventoryController(inventoryUsecase, bookingUsecase)
+	restrictionController := deliveries.NewRestrictionController(restrictionUsecase)
+	frontDeskController := deliveries.NewFrontDeskController(frontDeskUsecase)
+	guestController := deliveries.NewGuestController(guestUsecase)
+	loyaltyController := deliveries.NewLoyaltyController(loyaltyUsecase)
+	reviewController := deliveries.NewReviewController(reviewUsecase)
+	extraController := deliveries.NewExtraController(extraUsecase)
+	messageController := deliveries.NewMessageController(messageUsecase)
+	amenityController := deliveries.NewAmenityController(amenityUsecase)
+
+	setWebhookTarget(paymentUsecase.HandleWebhook)
+	scheduler.Every("expire-pending-bookings", time.Minute, func(ctx context.Context) error {
+		return paymentUsecase.ExpirePendingBookings(ctx, cfg.Payments.PendingTTL)
+	})
+
+	bus.Subscribe(events.BookingCancelled, waitlistUsecase.HandleBookingCancelled)
+	bus.Subscribe(events.BookingNoShow, waitlistUsecase.HandleBookingCancelled)
+	bus.Subscribe(events.BookingCancelled, loyaltyUsecase.HandleBookingCancelled)
+	bus.Subscribe(events.BookingNoShow, loyaltyUsecase.HandleBookingCancelled)
+	bus.Subscribe(events.BookingCheckedOut, loyaltyUsecase.HandleBookingCheckedOut)
+	bus.Subscribe(events.BookingMessagePosted, messageUsecase.HandleMessagePosted)
+	scheduler.Every("expire-loyalty-points", 24*time.Hour, loyaltyUsecase.ExpirePoints)
+	scheduler.Every("expire-waitlist-offers", time.Minute, waitlistUsecase.ExpireOffers)
+	scheduler.Every("mark-no-shows", 15*time.Minute, bookingUsecase.MarkNoShows)
+	scheduler.Every("assign-rooms", time.Hour, func(ctx context.Context) error {
+		return bookingUsecase.AssignRooms(ctx, time.Now().AddDate(0, 0, 1))
+	})
+
+	scheduler.Every("expire-idempotency-keys", time.Hour, func(ctx context.Context) error {
+		_, err := store.IdemRepo.DeleteOlderThan(time.Now().Add(-cfg.Idempotency.KeyTTL))
+		return err
+	})
 
 	auth := middleware.AuthMiddleware(cfg.JWT.Secret)
+	idempotent := middleware.Idempotency(store.IdemRepo)
 
 	router.HandleFunc("/register", authController.Register).Methods("POST")
+	router.HandleFunc("/register/claim", authController.ClaimGuest).Methods("POST")
 	router.HandleFunc("/login", authController.Login).Methods("POST")
 	router.HandleFunc("/hotels", hotelController.GetAllHotels).Methods("GET")
+	router.HandleFunc("/hotels/search", hotelController.SearchHotels).Methods("GET")
 	router.HandleFunc("/hotels/{id:[0-9]+}", hotelController.GetHotelByID).Methods("GET")
 	router.HandleFunc("/hotels/{id:[0-9]+}/rooms", hotelController.GetHotelRooms).Methods("GET")
+	router.HandleFunc("/hotels/{id:[0-9]+}/reviews", reviewController.GetHotelReviews).Methods("GET")
+	router.HandleFunc("/hotels/{id:[0-9]+}/extras", extraController.GetHotelExtras).Methods("GET")
+	router.HandleFunc("/rooms/{id:[0-9]+}", hotelController.GetRoom).Methods("GET")
+	router.HandleFunc("/rooms/{id:[0-9]+}/quote", hotelController.QuoteRoom).Methods("GET")
+	router.HandleFunc("/hotels/{id:[0-9]+}/room-types", hotelController.GetHotelRoomTypes).Methods("GET")
+	router.HandleFunc("/room-types/{id:[0-9]+}", hotelController.GetRoomType).Methods("GET")
+	router.HandleFunc("/room-types/{id:[0-9]+}/quote", hotelController.QuoteRoomType).Methods("GET")
+	router.HandleFunc("/exchange-rates", exchangeRateController.GetRates).Methods("GET")
+	router.HandleFunc("/amenities", amenityController.GetAmenities).Methods("GET")
+	router.HandleFunc("/hotels/{id:[0-9]+}/cancellation-policies", policyController.GetHotelPolicies).Methods("GET")
+	router.HandleFunc("/hotels/{id:[0-9]+}/restrictions", restrictionController.GetHotelRestrictions).Methods("GET")
+	router.HandleFunc("/webhooks/payments", paymentController.Webhook).Methods("POST")
+	router.Handle("/bookings", idempotent(http.HandlerFunc(guestController.CreateBooking))).Methods("POST")
+	router.Handle("/bookings/lookup", middleware.RateLimit(cfg.Lookup.RatePerMinute, time.Minute)(http.HandlerFunc(bookingController.LookupBooking))).Methods("POST")
+
+	guest := router.PathPrefix("/guest/bookings/{id:[0-9]+}").Subrouter()
+	guest.Use(middleware.MagicLinkAuth(cfg.JWT.Secret), idempotent)
+
+	guest.HandleFunc("", bookingController.GetBookingByID).Methods("GET")
+	guest.HandleFunc("", bookingController.CancelBooking).Methods("DELETE")
+	guest.HandleFunc("/cancellation", bookingController.PreviewCancellation).Methods("GET")
+	guest.HandleFunc("/contact", guestController.UpdateContact).Methods("PUT")
+	guest.HandleFunc("/extras", extraController.AddToBooking).Methods("POST")
+	guest.HandleFunc("/payments", paymentController.CreatePayment).Methods("POST")
+	guest.HandleFunc("/payments", paymentController.GetBookingPayments).Methods("GET")
+	guest.HandleFunc("/invoice", invoiceController.GetBookingInvoice).Methods("GET")
+	guest.HandleFunc("/messages", messageController.GetThread).Methods("GET")
+	guest.HandleFunc("/messages", messageController.PostMessage).Methods("POST")
+	guest.HandleFunc("/messages/attachments/{attachmentID:[0-9]+}", messageController.GetAttachment).Methods("GET")
 
 	api := router.PathPrefix("/api").Subrouter()
-	api.Use(auth)
+	api.Use(auth, idempotent)
 
 	api.HandleFunc("/users/me", userController.GetCurrentUser).Methods("GET")
+	api.HandleFunc("/users/me/loyalty", loyaltyController.GetMyAccount).Methods("GET")
 	api.HandleFunc("/users/{id:[0-9]+}", userController.UpdateUser).Methods("PUT")
 	api.HandleFunc("/users/{id:[0-9]+}", userController.DeleteUser).Methods("DELETE")
 
@@ -42,15 +136,94 @@ func SetupRoutes(cfg *config.Config, store *store.Store) *mux.Router {
 	api.HandleFunc("/bookings", bookingController.GetUserBookings).Methods("GET")
 	api.HandleFunc("/bookings/{id:[0-9]+}", bookingController.GetBookingByID).Methods("GET")
 	api.HandleFunc("/bookings/{id:[0-9]+}", bookingController.CancelBooking).Methods("DELETE")
-	api.HandleFunc("/bookings/{id:[0-9]+}", bookingController.UpdateBooking).Methods("PUT")
+	api.HandleFunc("/bookings/{id:[0-9]+}/cancellation", bookingController.PreviewCancellation).Methods("GET")
+	api.HandleFunc("/bookings/{id:[0-9]+}/payments", paymentController.CreatePayment).Methods("POST")
+	api.HandleFunc("/bookings/{id:[0-9]+}/payments", paymentController.GetBookingPayments).Methods("GET")
+	api.HandleFunc("/bookings/{id:[0-9]+}/invoice", invoiceController.GetBookingInvoice).Methods("GET")
+	api.HandleFunc("/bookings/{id:[0-9]+}/review", reviewController.CreateReview).Methods("POST")
+	api.HandleFunc("/bookings/{id:[0-9]+}/extras", extraController.AddToBooking).Methods("POST")
+	api.HandleFunc("/bookings/{id:[0-9]+}/messages", messageController.GetThread).Methods("GET")
+	api.HandleFunc("/bookings/{id:[0-9]+}/messages", messageController.PostMessage).Methods("POST")
+	api.HandleFunc("/bookings/{id:[0-9]+}/messages/attachments/{attachmentID:[0-9]+}", messageController.GetAttachment).Methods("GET")
+
+	api.HandleFunc("/waitlist", waitlistController.Join).Methods("POST")
+	api.HandleFunc("/waitlist", waitlistController.GetUserEntries).Methods("GET")
+	api.HandleFunc("/waitlist/{id:[0-9]+}", waitlistController.Leave).Methods("DELETE")
+	api.HandleFunc("/waitlist/{id:[0-9]+}/claim", waitlistController.Claim).Methods("POST")
 
 	api.HandleFunc("/hotels", hotelController.CreateHotel).Methods("POST")
 	api.HandleFunc("/hotels/{id:[0-9]+}", hotelController.UpdateHotel).Methods("PUT")
 	api.HandleFunc("/hotels/{id:[0-9]+}", hotelController.DeleteHotel).Methods("DELETE")
 
+	api.HandleFunc("/hotels/{hotelID:[0-9]+}/room-types", hotelController.CreateRoomType).Methods("POST")
+	api.HandleFunc("/room-types/{id:[0-9]+}", hotelController.UpdateRoomType).Methods("PUT")
+	api.HandleFunc("/room-types/{id:[0-9]+}", hotelController.DeleteRoomType).Methods("DELETE")
+
 	api.HandleFunc("/hotels/{hotelID:[0-9]+}/rooms", hotelController.CreateRoom).Methods("POST")
 	api.HandleFunc("/rooms/{id:[0-9]+}", hotelController.UpdateRoom).Methods("PUT")
 	api.HandleFunc("/rooms/{id:[0-9]+}", hotelController.DeleteRoom).Methods("DELETE")
 
+	desk := api.PathPrefix("/front-desk").Subrouter()
+	desk.Use(middleware.RequireRole(store.UserRepo, "admin", "staff"))
+
+	desk.HandleFunc("/hotels/{hotelID:[0-9]+}/arrivals", frontDeskController.Arrivals).Methods("GET")
+	desk.HandleFunc("/hotels/{hotelID:[0-9]+}/in-house", frontDeskController.InHouse).Methods("GET")
+	desk.HandleFunc("/hotels/{hotelID:[0-9]+}/departures", frontDeskController.Departures).Methods("GET")
+	desk.HandleFunc("/bookings/{id:[0-9]+}/check-in", frontDeskController.CheckIn).Methods("POST")
+	desk.HandleFunc("/bookings/{id:[0-9]+}/check-out", frontDeskController.CheckOut).Methods("POST")
+	desk.HandleFunc("/rooms/{id:[0-9]+}/housekeeping", frontDeskController.SetHousekeepingStatus).Methods("PUT")
+	desk.HandleFunc("/reviews/{id:[0-9]+}/reply", reviewController.Reply).Methods("PUT")
+	desk.HandleFunc("/hotels/{hotelID:[0-9]+}/messages", messageController.Inbox).Methods("GET")
+	desk.HandleFunc("/bookings/{id:[0-9]+}/messages", messageController.GetHotelThread).Methods("GET")
+	desk.HandleFunc("/bookings/{id:[0-9]+}/messages", messageController.PostHotelMessage).Methods("POST")
+	desk.HandleFunc("/bookings/{id:[0-9]+}/messages/attachments/{attachmentID:[0-9]+}", messageController.GetHotelAttachment).Methods("GET")
+
+	admin := api.PathPrefix("/admin").Subrouter()
+	admin.Use(middleware.RequireRole(store.UserRepo, "admin"))
+
+	admin.HandleFunc("/exchange-rates", exchangeRateController.UpdateRates).Methods("PUT")
+	admin.HandleFunc("/exchange-rates/{base:[A-Za-z]{3}}/{quote:[A-Za-z]{3}}", exchangeRateController.DeleteRate).Methods("DELETE")
+
+	admin.HandleFunc("/hotels/{hotelID:[0-9]+}/cancellation-policies", policyController.CreatePolicy).Methods("POST")
+	admin.HandleFunc("/cancellation-policies/{id:[0-9]+}", policyController.UpdatePolicy).Methods("PUT")
+	admin.HandleFunc("/cancellation-policies/{id:[0-9]+}", policyController.DeletePolicy).Methods("DELETE")
+
+	admin.HandleFunc("/hotels/{hotelID:[0-9]+}/restrictions", restrictionController.CreateRestriction).Methods("POST")
+	admin.HandleFunc("/restrictions/{id:[0-9]+}", restrictionController.UpdateRestriction).Methods("PUT")
+	admin.HandleFunc("/restrictions/{id:[0-9]+}", restrictionController.DeleteRestriction).Methods("DELETE")
+
+	admin.HandleFunc("/payments/{id:[0-9]+}/capture", paymentController.Capture).Methods("POST")
+	admin.HandleFunc("/payments/{id:[0-9]+}/refund", paymentController.Refund).Methods("POST")
+	admin.HandleFunc("/payments/{id:[0-9]+}/void", paymentController.Void).Methods("POST")
+
+	admin.HandleFunc("/invoices/{id:[0-9]+}/credit-notes", invoiceController.CreateCreditNote).Methods("POST")
+
+	admin.HandleFunc("/hotels/{hotelID:[0-9]+}/overbooking", inventoryController.GetAllowances).Methods("GET")
+	admin.HandleFunc("/hotels/{hotelID:[0-9]+}/overbooking", inventoryController.CreateAllowance).Methods("POST")
+	admin.HandleFunc("/overbooking/{id:[0-9]+}", inventoryController.DeleteAllowance).Methods("DELETE")
+	admin.HandleFunc("/hotels/{hotelID:[0-9]+}/oversold", inventoryController.OversoldReport).Methods("GET")
+	admin.HandleFunc("/bookings/{id:[0-9]+}/walk", inventoryController.WalkGuest).Methods("POST")
+	admin.HandleFunc("/bookings/{id:[0-9]+}/room", inventoryController.ReassignRoom).Methods("PUT")
+
+	admin.HandleFunc("/hotels/{hotelID:[0-9]+}/blocks", inventoryController.GetHotelBlocks).Methods("GET")
+	admin.HandleFunc("/rooms/{id:[0-9]+}/blocks", inventoryController.CreateBlock).Methods("POST")
+	admin.HandleFunc("/room-blocks/{id:[0-9]+}", inventoryController.DeleteBlock).Methods("DELETE")
+	admin.HandleFunc("/hotels/{hotelID:[0-9]+}/calendar", inventoryController.HotelCalendar).Methods("GET")
+
+	admin.HandleFunc("/hotels/{hotelID:[0-9]+}/extras", extraController.GetAllHotelExtras).Methods("GET")
+	admin.HandleFunc("/hotels/{hotelID:[0-9]+}/extras", extraController.CreateExtra).Methods("POST")
+	admin.HandleFunc("/extras/{id:[0-9]+}", extraController.UpdateExtra).Methods("PUT")
+	admin.HandleFunc("/extras/{id:[0-9]+}", extraController.DeleteExtra).Methods("DELETE")
+
+	admin.HandleFunc("/reviews", reviewController.ListReviews).Methods("GET")
+	admin.HandleFunc("/reviews/{id:[0-9]+}/moderation", reviewController.Moderate).Methods("PUT")
+
+	admin.HandleFunc("/amenities", amenityController.CreateAmenity).Methods("POST")
+	admin.HandleFunc("/amenities/{id:[0-9]+}", amenityController.UpdateAmenity).Methods("PUT")
+	admin.HandleFunc("/amenities/{id:[0-9]+}", amenityController.DeleteAmenity).Methods("DELETE")
+	admin.HandleFunc("/hotels/{hotelID:[0-9]+}/amenities", amenityController.SetHotelAmenities).Methods("PUT")
+	admin.HandleFunc("/room-types/{id:[0-9]+}/amenities", amenityController.SetRoomTypeAmenities).Methods("PUT")
+	admin.HandleFunc("/rooms/{id:[0-9]+}/amenities", amenityController.SetRoomAmenities).Methods("PUT")
+
 	return router
 }
\ No newline at end of file
@@ -7,17 +7,47 @@ import (
 )
 
 type Store struct {
-	UserRepo    *repositories.UserRepository
-	HotelRepo   *repositories.HotelRepository
-	RoomRepo    *repositories.RoomRepository
-	BookingRepo *repositories.BookingRepository
+	UserRepo     *repositories.UserRepository
+	HotelRepo    *repositories.HotelRepository
+	RoomRepo     *repositories.RoomRepository
+	RoomTypeRepo *repositories.RoomTypeRepository
+	BookingRepo  *repositories.BookingRepository
+	RateRepo     *repositories.ExchangeRateRepository
+	PolicyRepo   *repositories.CancellationPolicyRepository
+	PaymentRepo  *repositories.PaymentRepository
+	InvoiceRepo  *repositories.InvoiceRepository
+	WaitlistRepo *repositories.WaitlistRepository
+	OverbookRepo *repositories.OverbookingRepository
+	RestrictRepo *repositories.RestrictionRepository
+	BlockRepo    *repositories.RoomBlockRepository
+	IdemRepo     *repositories.IdempotencyRepository
+	LoyaltyRepo  *repositories.LoyaltyRepository
+	ReviewRepo   *repositories.ReviewRepository
+	ExtraRepo    *repositories.ExtraRepository
+	MessageRepo  *repositories.MessageRepository
+	AmenityRepo  *repositories.AmenityRepository
 }
 
 func NewStore(db *sql.DB) *Store {
 	return &Store{
-		UserRepo:    repositories.NewUserRepository(db),
-		HotelRepo:   repositories.NewHotelRepository(db),
-		RoomRepo:    repositories.NewRoomRepository(db),
-		BookingRepo: repositories.NewBookingRepository(db),
+		UserRepo:     repositories.NewUserRepository(db),
+		HotelRepo:    repositories.NewHotelRepository(db),
+		RoomRepo:     repositories.NewRoomRepository(db),
+		RoomTypeRepo: repositories.NewRoomTypeRepository(db),
+		BookingRepo:  repositories.NewBookingRepository(db),
+		RateRepo:     repositories.NewExchangeRateRepository(db),
+		PolicyRepo:   repositories.NewCancellationPolicyRepository(db),
+		PaymentRepo:  repositories.NewPaymentRepository(db),
+		InvoiceRepo:  repositories.NewInvoiceRepository(db),
+		WaitlistRepo: repositories.NewWaitlistRepository(db),
+		OverbookRepo: repositories.NewOverbookingRepository(db),
+		RestrictRepo: repositories.NewRestrictionRepository(db),
+		BlockRepo:    repositories.NewRoomBlockRepository(db),
+		IdemRepo:     repositories.NewIdempotencyRepository(db),
+		LoyaltyRepo:  repositories.NewLoyaltyRepository(db),
+		ReviewRepo:   repositories.NewReviewRepository(db),
+		ExtraRepo:    repositories.NewExtraRepository(db),
+		MessageRepo:  repositories.NewMessageRepository(db),
+		AmenityRepo:  repositories.NewAmenityRepository(db),
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,23 @@
+package data
+
+// What an amenity belongs to: the whole hotel, e.g. a pool, or its room
+// types, e.g. a sea view, and so every room of those types.
+const (
+	AmenityScopeHotel = "hotel"
+	AmenityScopeRoom  = "room"
+)
+
+// Amenity is an entry in the amenity catalog. Code is the stable name
+// searches filter by, e.g. "sea_view"; Name is what guests are shown.
+type Amenity struct {
+	ID    int    `json:"id"`
+	Code  string `json:"code"`
+	Name  string `json:"name"`
+	Scope string `json:"scope"`
+}
+
+// SetAmenitiesRequest replaces a hotel's or room type's amenities with the
+// ones whose codes it lists.
+type SetAmenitiesRequest struct {
+	Amenities []string `json:"amenities"`
+}
@@ -1,5 +1,45 @@
 package data
 
-type UpdateBookingRequest struct {
-    Status string `json:"status"`
+const (
+	BookingStatusPending   = "pending"
+	BookingStatusConfirmed = "confirmed"
+	BookingStatusCancelled = "cancelled"
+	// BookingStatusWalked marks a booking the hotel could not honour; the
+	// guest was moved to a partner hotel.
+	BookingStatusWalked = "walked"
+	// BookingStatusCheckedIn and BookingStatusCheckedOut are set by the front
+	// desk when the guest arrives and leaves.
+	BookingStatusCheckedIn  = "checked_in"
+	BookingStatusCheckedOut = "checked_out"
+	// BookingStatusNoShow marks a confirmed booking whose guest never
+	// arrived; its nights are released.
+	BookingStatusNoShow = "no_show"
+)
+
+// BookingLookupRequest identifies a booking without logging in: the
+// confirmation code plus the guest's last name or email.
+type BookingLookupRequest struct {
+	ConfirmationCode string `json:"confirmation_code"`
+	LastName         string `json:"last_name,omitempty"`
+	Email            string `json:"email,omitempty"`
+}
+
+type GuestDetails struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Phone string `json:"phone,omitempty"`
+}
+
+// GuestBookingRequest books without an account, like CreateBookingRequest
+// plus the guest's contact details.
+type GuestBookingRequest struct {
+	CreateBookingRequest
+	Guest GuestDetails `json:"guest"`
+}
+
+// GuestBooking is the answer to a guest booking. The link that opens the
+// booking's guest endpoints is only emailed, so that only whoever reads the
+// guest's mailbox gets it.
+type GuestBooking struct {
+	Booking *Booking `json:"booking"`
 }
@@ -0,0 +1,37 @@
+package data
+
+import (
+	"time"
+
+	"hotel-booking-service/internal/pkg/money"
+)
+
+const (
+	PenaltyNone       = "none"
+	PenaltyPercent    = "percent"
+	PenaltyFirstNight = "first_night"
+	PenaltyFull       = "full"
+)
+
+// CancellationPolicy is free until FreeCancellationDays before arrival; after
+// that the penalty applies. A non-refundable policy always charges in full.
+// RoomTypeID is nil for a hotel's default policy.
+type CancellationPolicy struct {
+	ID                   int       `json:"id,omitempty"`
+	HotelID              int       `json:"hotel_id"`
+	RoomTypeID           *int      `json:"room_type_id,omitempty"`
+	Name                 string    `json:"name"`
+	FreeCancellationDays int       `json:"free_cancellation_days"`
+	PenaltyType          string    `json:"penalty_type"`
+	PenaltyPercent       int       `json:"penalty_percent,omitempty"`
+	NonRefundable        bool      `json:"non_refundable"`
+	CreatedAt            time.Time `json:"created_at,omitempty"`
+}
+
+type CancellationResult struct {
+	BookingID         int                `json:"booking_id"`
+	DaysBeforeArrival int                `json:"days_before_arrival"`
+	Penalty           money.Money        `json:"penalty"`
+	Refund            money.Money        `json:"refund"`
+	Policy            CancellationPolicy `json:"policy"`
+}
@@ -0,0 +1,38 @@
+package data
+
+import (
+	"time"
+
+	"hotel-booking-service/internal/pkg/money"
+)
+
+type ExchangeRate struct {
+	BaseCurrency  string    `json:"base_currency"`
+	QuoteCurrency string    `json:"quote_currency"`
+	Rate          string    `json:"rate"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type Quote struct {
+	RoomTypeID  int         `json:"room_type_id"`
+	RoomID      int         `json:"room_id,omitempty"`
+	HotelID     int         `json:"hotel_id"`
+	FromDate    time.Time   `json:"from_date"`
+	ToDate      time.Time   `json:"to_date"`
+	Nights      int         `json:"nights"`
+	NightlyRate money.Money `json:"nightly_rate"`
+	Total       money.Money `json:"total"`
+
+	Converted *ConvertedQuote `json:"converted,omitempty"`
+
+	Restrictions []RestrictionViolation `json:"restrictions,omitempty"`
+}
+
+// ConvertedQuote shows a quote in the guest's currency. Guests are always
+// charged in the hotel currency; these amounts are informational.
+type ConvertedQuote struct {
+	Currency     string      `json:"currency"`
+	ExchangeRate string      `json:"exchange_rate"`
+	NightlyRate  money.Money `json:"nightly_rate"`
+	Total        money.Money `json:"total"`
+}
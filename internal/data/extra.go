@@ -0,0 +1,60 @@
+package data
+
+import (
+	"time"
+
+	"hotel-booking-service/internal/pkg/money"
+)
+
+// How an extra is priced: once per stay, per night, or per guest per
+// night. The quantity of a per-guest extra is the number of guests.
+const (
+	ExtraPerStay  = "per_stay"
+	ExtraPerNight = "per_night"
+	ExtraPerGuest = "per_guest"
+)
+
+// Extra is something a hotel sells alongside its rooms, e.g. breakfast,
+// parking or a crib. DailyInventory caps how many can be sold for any one
+// night; nil means unlimited. Inactive extras are no longer sold but stay on
+// the bookings that have them.
+type Extra struct {
+	ID             int         `json:"id"`
+	HotelID        int         `json:"hotel_id"`
+	Name           string      `json:"name"`
+	Description    string      `json:"description,omitempty"`
+	Pricing        string      `json:"pricing"`
+	Price          money.Money `json:"price"`
+	DailyInventory *int        `json:"daily_inventory,omitempty"`
+	Active         bool        `json:"active"`
+	CreatedAt      time.Time   `json:"created_at"`
+
+	// Available is the number of units left on every night of the requested
+	// stay, for extras with limited inventory.
+	Available *int `json:"available,omitempty"`
+}
+
+// BookingExtra is an extra on a booking, priced when it was added.
+type BookingExtra struct {
+	ID        int         `json:"id"`
+	BookingID int         `json:"-"`
+	ExtraID   int         `json:"extra_id"`
+	Name      string      `json:"name"`
+	Pricing   string      `json:"pricing"`
+	Quantity  int         `json:"quantity"`
+	FromDate  time.Time   `json:"-"`
+	ToDate    time.Time   `json:"-"`
+	UnitPrice money.Money `json:"unit_price"`
+	Total     money.Money `json:"total"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// ExtraRequest asks for quantity of an extra; zero means one.
+type ExtraRequest struct {
+	ExtraID  int `json:"extra_id"`
+	Quantity int `json:"quantity,omitempty"`
+}
+
+type AddExtrasRequest struct {
+	Extras []ExtraRequest `json:"extras"`
+}
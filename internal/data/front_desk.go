@@ -0,0 +1,42 @@
+package data
+
+import "time"
+
+const (
+	HousekeepingClean = "clean"
+	HousekeepingDirty = "dirty"
+)
+
+// CheckInRequest records a guest's arrival. IDDocument is the identity
+// document the desk checked, e.g. "passport X1234567". RoomID confirms or
+// overrides the assigned room; Early allows check-in before the standard
+// time on the arrival day.
+type CheckInRequest struct {
+	IDDocument string `json:"id_document"`
+	RoomID     int    `json:"room_id,omitempty"`
+	Early      bool   `json:"early,omitempty"`
+}
+
+// CheckOutRequest records a guest's departure. Early ends the stay before
+// the departure day and Late allows check-out after the standard time.
+type CheckOutRequest struct {
+	Early bool `json:"early,omitempty"`
+	Late  bool `json:"late,omitempty"`
+}
+
+type CheckOutResult struct {
+	Booking *Booking `json:"booking"`
+	Invoice *Invoice `json:"invoice,omitempty"`
+}
+
+// FrontDeskList is one of the desk's daily lists for a hotel: arrivals,
+// guests in house, or departures.
+type FrontDeskList struct {
+	HotelID  int       `json:"hotel_id"`
+	Date     time.Time `json:"date"`
+	Bookings []Booking `json:"bookings"`
+}
+
+type HousekeepingRequest struct {
+	Status string `json:"status"`
+}
@@ -0,0 +1,18 @@
+package data
+
+import "time"
+
+// IdempotencyRecord is the stored outcome of a request sent with an
+// Idempotency-Key. Scope keeps keys of different users apart; Fingerprint
+// identifies the request the key was first used for. StatusCode is zero
+// while that request is still being handled.
+type IdempotencyRecord struct {
+	ID           int
+	Scope        string
+	Key          string
+	Fingerprint  string
+	StatusCode   int
+	ContentType  string
+	ResponseBody []byte
+	CreatedAt    time.Time
+}
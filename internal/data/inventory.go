@@ -0,0 +1,110 @@
+package data
+
+import "time"
+
+// OverbookingAllowance lets a hotel sell a percentage more rooms than it has
+// on the nights from FromDate to ToDate inclusive.
+type OverbookingAllowance struct {
+	ID        int       `json:"id"`
+	HotelID   int       `json:"hotel_id"`
+	FromDate  time.Time `json:"from_date"`
+	ToDate    time.Time `json:"to_date"`
+	Percent   int       `json:"percent"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CategoryNight is the inventory of one room type on one night.
+type CategoryNight struct {
+	Night      time.Time `json:"night"`
+	RoomTypeID int       `json:"room_type_id"`
+	RoomType   string    `json:"room_type"`
+	Rooms      int       `json:"rooms"`
+	Sold       int       `json:"sold"`
+	Limit      int       `json:"limit"`
+	Oversold   int       `json:"oversold"`
+}
+
+// RoomConflict lists bookings that were sold on the same room for
+// overlapping dates. The front desk resolves each one by moving a guest to
+// another room or walking them to a partner hotel.
+type RoomConflict struct {
+	RoomID     int       `json:"room_id"`
+	RoomNumber string    `json:"room_number"`
+	Bookings   []Booking `json:"bookings"`
+}
+
+type OversoldReport struct {
+	HotelID   int             `json:"hotel_id"`
+	FromDate  time.Time       `json:"from_date"`
+	ToDate    time.Time       `json:"to_date"`
+	Nights    []CategoryNight `json:"nights"`
+	Conflicts []RoomConflict  `json:"conflicts"`
+}
+
+type BookingWalk struct {
+	ID               int       `json:"id"`
+	BookingID        int       `json:"booking_id"`
+	PartnerName      string    `json:"partner_name"`
+	PartnerReference string    `json:"partner_reference,omitempty"`
+	Notes            string    `json:"notes,omitempty"`
+	WalkedBy         int       `json:"walked_by"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+type ReassignRoomRequest struct {
+	RoomID int `json:"room_id"`
+}
+
+// RoomBlock takes a room out of service, e.g. for renovation, on the nights
+// from FromDate up to, but not including, ToDate.
+type RoomBlock struct {
+	ID        int       `json:"id"`
+	RoomID    int       `json:"room_id"`
+	FromDate  time.Time `json:"from_date"`
+	ToDate    time.Time `json:"to_date"`
+	Reason    string    `json:"reason"`
+	CreatedBy int       `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+const (
+	CalendarFree    = "free"
+	CalendarBooked  = "booked"
+	CalendarBlocked = "blocked"
+)
+
+// CalendarNight is the state of one room on one night. A night with more
+// than one booking has been oversold.
+type CalendarNight struct {
+	Night      time.Time `json:"night"`
+	Status     string    `json:"status"`
+	BookingIDs []int     `json:"booking_ids,omitempty"`
+	BlockID    int       `json:"block_id,omitempty"`
+	Reason     string    `json:"reason,omitempty"`
+}
+
+type RoomCalendar struct {
+	RoomID     int             `json:"room_id"`
+	RoomNumber string          `json:"room_number"`
+	Nights     []CalendarNight `json:"nights"`
+}
+
+// OccupancyNight summarises a hotel night. Blocked rooms cannot be sold, so
+// they are left out of the rooms occupancy is measured against. Unassigned
+// counts the sold stays that have no room yet.
+type OccupancyNight struct {
+	Night            time.Time `json:"night"`
+	Rooms            int       `json:"rooms"`
+	Blocked          int       `json:"blocked"`
+	Sold             int       `json:"sold"`
+	Unassigned       int       `json:"unassigned"`
+	OccupancyPercent float64   `json:"occupancy_percent"`
+}
+
+type HotelCalendar struct {
+	HotelID   int              `json:"hotel_id"`
+	FromDate  time.Time        `json:"from_date"`
+	ToDate    time.Time        `json:"to_date"`
+	Rooms     []RoomCalendar   `json:"rooms"`
+	Occupancy []OccupancyNight `json:"occupancy"`
+}
@@ -0,0 +1,70 @@
+package data
+
+import (
+	"time"
+
+	"hotel-booking-service/internal/pkg/money"
+)
+
+const (
+	InvoiceKindInvoice    = "invoice"
+	InvoiceKindCreditNote = "credit_note"
+)
+
+// Invoice is an issued invoice or credit note. Everything a reader needs is
+// copied in when it is issued, so later changes to the hotel, the guest or
+// the booking never alter it. Credit note amounts are positive and reduce
+// the invoice they credit.
+type Invoice struct {
+	ID                int           `json:"id"`
+	Kind              string        `json:"kind"`
+	Number            string        `json:"number"`
+	HotelID           int           `json:"hotel_id"`
+	BookingID         int           `json:"booking_id"`
+	CreditedInvoiceID *int          `json:"credited_invoice_id,omitempty"`
+	CreditedNumber    string        `json:"credited_number,omitempty"`
+	Reason            string        `json:"reason,omitempty"`
+	IssuedAt          time.Time     `json:"issued_at"`
+	Seller            InvoiceParty  `json:"seller"`
+	Buyer             InvoiceParty  `json:"buyer"`
+	StayFrom          time.Time     `json:"stay_from"`
+	StayTo            time.Time     `json:"stay_to"`
+	Lines             []InvoiceLine `json:"lines"`
+	Taxes             []InvoiceTax  `json:"taxes"`
+	Net               money.Money   `json:"net"`
+	Tax               money.Money   `json:"tax"`
+	Total             money.Money   `json:"total"`
+}
+
+type InvoiceParty struct {
+	Name  string `json:"name"`
+	City  string `json:"city,omitempty"`
+	Email string `json:"email,omitempty"`
+}
+
+// InvoiceLine amounts include tax, as room prices do.
+type InvoiceLine struct {
+	Description string      `json:"description"`
+	Quantity    int         `json:"quantity"`
+	UnitPrice   money.Money `json:"unit_price"`
+	Amount      money.Money `json:"amount"`
+}
+
+type InvoiceTax struct {
+	Description string      `json:"description"`
+	RateBP      int         `json:"rate_bp"`
+	Taxable     money.Money `json:"taxable"`
+	Amount      money.Money `json:"amount"`
+}
+
+// BookingInvoices is a booking's invoice together with every credit note
+// issued against it.
+type BookingInvoices struct {
+	Invoice     *Invoice  `json:"invoice"`
+	CreditNotes []Invoice `json:"credit_notes"`
+}
+
+type CreateCreditNoteRequest struct {
+	Amount *money.Money `json:"amount,omitempty"`
+	Reason string       `json:"reason"`
+}
@@ -0,0 +1,42 @@
+package data
+
+import "time"
+
+const (
+	LoyaltyEarn     = "earn"
+	LoyaltyRedeem   = "redeem"
+	LoyaltyReversal = "reversal"
+	LoyaltyExpiry   = "expiry"
+)
+
+// LoyaltyEntry is one line of a member's points ledger. Points are negative
+// for redemptions, expiries and reversed earnings.
+type LoyaltyEntry struct {
+	ID              int       `json:"id"`
+	UserID          int       `json:"-"`
+	BookingID       int       `json:"booking_id,omitempty"`
+	Kind            string    `json:"kind"`
+	Points          int       `json:"points"`
+	Description     string    `json:"description"`
+	ReversesEntryID int       `json:"reverses_entry_id,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// LoyaltyTier is a membership level, reached by the points a member earned
+// over the last year.
+type LoyaltyTier struct {
+	Name             string   `json:"name"`
+	QualifyingPoints int      `json:"qualifying_points"`
+	EarnBonusPercent int      `json:"earn_bonus_percent"`
+	Benefits         []string `json:"benefits"`
+}
+
+// LoyaltyAccount is a member's standing: balance, tier and ledger.
+type LoyaltyAccount struct {
+	Balance          int            `json:"balance"`
+	Tier             LoyaltyTier    `json:"tier"`
+	QualifyingPoints int            `json:"qualifying_points"`
+	NextTier         *LoyaltyTier   `json:"next_tier,omitempty"`
+	PointsExpireAt   *time.Time     `json:"points_expire_at,omitempty"`
+	History          []LoyaltyEntry `json:"history"`
+}
@@ -0,0 +1,66 @@
+package data
+
+import (
+	"io"
+	"time"
+)
+
+// The two sides of a booking's message thread.
+const (
+	MessageFromGuest = "guest"
+	MessageFromHotel = "hotel"
+)
+
+// BookingMessage is one message in a booking's thread. ReadAt is set when
+// the other side first reads it.
+type BookingMessage struct {
+	ID          int                 `json:"id"`
+	BookingID   int                 `json:"booking_id"`
+	SenderID    int                 `json:"-"`
+	SenderName  string              `json:"sender_name"`
+	From        string              `json:"from"`
+	Body        string              `json:"body,omitempty"`
+	Attachments []MessageAttachment `json:"attachments,omitempty"`
+	ReadAt      *time.Time          `json:"read_at,omitempty"`
+	CreatedAt   time.Time           `json:"created_at"`
+}
+
+// MessageAttachment describes a file sent with a message. The file itself
+// is kept in the file store under StorageKey.
+type MessageAttachment struct {
+	ID          int       `json:"id"`
+	MessageID   int       `json:"-"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	StorageKey  string    `json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type PostMessageRequest struct {
+	Body string `json:"body"`
+}
+
+// MessageUpload is a file sent with a new message.
+type MessageUpload struct {
+	Filename string
+	Content  io.Reader
+}
+
+// MessageThread is the conversation on one booking, oldest message first.
+// Unread counts the messages from the other side that the reader had not
+// seen before this read.
+type MessageThread struct {
+	BookingID int              `json:"booking_id"`
+	Unread    int              `json:"unread"`
+	Messages  []BookingMessage `json:"messages"`
+}
+
+// UnreadThread is a booking with guest messages the hotel has not read.
+type UnreadThread struct {
+	BookingID        int       `json:"booking_id"`
+	ConfirmationCode string    `json:"confirmation_code"`
+	GuestName        string    `json:"guest_name"`
+	Unread           int       `json:"unread"`
+	LastMessageAt    time.Time `json:"last_message_at"`
+}
@@ -2,6 +2,8 @@ package data
 
 import (
 	"time"
+
+	"hotel-booking-service/internal/pkg/money"
 )
 
 type User struct {
@@ -9,38 +11,166 @@ type User struct {
 	Email     string    `json:"email"`
 	Password  string    `json:"-"`
 	Name      string    `json:"name"` 
+	Phone     string    `json:"phone,omitempty"`
+	Role      string    `json:"role"`
+	// HotelID is the hotel a staff member works at.
+	HotelID   *int      `json:"hotel_id,omitempty"`
+	Version   int       `json:"version"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// RoleGuest marks a user created for a booking made without an account. It
+// has no password and cannot log in.
+const RoleGuest = "guest"
+
 type Hotel struct {
-	ID    int    `json:"id"`
-	Name  string `json:"name"`
-	City  string `json:"city"`
-	Rooms []Room `json:"rooms,omitempty"`
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	City     string `json:"city"`
+	Currency string `json:"currency"`
+	// Description is free text about the hotel that full-text search
+	// looks through along with its name and city.
+	Description string `json:"description"`
+	// Latitude and Longitude place the hotel in decimal degrees. Both are
+	// set or neither is.
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
+	// TaxRateBP is the tax included in room prices, in basis points
+	// (1000 = 10%).
+	TaxRateBP int `json:"tax_rate_bp"`
+	// NoShowCutoffHour is how many hours after midnight on the arrival day
+	// an unarrived guest becomes a no-show, e.g. 26 for 02:00 the next day.
+	NoShowCutoffHour int `json:"no_show_cutoff_hour"`
+	Version          int `json:"version"`
+	// Rating is nil until the hotel has a published review.
+	Rating    *HotelRating `json:"rating,omitempty"`
+	Amenities []Amenity    `json:"amenities,omitempty"`
+	RoomTypes []RoomType   `json:"room_types,omitempty"`
+	Rooms     []Room       `json:"rooms,omitempty"`
+
+	// LowestPrice is the cheapest nightly rate of the room types that
+	// matched a search, filled in by hotel searches.
+	LowestPrice          *money.Money `json:"lowest_price,omitempty"`
+	ConvertedLowestPrice *money.Money `json:"converted_lowest_price,omitempty"`
+	// Snippet is the text that matched a full-text search, with the
+	// matching words wrapped in <mark> tags. The rest is HTML-escaped.
+	Snippet string `json:"snippet,omitempty"`
+	// DistanceKm is how far the hotel is from the point a search was
+	// made around.
+	DistanceKm *float64 `json:"distance_km,omitempty"`
+}
+
+// RoomType is what a hotel sells, e.g. "Standard Double". It owns the
+// capacity, description and rate; rooms are the physical units of a type.
+type RoomType struct {
+	ID          int         `json:"id"`
+	HotelID     int         `json:"hotel_id"`
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Capacity    int         `json:"capacity"`
+	Price       money.Money `json:"price"`
+	Version     int         `json:"version"`
+	CreatedAt   time.Time   `json:"created_at"`
+	Amenities   []Amenity   `json:"amenities,omitempty"`
+
+	// Available is the number of units left on every night of the requested
+	// stay, filled in by availability searches.
+	Available      *int         `json:"available,omitempty"`
+	ConvertedPrice *money.Money `json:"converted_price,omitempty"`
 }
 
+// Room is a physical unit. Capacity and Price come from its type.
 type Room struct {
-	ID       int     `json:"id"`
-	HotelID  int     `json:"hotel_id"`
-	Number   string  `json:"number"`
-	Capacity int     `json:"capacity"`
-	Price    float64 `json:"price"`
+	ID         int         `json:"id"`
+	HotelID    int         `json:"hotel_id"`
+	RoomTypeID int         `json:"room_type_id"`
+	RoomType   string      `json:"room_type"`
+	Number     string      `json:"number"`
+	Capacity   int         `json:"capacity"`
+	Price      money.Money `json:"price"`
+	// HousekeepingStatus is "dirty" from check-out until housekeeping marks
+	// the room clean again.
+	HousekeepingStatus string `json:"housekeeping_status"`
+	Version            int    `json:"version"`
+	// Amenities are those of the room's type and the room's own.
+	Amenities []Amenity `json:"amenities,omitempty"`
+
+	ConvertedPrice *money.Money `json:"converted_price,omitempty"`
+
+	// Restrictions lists the stay rules that block the requested dates.
+	Restrictions []RestrictionViolation `json:"restrictions,omitempty"`
 }
 
+// Booking reserves a room type. RoomID is zero until a room is assigned.
 type Booking struct {
-	ID        int       `json:"id"`
-	UserID    int       `json:"user_id"`
-	RoomID    int       `json:"room_id"`
-	FromDate  time.Time `json:"from_date"`
-	ToDate    time.Time `json:"to_date"`
-	Status    string    `json:"status"`
-	CreatedAt time.Time `json:"created_at"`
+	ID int `json:"id"`
+	// ConfirmationCode is what the guest quotes, e.g. "HBK-7F3Q9K"; unlike
+	// ID it cannot be guessed.
+	ConfirmationCode string `json:"confirmation_code"`
+
+	UserID      int         `json:"user_id"`
+	HotelID     int         `json:"hotel_id"`
+	RoomTypeID  int         `json:"room_type_id"`
+	RoomID      int         `json:"room_id,omitempty"`
+	FromDate    time.Time   `json:"from_date"`
+	ToDate      time.Time   `json:"to_date"`
+	Nights      int         `json:"nights"`
+	NightlyRate money.Money `json:"nightly_rate"`
+	TotalPrice  money.Money `json:"total_price"`
+
+	DisplayCurrency string       `json:"display_currency,omitempty"`
+	ExchangeRate    string       `json:"exchange_rate,omitempty"`
+	DisplayTotal    *money.Money `json:"display_total,omitempty"`
+
+	CancellationPolicy *CancellationPolicy `json:"cancellation_policy,omitempty"`
+	CancelledAt        *time.Time          `json:"cancelled_at,omitempty"`
+	Penalty            *money.Money        `json:"penalty,omitempty"`
+	Refund             *money.Money        `json:"refund,omitempty"`
+
+	// PointsRedeemed loyalty points bought LoyaltyDiscount off the price;
+	// TotalPrice is what is left to pay.
+	PointsRedeemed  int          `json:"points_redeemed,omitempty"`
+	LoyaltyDiscount *money.Money `json:"loyalty_discount,omitempty"`
+
+	// Extras are included in TotalPrice; ExtrasTotal is their share of it.
+	Extras      []BookingExtra `json:"extras,omitempty"`
+	ExtrasTotal *money.Money   `json:"extras_total,omitempty"`
+
+	CheckedInAt     *time.Time `json:"checked_in_at,omitempty"`
+	GuestIDDocument string     `json:"guest_id_document,omitempty"`
+	CheckedOutAt    *time.Time `json:"checked_out_at,omitempty"`
+
+	Status      string      `json:"status"`
+	Version     int         `json:"version"`
+	CreatedAt   time.Time   `json:"created_at"`
 }
 
+// CreateBookingRequest books a room type, or one specific room when RoomID
+// is set.
 type CreateBookingRequest struct {
-	RoomID   int       `json:"room_id"`
-	FromDate time.Time `json:"from_date"`
-	ToDate   time.Time `json:"to_date"`
+	RoomTypeID int       `json:"room_type_id,omitempty"`
+	RoomID     int       `json:"room_id,omitempty"`
+	FromDate   time.Time `json:"from_date"`
+	ToDate     time.Time `json:"to_date"`
+	Currency   string    `json:"currency,omitempty"`
+	// RedeemPoints spends loyalty points, in blocks of 100, as a discount.
+	RedeemPoints int `json:"redeem_points,omitempty"`
+	// Extras are added to the booking, e.g. breakfast or parking.
+	Extras []ExtraRequest `json:"extras,omitempty"`
+}
+
+type RegisterRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Name     string `json:"name,omitempty"`
+}
+
+// ClaimGuestRequest completes a registration with the token from the link
+// emailed to a guest.
+type ClaimGuestRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+	Name     string `json:"name,omitempty"`
 }
 
 type LoginRequest struct {
@@ -0,0 +1,25 @@
+package data
+
+import (
+	"time"
+
+	"hotel-booking-service/internal/pkg/money"
+)
+
+type Payment struct {
+	ID          int         `json:"id"`
+	BookingID   int         `json:"booking_id"`
+	Provider    string      `json:"provider"`
+	ProviderRef string      `json:"provider_ref"`
+	Amount      money.Money `json:"amount"`
+	Captured    money.Money `json:"captured"`
+	Refunded    money.Money `json:"refunded"`
+	Status      string      `json:"status"`
+	ConfirmURL  string      `json:"confirm_url,omitempty"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+type PaymentAmountRequest struct {
+	Amount *money.Money `json:"amount,omitempty"`
+}
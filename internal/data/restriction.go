@@ -0,0 +1,40 @@
+package data
+
+import "time"
+
+const (
+	RestrictionMinStay           = "min_stay"
+	RestrictionMaxStay           = "max_stay"
+	RestrictionClosedToArrival   = "closed_to_arrival"
+	RestrictionClosedToDeparture = "closed_to_departure"
+	RestrictionMinLead           = "min_lead_days"
+	RestrictionMaxLead           = "max_lead_days"
+)
+
+// StayRestriction limits which stays can be booked. It applies to dates from
+// FromDate to ToDate inclusive and, when DaysOfWeek is set, only to those
+// weekdays (0 = Sunday). Stay length and lead time are judged on the arrival
+// date, closed-to-departure on the departure date. Zero means no limit.
+// RoomTypeID is nil for rules covering the whole hotel.
+type StayRestriction struct {
+	ID                int       `json:"id,omitempty"`
+	HotelID           int       `json:"hotel_id"`
+	RoomTypeID        *int      `json:"room_type_id,omitempty"`
+	FromDate          time.Time `json:"from_date"`
+	ToDate            time.Time `json:"to_date"`
+	DaysOfWeek        []int     `json:"days_of_week,omitempty"`
+	MinStay           int       `json:"min_stay,omitempty"`
+	MaxStay           int       `json:"max_stay,omitempty"`
+	ClosedToArrival   bool      `json:"closed_to_arrival,omitempty"`
+	ClosedToDeparture bool      `json:"closed_to_departure,omitempty"`
+	MinLeadDays       int       `json:"min_lead_days,omitempty"`
+	MaxLeadDays       int       `json:"max_lead_days,omitempty"`
+	CreatedAt         time.Time `json:"created_at,omitempty"`
+}
+
+// RestrictionViolation explains why a stay cannot be booked.
+type RestrictionViolation struct {
+	RestrictionID int    `json:"restriction_id"`
+	Rule          string `json:"rule"`
+	Message       string `json:"message"`
+}
@@ -0,0 +1,66 @@
+package data
+
+import "time"
+
+const (
+	ReviewPublished = "published"
+	ReviewHidden    = "hidden"
+)
+
+// Review is a guest's verdict on a stay that checked out. Scores run from
+// 1 to 5; Overall is their mean.
+type Review struct {
+	ID          int     `json:"id"`
+	BookingID   int     `json:"booking_id"`
+	HotelID     int     `json:"hotel_id"`
+	UserID      int     `json:"-"`
+	AuthorName  string  `json:"author_name"`
+	Cleanliness int     `json:"cleanliness"`
+	Location    int     `json:"location"`
+	Service     int     `json:"service"`
+	Overall     float64 `json:"overall"`
+	Comment     string  `json:"comment,omitempty"`
+
+	Status           string     `json:"status"`
+	ModerationReason string     `json:"moderation_reason,omitempty"`
+	ModeratedAt      *time.Time `json:"moderated_at,omitempty"`
+
+	Reply     string     `json:"reply,omitempty"`
+	RepliedAt *time.Time `json:"replied_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CreateReviewRequest struct {
+	Cleanliness int    `json:"cleanliness"`
+	Location    int    `json:"location"`
+	Service     int    `json:"service"`
+	Comment     string `json:"comment,omitempty"`
+}
+
+type ReviewReplyRequest struct {
+	Reply string `json:"reply"`
+}
+
+// ModerateReviewRequest publishes or hides a review. Reason is shown to
+// staff, not to the public.
+type ModerateReviewRequest struct {
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// HotelRating sums up a hotel's published reviews.
+type HotelRating struct {
+	Overall     float64 `json:"overall"`
+	Cleanliness float64 `json:"cleanliness"`
+	Location    float64 `json:"location"`
+	Service     float64 `json:"service"`
+	Reviews     int     `json:"reviews"`
+}
+
+// HotelFilter narrows and orders hotel listings. MinRating leaves out
+// hotels rated lower or not rated at all; Sort is "" or "rating".
+type HotelFilter struct {
+	MinRating float64
+	Sort      string
+}
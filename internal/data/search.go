@@ -0,0 +1,59 @@
+package data
+
+import (
+	"time"
+
+	"hotel-booking-service/internal/pkg/geo"
+	"hotel-booking-service/internal/pkg/money"
+)
+
+// Ways to order hotel search results.
+const (
+	SearchSortPrice  = "price"
+	SearchSortRating = "rating"
+	SearchSortName   = "name"
+	// SearchSortRelevance ranks full-text matches, best first. It is the
+	// default when the search has Text.
+	SearchSortRelevance = "relevance"
+	// SearchSortDistance puts the hotels nearest the search's point first.
+	// It is the default when the search has a point but no Text.
+	SearchSortDistance = "distance"
+)
+
+// HotelSearch finds hotels with a room type that sleeps Guests and has a
+// unit free on every night from FromDate to ToDate. Prices are compared in
+// Currency: MinPrice and MaxPrice bound the nightly rate of the matching
+// room types, and sorting by price uses the cheapest of them. Text narrows
+// the search to hotels whose name, city or description has every one of its
+// words, or a word starting with it. Near narrows it to hotels within
+// RadiusKm of a point, and has each hotel's distance from it returned.
+// Amenities lists amenity codes that must all be had: hotel amenities by
+// the hotel and room amenities by the matching room type or one of its rooms.
+// Order is "asc" or "desc".
+type HotelSearch struct {
+	Text      string
+	City      string
+	Near      *geo.Point
+	RadiusKm  float64
+	Amenities []string
+	FromDate  time.Time
+	ToDate    time.Time
+	Guests    int
+	Currency  string
+	MinPrice  *money.Money
+	MaxPrice  *money.Money
+	MinRating float64
+	Sort      string
+	Order     string
+	Limit     int
+	Offset    int
+}
+
+// HotelSearchResult is one page of matching hotels. Total counts every
+// match, not just this page.
+type HotelSearchResult struct {
+	Hotels []Hotel `json:"hotels"`
+	Total  int     `json:"total"`
+	Limit  int     `json:"limit"`
+	Offset int     `json:"offset"`
+}
@@ -0,0 +1,42 @@
+package data
+
+import "time"
+
+const (
+	WaitlistWaiting   = "waiting"
+	WaitlistOffered   = "offered"
+	WaitlistClaimed   = "claimed"
+	WaitlistExpired   = "expired"
+	WaitlistCancelled = "cancelled"
+)
+
+// WaitlistEntry is a guest's place in line for a room type of a hotel. Any
+// unit of the type freed for their dates can be offered to them. RoomTypeID
+// is nil only on entries made before the waitlist was kept per type; those
+// take any type that fits the party.
+type WaitlistEntry struct {
+	ID             int        `json:"id"`
+	UserID         int        `json:"user_id"`
+	HotelID        int        `json:"hotel_id"`
+	RoomTypeID     *int       `json:"room_type_id,omitempty"`
+	FromDate       time.Time  `json:"from_date"`
+	ToDate         time.Time  `json:"to_date"`
+	Guests         int        `json:"guests"`
+	Status         string     `json:"status"`
+	OfferedRoomID  *int       `json:"offered_room_id,omitempty"`
+	OfferExpiresAt *time.Time `json:"offer_expires_at,omitempty"`
+	BookingID      *int       `json:"booking_id,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+type CreateWaitlistRequest struct {
+	HotelID    int       `json:"hotel_id"`
+	RoomTypeID int       `json:"room_type_id"`
+	FromDate   time.Time `json:"from_date"`
+	ToDate     time.Time `json:"to_date"`
+	Guests     int       `json:"guests"`
+}
+
+type ClaimWaitlistRequest struct {
+	Currency string `json:"currency,omitempty"`
+}
@@ -0,0 +1,167 @@
+package deliveries
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"hotel-booking-service/internal/data"
+	"hotel-booking-service/internal/usecases"
+)
+
+type AmenityController struct {
+	amenityUsecase *usecases.AmenityUsecase
+}
+
+func NewAmenityController(amenityUsecase *usecases.AmenityUsecase) *AmenityController {
+	return &AmenityController{
+		amenityUsecase: amenityUsecase,
+	}
+}
+
+// GetAmenities lists the catalog, so that clients can offer its codes as
+// search filters.
+func (c *AmenityController) GetAmenities(w http.ResponseWriter, r *http.Request) {
+	amenities, err := c.amenityUsecase.GetAmenities()
+	if err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(amenities)
+}
+
+func (c *AmenityController) CreateAmenity(w http.ResponseWriter, r *http.Request) {
+	var amenity data.Amenity
+	if err := json.NewDecoder(r.Body).Decode(&amenity); err != nil {
+		sendErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	created, err := c.amenityUsecase.CreateAmenity(amenity)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+func (c *AmenityController) UpdateAmenity(w http.ResponseWriter, r *http.Request) {
+	amenityID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid amenity ID", http.StatusBadRequest)
+		return
+	}
+
+	var amenity data.Amenity
+	if err := json.NewDecoder(r.Body).Decode(&amenity); err != nil {
+		sendErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	amenity.ID = amenityID
+
+	updated, err := c.amenityUsecase.UpdateAmenity(amenity)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
+
+func (c *AmenityController) DeleteAmenity(w http.ResponseWriter, r *http.Request) {
+	amenityID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid amenity ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.amenityUsecase.DeleteAmenity(amenityID); err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SetHotelAmenities takes {"amenities": ["wifi", "pool"]} and replaces the
+// hotel's amenities with those.
+func (c *AmenityController) SetHotelAmenities(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := strconv.Atoi(mux.Vars(r)["hotelID"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid hotel ID", http.StatusBadRequest)
+		return
+	}
+
+	var req data.SetAmenitiesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	amenities, err := c.amenityUsecase.SetHotelAmenities(hotelID, req.Amenities)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(amenities)
+}
+
+// SetRoomTypeAmenities replaces a room type's amenities, like
+// SetHotelAmenities.
+func (c *AmenityController) SetRoomTypeAmenities(w http.ResponseWriter, r *http.Request) {
+	roomTypeID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid room type ID", http.StatusBadRequest)
+		return
+	}
+
+	var req data.SetAmenitiesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	amenities, err := c.amenityUsecase.SetRoomTypeAmenities(roomTypeID, req.Amenities)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(amenities)
+}
+
+// SetRoomAmenities replaces the amenities a room has on top of its type's,
+// like SetHotelAmenities.
+func (c *AmenityController) SetRoomAmenities(w http.ResponseWriter, r *http.Request) {
+	roomID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid room ID", http.StatusBadRequest)
+		return
+	}
+
+	var req data.SetAmenitiesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	amenities, err := c.amenityUsecase.SetRoomAmenities(roomID, req.Amenities)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(amenities)
+}
@@ -19,7 +19,7 @@ func NewAuthController(authUsecase *usecases.AuthUsecase) *AuthController {
 }
 
 func (c *AuthController) Register(w http.ResponseWriter, r *http.Request) {
-	var req data.LoginRequest
+	var req data.RegisterRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
@@ -30,12 +30,47 @@ func (c *AuthController) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
-	user, err := c.authUsecase.Register(req.Email, req.Password)
+	user, err := c.authUsecase.Register(r.Context(), req.Email, req.Password, req.Name)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	
+	// The email belongs to a guest, who was sent a link to finish.
+	if user == nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]string{
+			"message": "This email has guest bookings. We sent a link to it to complete the registration.",
+		})
+		return
+	}
+	
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(user)
+}
+
+// ClaimGuest completes the registration of a guest who followed the link
+// sent by Register.
+func (c *AuthController) ClaimGuest(w http.ResponseWriter, r *http.Request) {
+	var req data.ClaimGuestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	
+	if req.Token == "" || req.Password == "" {
+		http.Error(w, "Token and password are required", http.StatusBadRequest)
+		return
+	}
+	
+	user, err := c.authUsecase.ClaimGuest(req.Token, req.Password, req.Name)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+	
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(user)
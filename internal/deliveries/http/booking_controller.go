@@ -58,10 +58,10 @@ func (c *BookingController) CreateBooking(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	log.Printf("Booking request: Room ID: %d, From: %s, To: %s", 
-		req.RoomID, req.FromDate.Format(time.RFC3339), req.ToDate.Format(time.RFC3339))
+	log.Printf("Booking request: Room type ID: %d, Room ID: %d, From: %s, To: %s", 
+		req.RoomTypeID, req.RoomID, req.FromDate.Format(time.RFC3339), req.ToDate.Format(time.RFC3339))
 
-	booking, err := c.bookingUsecase.CreateBooking(userID, req.RoomID, req.FromDate, req.ToDate)
+	booking, err := c.bookingUsecase.CreateBooking(userID, req.RoomTypeID, req.RoomID, req.FromDate, req.ToDate, req.Currency, req.RedeemPoints, req.Extras)
 	if err != nil {
 		log.Printf("Error creating booking: %v", err)
 		if err.Error() == "room not available for the selected dates" {
@@ -78,6 +78,8 @@ func (c *BookingController) CreateBooking(w http.ResponseWriter, r *http.Request
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"message": "Booking created successfully",
 		"booking_id": booking.ID,
+		"total_price": booking.TotalPrice,
+		"display_total": booking.DisplayTotal,
 	})
 }
 
@@ -108,30 +110,33 @@ func (c *BookingController) CancelBooking(w http.ResponseWriter, r *http.Request
 		return
 	}
 	
-	err = c.bookingUsecase.CancelBooking(userID, bookingID)
+	version, err := ifMatchVersion(r)
 	if err != nil {
-		switch err.Error() {
-		case "booking not found":
-			sendErrorResponse(w, err.Error(), http.StatusNotFound)
-		case "booking does not belong to this user":
-			sendErrorResponse(w, err.Error(), http.StatusForbidden)
-		default:
-			sendErrorResponse(w, err.Error(), http.StatusBadRequest)
-		}
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+	
+	result, err := c.bookingUsecase.CancelBooking(userID, bookingID, version)
+	if err != nil {
+		sendCancellationError(w, err)
 		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{"message": "Booking cancelled successfully"})
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Booking cancelled successfully",
+		"penalty": result.Penalty,
+		"refund":  result.Refund,
+	})
 }
 
-func (c *BookingController) GetBookingByID(w http.ResponseWriter, r *http.Request) {
-	defer func() {
-		if r := recover(); r != nil {
-			log.Printf("Recovered from panic in GetBookingByID: %v", r)
-			sendErrorResponse(w, "Internal server error", http.StatusInternalServerError)
-		}
-	}()
+func (c *BookingController) PreviewCancellation(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(userIDContextKey).(int)
+	if !ok {
+		sendErrorResponse(w, "User ID not found in context", http.StatusUnauthorized)
+		return
+	}
 
 	vars := mux.Vars(r)
 	bookingID, err := strconv.Atoi(vars["id"])
@@ -140,14 +145,28 @@ func (c *BookingController) GetBookingByID(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	booking, err := c.bookingUsecase.GetBookingByID(bookingID)
+	result, err := c.bookingUsecase.PreviewCancellation(userID, bookingID)
 	if err != nil {
-		sendErrorResponse(w, err.Error(), http.StatusNotFound)
+		sendCancellationError(w, err)
 		return
 	}
 
-	if booking == nil {
-		sendErrorResponse(w, "Booking not found", http.StatusNotFound)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// LookupBooking is the public, logged-out view of a booking for guests who
+// know its confirmation code.
+func (c *BookingController) LookupBooking(w http.ResponseWriter, r *http.Request) {
+	var req data.BookingLookupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	booking, err := c.bookingUsecase.LookupBooking(req)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
 		return
 	}
 
@@ -155,14 +174,37 @@ func (c *BookingController) GetBookingByID(w http.ResponseWriter, r *http.Reques
 	json.NewEncoder(w).Encode(booking)
 }
 
-func (c *BookingController) UpdateBooking(w http.ResponseWriter, r *http.Request) {
+func sendCancellationError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case "booking not found":
+		sendErrorResponse(w, err.Error(), http.StatusNotFound)
+	case "booking does not belong to this user":
+		sendErrorResponse(w, err.Error(), http.StatusForbidden)
+	case "stays that have already started cannot be cancelled":
+		sendErrorResponse(w, err.Error(), http.StatusConflict)
+	default:
+		status := errorStatus(err)
+		if status == http.StatusInternalServerError {
+			status = http.StatusBadRequest
+		}
+		sendErrorResponse(w, err.Error(), status)
+	}
+}
+
+func (c *BookingController) GetBookingByID(w http.ResponseWriter, r *http.Request) {
 	defer func() {
 		if r := recover(); r != nil {
-			log.Printf("Recovered from panic in UpdateBooking: %v", r)
+			log.Printf("Recovered from panic in GetBookingByID: %v", r)
 			sendErrorResponse(w, "Internal server error", http.StatusInternalServerError)
 		}
 	}()
 
+	userID, ok := r.Context().Value(userIDContextKey).(int)
+	if !ok {
+		sendErrorResponse(w, "User ID not found in context", http.StatusUnauthorized)
+		return
+	}
+
 	vars := mux.Vars(r)
 	bookingID, err := strconv.Atoi(vars["id"])
 	if err != nil {
@@ -170,20 +212,15 @@ func (c *BookingController) UpdateBooking(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	var req data.UpdateBookingRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		sendErrorResponse(w, "Invalid request body", http.StatusBadRequest)
-		return
-	}
-
-	err = c.bookingUsecase.UpdateBooking(bookingID, req.Status)
+	booking, err := c.bookingUsecase.GetBookingByID(userID, bookingID)
 	if err != nil {
-		sendErrorResponse(w, err.Error(), http.StatusBadRequest)
+		sendErrorResponse(w, err.Error(), errorStatus(err))
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{"message": "Booking updated successfully"})
+	setETag(w, booking.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(booking)
 }
 
 func (c *BookingController) GetUserBookings(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,111 @@
+package deliveries
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"hotel-booking-service/internal/data"
+	"hotel-booking-service/internal/usecases"
+)
+
+type CancellationPolicyController struct {
+	policyUsecase *usecases.CancellationPolicyUsecase
+}
+
+func NewCancellationPolicyController(policyUsecase *usecases.CancellationPolicyUsecase) *CancellationPolicyController {
+	return &CancellationPolicyController{
+		policyUsecase: policyUsecase,
+	}
+}
+
+func (c *CancellationPolicyController) GetHotelPolicies(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	hotelID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid hotel ID", http.StatusBadRequest)
+		return
+	}
+
+	policies, err := c.policyUsecase.GetHotelPolicies(hotelID)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(policies)
+}
+
+func (c *CancellationPolicyController) CreatePolicy(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	hotelID, err := strconv.Atoi(vars["hotelID"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid hotel ID", http.StatusBadRequest)
+		return
+	}
+
+	var policy data.CancellationPolicy
+	if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+		sendErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	policy.HotelID = hotelID
+
+	created, err := c.policyUsecase.CreatePolicy(policy)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+func (c *CancellationPolicyController) UpdatePolicy(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	policyID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid policy ID", http.StatusBadRequest)
+		return
+	}
+
+	var policy data.CancellationPolicy
+	if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+		sendErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	policy.ID = policyID
+
+	updated, err := c.policyUsecase.UpdatePolicy(policy)
+	if err != nil {
+		if err.Error() == "cancellation policy not found" {
+			sendErrorResponse(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
+
+func (c *CancellationPolicyController) DeletePolicy(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	policyID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid policy ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.policyUsecase.DeletePolicy(policyID); err != nil {
+		sendErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
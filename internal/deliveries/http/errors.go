@@ -0,0 +1,42 @@
+package deliveries
+
+import (
+	"errors"
+	"net/http"
+
+	"hotel-booking-service/internal/payments"
+	"hotel-booking-service/internal/pkg/apperror"
+)
+
+// errorStatus maps usecase errors onto HTTP status codes. Validation errors
+// wrap apperror sentinels; anything else is treated as a server error.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, apperror.ErrInvalidRequest):
+		return http.StatusBadRequest
+	case errors.Is(err, apperror.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, apperror.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, apperror.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, apperror.ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err, apperror.ErrPreconditionFailed):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, apperror.ErrPreconditionRequired):
+		return http.StatusPreconditionRequired
+	case errors.Is(err, payments.ErrProvider):
+		return http.StatusBadGateway
+	}
+
+	switch err.Error() {
+	case "hotel not found", "room not found", "user not found":
+		return http.StatusNotFound
+	case "booking not found":
+		return http.StatusNotFound
+	case "booking does not belong to this user":
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,33 @@
+package deliveries
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"hotel-booking-service/internal/pkg/apperror"
+)
+
+// setETag tags a response with the version of the resource it carries.
+// Clients send it back in If-Match to change or delete the resource.
+func setETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", `"`+strconv.Itoa(version)+`"`)
+}
+
+// ifMatchVersion reads the version the client last saw from If-Match. A
+// write without it could overwrite changes the client never saw, so it is
+// refused; so is a weak or malformed tag, which matches no version.
+func ifMatchVersion(r *http.Request) (int, error) {
+	tag := strings.TrimSpace(r.Header.Get("If-Match"))
+	if tag == "" {
+		return 0, fmt.Errorf("%w: send the ETag you last read in an If-Match header", apperror.ErrPreconditionRequired)
+	}
+
+	version, err := strconv.Atoi(strings.Trim(tag, `"`))
+	if err != nil || !strings.HasPrefix(tag, `"`) || !strings.HasSuffix(tag, `"`) {
+		return 0, fmt.Errorf("%w: If-Match does not match the current version", apperror.ErrPreconditionFailed)
+	}
+
+	return version, nil
+}
@@ -0,0 +1,61 @@
+package deliveries
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"hotel-booking-service/internal/data"
+	"hotel-booking-service/internal/usecases"
+)
+
+type ExchangeRateController struct {
+	currencyUsecase *usecases.CurrencyUsecase
+}
+
+func NewExchangeRateController(currencyUsecase *usecases.CurrencyUsecase) *ExchangeRateController {
+	return &ExchangeRateController{
+		currencyUsecase: currencyUsecase,
+	}
+}
+
+func (c *ExchangeRateController) GetRates(w http.ResponseWriter, r *http.Request) {
+	rates, err := c.currencyUsecase.GetRates()
+	if err != nil {
+		sendErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rates)
+}
+
+func (c *ExchangeRateController) UpdateRates(w http.ResponseWriter, r *http.Request) {
+	var rates []data.ExchangeRate
+	if err := json.NewDecoder(r.Body).Decode(&rates); err != nil {
+		sendErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.currencyUsecase.UpdateRates(rates); err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Exchange rates updated successfully",
+		"count":   len(rates),
+	})
+}
+
+func (c *ExchangeRateController) DeleteRate(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if err := c.currencyUsecase.DeleteRate(vars["base"], vars["quote"]); err != nil {
+		sendErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
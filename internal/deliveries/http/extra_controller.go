@@ -0,0 +1,172 @@
+package deliveries
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"hotel-booking-service/internal/data"
+	"hotel-booking-service/internal/usecases"
+)
+
+type ExtraController struct {
+	extraUsecase *usecases.ExtraUsecase
+}
+
+func NewExtraController(extraUsecase *usecases.ExtraUsecase) *ExtraController {
+	return &ExtraController{
+		extraUsecase: extraUsecase,
+	}
+}
+
+// GetHotelExtras lists the extras a hotel sells. With ?from_date= and
+// ?to_date= (YYYY-MM-DD), extras with limited inventory show how many are
+// left for the stay.
+func (c *ExtraController) GetHotelExtras(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid hotel ID", http.StatusBadRequest)
+		return
+	}
+
+	var fromDate, toDate time.Time
+	if value := r.URL.Query().Get("from_date"); value != "" {
+		fromDate, err = time.Parse("2006-01-02", value)
+		if err != nil {
+			sendErrorResponse(w, "Invalid from_date, expected YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+	}
+	if value := r.URL.Query().Get("to_date"); value != "" {
+		toDate, err = time.Parse("2006-01-02", value)
+		if err != nil {
+			sendErrorResponse(w, "Invalid to_date, expected YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+	}
+
+	extras, err := c.extraUsecase.GetHotelExtras(hotelID, fromDate, toDate)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(extras)
+}
+
+func (c *ExtraController) GetAllHotelExtras(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := strconv.Atoi(mux.Vars(r)["hotelID"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid hotel ID", http.StatusBadRequest)
+		return
+	}
+
+	extras, err := c.extraUsecase.GetAllHotelExtras(hotelID)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(extras)
+}
+
+func (c *ExtraController) CreateExtra(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := strconv.Atoi(mux.Vars(r)["hotelID"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid hotel ID", http.StatusBadRequest)
+		return
+	}
+
+	var extra data.Extra
+	if err := json.NewDecoder(r.Body).Decode(&extra); err != nil {
+		sendErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	extra.HotelID = hotelID
+
+	created, err := c.extraUsecase.CreateExtra(extra)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+func (c *ExtraController) UpdateExtra(w http.ResponseWriter, r *http.Request) {
+	extraID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid extra ID", http.StatusBadRequest)
+		return
+	}
+
+	var extra data.Extra
+	if err := json.NewDecoder(r.Body).Decode(&extra); err != nil {
+		sendErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	extra.ID = extraID
+
+	updated, err := c.extraUsecase.UpdateExtra(extra)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
+
+func (c *ExtraController) DeleteExtra(w http.ResponseWriter, r *http.Request) {
+	extraID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid extra ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.extraUsecase.DeleteExtra(extraID); err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AddToBooking adds extras to one of the user's bookings, or to a guest's
+// booking through its link.
+func (c *ExtraController) AddToBooking(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(userIDContextKey).(int)
+	if !ok {
+		sendErrorResponse(w, "User ID not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	bookingID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid booking ID", http.StatusBadRequest)
+		return
+	}
+
+	var req data.AddExtrasRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	booking, err := c.extraUsecase.AddToBooking(userID, bookingID, req.Extras)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	setETag(w, booking.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(booking)
+}
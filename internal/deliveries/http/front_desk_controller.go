@@ -0,0 +1,158 @@
+package deliveries
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"hotel-booking-service/internal/data"
+	"hotel-booking-service/internal/usecases"
+)
+
+type FrontDeskController struct {
+	frontDeskUsecase *usecases.FrontDeskUsecase
+}
+
+func NewFrontDeskController(frontDeskUsecase *usecases.FrontDeskUsecase) *FrontDeskController {
+	return &FrontDeskController{
+		frontDeskUsecase: frontDeskUsecase,
+	}
+}
+
+func (c *FrontDeskController) Arrivals(w http.ResponseWriter, r *http.Request) {
+	c.sendList(w, r, c.frontDeskUsecase.Arrivals)
+}
+
+func (c *FrontDeskController) InHouse(w http.ResponseWriter, r *http.Request) {
+	c.sendList(w, r, c.frontDeskUsecase.InHouse)
+}
+
+func (c *FrontDeskController) Departures(w http.ResponseWriter, r *http.Request) {
+	c.sendList(w, r, c.frontDeskUsecase.Departures)
+}
+
+// sendList serves one of the daily lists for the hotel in the path and the
+// date in ?date=YYYY-MM-DD, today by default.
+func (c *FrontDeskController) sendList(w http.ResponseWriter, r *http.Request, list func(int, int, time.Time) (*data.FrontDeskList, error)) {
+	staffID, ok := r.Context().Value(userIDContextKey).(int)
+	if !ok {
+		sendErrorResponse(w, "User ID not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	hotelID, err := strconv.Atoi(mux.Vars(r)["hotelID"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid hotel ID", http.StatusBadRequest)
+		return
+	}
+
+	date := time.Now()
+	if value := r.URL.Query().Get("date"); value != "" {
+		date, err = time.Parse("2006-01-02", value)
+		if err != nil {
+			sendErrorResponse(w, "Invalid date, expected YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+	}
+
+	result, err := list(staffID, hotelID, date)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+func (c *FrontDeskController) CheckIn(w http.ResponseWriter, r *http.Request) {
+	staffID, ok := r.Context().Value(userIDContextKey).(int)
+	if !ok {
+		sendErrorResponse(w, "User ID not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	bookingID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid booking ID", http.StatusBadRequest)
+		return
+	}
+
+	var req data.CheckInRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	booking, err := c.frontDeskUsecase.CheckIn(staffID, bookingID, req)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(booking)
+}
+
+func (c *FrontDeskController) CheckOut(w http.ResponseWriter, r *http.Request) {
+	staffID, ok := r.Context().Value(userIDContextKey).(int)
+	if !ok {
+		sendErrorResponse(w, "User ID not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	bookingID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid booking ID", http.StatusBadRequest)
+		return
+	}
+
+	// The body is optional for a check-out on time.
+	var req data.CheckOutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		sendErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	result, err := c.frontDeskUsecase.CheckOut(r.Context(), staffID, bookingID, req)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+func (c *FrontDeskController) SetHousekeepingStatus(w http.ResponseWriter, r *http.Request) {
+	staffID, ok := r.Context().Value(userIDContextKey).(int)
+	if !ok {
+		sendErrorResponse(w, "User ID not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	roomID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid room ID", http.StatusBadRequest)
+		return
+	}
+
+	var req data.HousekeepingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	room, err := c.frontDeskUsecase.SetHousekeepingStatus(staffID, roomID, req.Status)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(room)
+}
@@ -0,0 +1,70 @@
+package deliveries
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"hotel-booking-service/internal/data"
+	"hotel-booking-service/internal/usecases"
+)
+
+type GuestController struct {
+	guestUsecase *usecases.GuestUsecase
+}
+
+func NewGuestController(guestUsecase *usecases.GuestUsecase) *GuestController {
+	return &GuestController{
+		guestUsecase: guestUsecase,
+	}
+}
+
+// CreateBooking books for a guest without an account.
+func (c *GuestController) CreateBooking(w http.ResponseWriter, r *http.Request) {
+	var req data.GuestBookingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	result, err := c.guestUsecase.CreateBooking(r.Context(), req)
+	if err != nil {
+		if err.Error() == "room not available for the selected dates" {
+			sendErrorResponse(w, err.Error(), http.StatusConflict)
+			return
+		}
+		status := errorStatus(err)
+		if status == http.StatusInternalServerError {
+			status = http.StatusBadRequest
+		}
+		sendErrorResponse(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(result)
+}
+
+// UpdateContact changes the guest's contact details through a booking link.
+func (c *GuestController) UpdateContact(w http.ResponseWriter, r *http.Request) {
+	guestID, ok := r.Context().Value(userIDContextKey).(int)
+	if !ok {
+		sendErrorResponse(w, "User ID not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	var req data.GuestDetails
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	guest, err := c.guestUsecase.UpdateContact(guestID, req)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(guest)
+}
@@ -4,12 +4,15 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 	
 	"github.com/gorilla/mux"
 	
 	"hotel-booking-service/internal/usecases"
 	"hotel-booking-service/internal/data" 
+	"hotel-booking-service/internal/pkg/geo"
+	"hotel-booking-service/internal/pkg/money"
 )
 
 type HotelController struct {
@@ -43,9 +46,19 @@ func (c *HotelController) GetAllHotels(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 	
-	hotels, err := c.hotelUsecase.GetAllHotels(fromDate, toDate)
+	filter := data.HotelFilter{Sort: r.URL.Query().Get("sort")}
+	if value := r.URL.Query().Get("min_rating"); value != "" {
+		minRating, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			http.Error(w, "Invalid min_rating", http.StatusBadRequest)
+			return
+		}
+		filter.MinRating = minRating
+	}
+	
+	hotels, err := c.hotelUsecase.GetAllHotels(fromDate, toDate, r.URL.Query().Get("currency"), filter)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), errorStatus(err))
 		return
 	}
 	
@@ -53,6 +66,116 @@ func (c *HotelController) GetAllHotels(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(hotels)
 }
 
+// SearchHotels finds hotels with a room for the party on every night of the
+// stay. All parameters are optional; see the README for what they mean.
+func (c *HotelController) SearchHotels(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	search := data.HotelSearch{
+		Text:     strings.TrimSpace(query.Get("q")),
+		City:     strings.TrimSpace(query.Get("city")),
+		Currency: query.Get("currency"),
+		Sort:     query.Get("sort"),
+		Order:    query.Get("order"),
+		FromDate: time.Now(),
+	}
+	search.ToDate = search.FromDate.AddDate(0, 0, 1)
+
+	for _, param := range []struct {
+		name string
+		dest *time.Time
+	}{
+		{"from_date", &search.FromDate},
+		{"to_date", &search.ToDate},
+	} {
+		if value := query.Get(param.name); value != "" {
+			parsed, err := time.Parse("2006-01-02", value)
+			if err != nil {
+				http.Error(w, "Invalid "+param.name+", expected YYYY-MM-DD", http.StatusBadRequest)
+				return
+			}
+			*param.dest = parsed
+		}
+	}
+	if query.Get("from_date") != "" && query.Get("to_date") == "" {
+		search.ToDate = search.FromDate.AddDate(0, 0, 1)
+	}
+
+	for _, param := range []struct {
+		name string
+		dest *int
+	}{
+		{"guests", &search.Guests},
+		{"limit", &search.Limit},
+		{"offset", &search.Offset},
+	} {
+		if value := query.Get(param.name); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				http.Error(w, "Invalid "+param.name, http.StatusBadRequest)
+				return
+			}
+			*param.dest = parsed
+		}
+	}
+
+	for _, param := range []struct {
+		name string
+		dest **money.Money
+	}{
+		{"min_price", &search.MinPrice},
+		{"max_price", &search.MaxPrice},
+	} {
+		if value := query.Get(param.name); value != "" {
+			parsed, err := money.Parse(value, strings.ToUpper(search.Currency))
+			if err != nil {
+				http.Error(w, "Invalid "+param.name, http.StatusBadRequest)
+				return
+			}
+			*param.dest = &parsed
+		}
+	}
+
+	if lat, lng := query.Get("lat"), query.Get("lng"); lat != "" || lng != "" {
+		latitude, latErr := strconv.ParseFloat(lat, 64)
+		longitude, lngErr := strconv.ParseFloat(lng, 64)
+		if latErr != nil || lngErr != nil {
+			http.Error(w, "lat and lng must both be given as decimal degrees", http.StatusBadRequest)
+			return
+		}
+		search.Near = &geo.Point{Latitude: latitude, Longitude: longitude}
+	}
+	if value := query.Get("radius_km"); value != "" {
+		radius, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			http.Error(w, "Invalid radius_km", http.StatusBadRequest)
+			return
+		}
+		search.RadiusKm = radius
+	}
+
+	for _, value := range query["amenities"] {
+		search.Amenities = append(search.Amenities, strings.Split(value, ",")...)
+	}
+
+	if value := query.Get("min_rating"); value != "" {
+		minRating, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			http.Error(w, "Invalid min_rating", http.StatusBadRequest)
+			return
+		}
+		search.MinRating = minRating
+	}
+
+	result, err := c.hotelUsecase.SearchHotels(search)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
 func (c *HotelController) GetHotelByID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	hotelID, err := strconv.Atoi(vars["id"])
@@ -81,9 +204,9 @@ func (c *HotelController) GetHotelByID(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 	
-	hotel, err := c.hotelUsecase.GetHotelByID(hotelID, fromDate, toDate)
+	hotel, err := c.hotelUsecase.GetHotelByID(hotelID, fromDate, toDate, r.URL.Query().Get("currency"))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), errorStatus(err))
 		return
 	}
 	
@@ -92,6 +215,7 @@ func (c *HotelController) GetHotelByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
+	setETag(w, hotel.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(hotel)
 }
@@ -122,9 +246,9 @@ func (c *HotelController) GetHotelRooms(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
-	rooms, err := c.hotelUsecase.GetRoomsByHotelID(hotelID, fromDate, toDate)
+	rooms, err := c.hotelUsecase.GetRoomsByHotelID(hotelID, fromDate, toDate, r.URL.Query().Get("currency"))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), errorStatus(err))
 		return
 	}
 
@@ -132,6 +256,40 @@ func (c *HotelController) GetHotelRooms(w http.ResponseWriter, r *http.Request)
 	json.NewEncoder(w).Encode(rooms)
 }
 
+func (c *HotelController) QuoteRoom(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	roomID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid room ID", http.StatusBadRequest)
+		return
+	}
+
+	fromDate, err := time.Parse("2006-01-02", r.URL.Query().Get("from_date"))
+	if err != nil {
+		http.Error(w, "from_date is required in YYYY-MM-DD format", http.StatusBadRequest)
+		return
+	}
+
+	toDate, err := time.Parse("2006-01-02", r.URL.Query().Get("to_date"))
+	if err != nil {
+		http.Error(w, "to_date is required in YYYY-MM-DD format", http.StatusBadRequest)
+		return
+	}
+
+	quote, err := c.hotelUsecase.QuoteRoom(roomID, fromDate, toDate, r.URL.Query().Get("currency"))
+	if err != nil {
+		if err.Error() == "room not found" {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(quote)
+}
+
 func (c *HotelController) CreateHotel(w http.ResponseWriter, r *http.Request) {
 	var hotel data.Hotel
 	if err := json.NewDecoder(r.Body).Decode(&hotel); err != nil {
@@ -141,7 +299,7 @@ func (c *HotelController) CreateHotel(w http.ResponseWriter, r *http.Request) {
 
 	createdHotel, err := c.hotelUsecase.CreateHotel(hotel)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), errorStatus(err))
 		return
 	}
 
@@ -157,19 +315,27 @@ func (c *HotelController) UpdateHotel(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
 	var hotel data.Hotel
 	if err := json.NewDecoder(r.Body).Decode(&hotel); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 	hotel.ID = hotelID
+	hotel.Version = version
 
 	updatedHotel, err := c.hotelUsecase.UpdateHotel(hotel)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), errorStatus(err))
 		return
 	}
 
+	setETag(w, updatedHotel.Version)
 	json.NewEncoder(w).Encode(updatedHotel)
 }
 
@@ -181,9 +347,15 @@ func (c *HotelController) DeleteHotel(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = c.hotelUsecase.DeleteHotel(hotelID)
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	err = c.hotelUsecase.DeleteHotel(hotelID, version)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), errorStatus(err))
 		return
 	}
 
@@ -199,7 +371,7 @@ func (c *HotelController) CreateRoom(w http.ResponseWriter, r *http.Request) {
 
 	createdRoom, err := c.hotelUsecase.CreateRoom(room)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), errorStatus(err))
 		return
 	}
 
@@ -207,6 +379,25 @@ func (c *HotelController) CreateRoom(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(createdRoom)
 }
 
+func (c *HotelController) GetRoom(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	roomID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid room ID", http.StatusBadRequest)
+		return
+	}
+
+	room, err := c.hotelUsecase.GetRoom(roomID)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	setETag(w, room.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(room)
+}
+
 func (c *HotelController) UpdateRoom(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	roomID, err := strconv.Atoi(vars["id"])
@@ -215,19 +406,27 @@ func (c *HotelController) UpdateRoom(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
 	var room data.Room
 	if err := json.NewDecoder(r.Body).Decode(&room); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 	room.ID = roomID
+	room.Version = version
 
 	updatedRoom, err := c.hotelUsecase.UpdateRoom(room)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), errorStatus(err))
 		return
 	}
 
+	setETag(w, updatedRoom.Version)
 	json.NewEncoder(w).Encode(updatedRoom)
 }
 
@@ -239,11 +438,182 @@ func (c *HotelController) DeleteRoom(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = c.hotelUsecase.DeleteRoom(roomID)
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	err = c.hotelUsecase.DeleteRoom(roomID, version)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c *HotelController) GetHotelRoomTypes(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	hotelID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid hotel ID", http.StatusBadRequest)
+		return
+	}
+
+	fromDate := time.Now()
+	toDate := fromDate.AddDate(0, 0, 1)
+
+	if fromDateStr := r.URL.Query().Get("from_date"); fromDateStr != "" {
+		if parsed, err := time.Parse("2006-01-02", fromDateStr); err == nil {
+			fromDate = parsed
+		}
+	}
+
+	if toDateStr := r.URL.Query().Get("to_date"); toDateStr != "" {
+		if parsed, err := time.Parse("2006-01-02", toDateStr); err == nil {
+			toDate = parsed
+		}
+	}
+
+	roomTypes, err := c.hotelUsecase.GetRoomTypes(hotelID, fromDate, toDate, r.URL.Query().Get("currency"))
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(roomTypes)
+}
+
+func (c *HotelController) QuoteRoomType(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	roomTypeID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid room type ID", http.StatusBadRequest)
+		return
+	}
+
+	fromDate, err := time.Parse("2006-01-02", r.URL.Query().Get("from_date"))
+	if err != nil {
+		http.Error(w, "from_date is required in YYYY-MM-DD format", http.StatusBadRequest)
+		return
+	}
+
+	toDate, err := time.Parse("2006-01-02", r.URL.Query().Get("to_date"))
+	if err != nil {
+		http.Error(w, "to_date is required in YYYY-MM-DD format", http.StatusBadRequest)
+		return
+	}
+
+	quote, err := c.hotelUsecase.QuoteRoomType(roomTypeID, fromDate, toDate, r.URL.Query().Get("currency"))
+	if err != nil {
+		status := errorStatus(err)
+		if status == http.StatusInternalServerError {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(quote)
+}
+
+func (c *HotelController) CreateRoomType(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	hotelID, err := strconv.Atoi(vars["hotelID"])
+	if err != nil {
+		http.Error(w, "Invalid hotel ID", http.StatusBadRequest)
+		return
+	}
+
+	var roomType data.RoomType
+	if err := json.NewDecoder(r.Body).Decode(&roomType); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	roomType.HotelID = hotelID
+
+	created, err := c.hotelUsecase.CreateRoomType(roomType)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+func (c *HotelController) GetRoomType(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	roomTypeID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid room type ID", http.StatusBadRequest)
+		return
+	}
+
+	roomType, err := c.hotelUsecase.GetRoomType(roomTypeID)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	setETag(w, roomType.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(roomType)
+}
+
+func (c *HotelController) UpdateRoomType(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	roomTypeID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid room type ID", http.StatusBadRequest)
+		return
+	}
+
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	var roomType data.RoomType
+	if err := json.NewDecoder(r.Body).Decode(&roomType); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	roomType.ID = roomTypeID
+	roomType.Version = version
+
+	updated, err := c.hotelUsecase.UpdateRoomType(roomType)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	setETag(w, updated.Version)
+	json.NewEncoder(w).Encode(updated)
+}
+
+func (c *HotelController) DeleteRoomType(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	roomTypeID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid room type ID", http.StatusBadRequest)
+		return
+	}
+
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	if err := c.hotelUsecase.DeleteRoomType(roomTypeID, version); err != nil {
+		http.Error(w, err.Error(), errorStatus(err))
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
-}
\ No newline at end of file
+}
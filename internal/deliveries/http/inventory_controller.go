@@ -0,0 +1,281 @@
+package deliveries
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"hotel-booking-service/internal/data"
+	"hotel-booking-service/internal/usecases"
+)
+
+// InventoryController serves the revenue and front-desk tools for selling
+// beyond physical capacity and cleaning up afterwards.
+type InventoryController struct {
+	inventoryUsecase *usecases.InventoryUsecase
+	bookingUsecase   *usecases.BookingUsecase
+}
+
+func NewInventoryController(inventoryUsecase *usecases.InventoryUsecase, bookingUsecase *usecases.BookingUsecase) *InventoryController {
+	return &InventoryController{
+		inventoryUsecase: inventoryUsecase,
+		bookingUsecase:   bookingUsecase,
+	}
+}
+
+func (c *InventoryController) GetAllowances(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := strconv.Atoi(mux.Vars(r)["hotelID"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid hotel ID", http.StatusBadRequest)
+		return
+	}
+
+	allowances, err := c.inventoryUsecase.GetAllowances(hotelID)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(allowances)
+}
+
+func (c *InventoryController) CreateAllowance(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := strconv.Atoi(mux.Vars(r)["hotelID"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid hotel ID", http.StatusBadRequest)
+		return
+	}
+
+	var allowance data.OverbookingAllowance
+	if err := json.NewDecoder(r.Body).Decode(&allowance); err != nil {
+		sendErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	allowance.HotelID = hotelID
+
+	created, err := c.inventoryUsecase.CreateAllowance(allowance)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+func (c *InventoryController) DeleteAllowance(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid allowance ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.inventoryUsecase.DeleteAllowance(id); err != nil {
+		sendErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c *InventoryController) OversoldReport(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := strconv.Atoi(mux.Vars(r)["hotelID"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid hotel ID", http.StatusBadRequest)
+		return
+	}
+
+	fromDate, toDate, ok := reportRange(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := c.inventoryUsecase.OversoldReport(hotelID, fromDate, toDate)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+func (c *InventoryController) WalkGuest(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := r.Context().Value(userIDContextKey).(int)
+	if !ok {
+		sendErrorResponse(w, "User ID not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	bookingID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid booking ID", http.StatusBadRequest)
+		return
+	}
+
+	var walk data.BookingWalk
+	if err := json.NewDecoder(r.Body).Decode(&walk); err != nil {
+		sendErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	walk.BookingID = bookingID
+	walk.WalkedBy = adminID
+
+	walked, err := c.bookingUsecase.WalkGuest(walk)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(walked)
+}
+
+func (c *InventoryController) ReassignRoom(w http.ResponseWriter, r *http.Request) {
+	bookingID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid booking ID", http.StatusBadRequest)
+		return
+	}
+
+	var req data.ReassignRoomRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	booking, err := c.bookingUsecase.ReassignRoom(bookingID, req.RoomID)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(booking)
+}
+
+func (c *InventoryController) GetHotelBlocks(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := strconv.Atoi(mux.Vars(r)["hotelID"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid hotel ID", http.StatusBadRequest)
+		return
+	}
+
+	fromDate, toDate, ok := reportRange(w, r)
+	if !ok {
+		return
+	}
+
+	blocks, err := c.inventoryUsecase.GetHotelBlocks(hotelID, fromDate, toDate)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(blocks)
+}
+
+func (c *InventoryController) CreateBlock(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := r.Context().Value(userIDContextKey).(int)
+	if !ok {
+		sendErrorResponse(w, "User ID not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	roomID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid room ID", http.StatusBadRequest)
+		return
+	}
+
+	var block data.RoomBlock
+	if err := json.NewDecoder(r.Body).Decode(&block); err != nil {
+		sendErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	block.RoomID = roomID
+	block.CreatedBy = adminID
+
+	created, err := c.inventoryUsecase.CreateBlock(block)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+func (c *InventoryController) DeleteBlock(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid block ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.inventoryUsecase.DeleteBlock(id); err != nil {
+		sendErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c *InventoryController) HotelCalendar(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := strconv.Atoi(mux.Vars(r)["hotelID"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid hotel ID", http.StatusBadRequest)
+		return
+	}
+
+	fromDate, toDate, ok := reportRange(w, r)
+	if !ok {
+		return
+	}
+
+	calendar, err := c.inventoryUsecase.HotelCalendar(hotelID, fromDate, toDate)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(calendar)
+}
+
+// reportRange reads from_date and to_date (YYYY-MM-DD), defaulting to the
+// next seven nights.
+func reportRange(w http.ResponseWriter, r *http.Request) (time.Time, time.Time, bool) {
+	now := time.Now().UTC()
+	fromDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	toDate := fromDate.AddDate(0, 0, 7)
+
+	if value := r.URL.Query().Get("from_date"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			sendErrorResponse(w, "Invalid from_date, expected YYYY-MM-DD", http.StatusBadRequest)
+			return fromDate, toDate, false
+		}
+		fromDate = parsed
+		toDate = fromDate.AddDate(0, 0, 7)
+	}
+
+	if value := r.URL.Query().Get("to_date"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			sendErrorResponse(w, "Invalid to_date, expected YYYY-MM-DD", http.StatusBadRequest)
+			return fromDate, toDate, false
+		}
+		toDate = parsed
+	}
+
+	return fromDate, toDate, true
+}
@@ -0,0 +1,80 @@
+package deliveries
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"hotel-booking-service/internal/data"
+	"hotel-booking-service/internal/usecases"
+)
+
+type InvoiceController struct {
+	invoiceUsecase *usecases.InvoiceUsecase
+}
+
+func NewInvoiceController(invoiceUsecase *usecases.InvoiceUsecase) *InvoiceController {
+	return &InvoiceController{
+		invoiceUsecase: invoiceUsecase,
+	}
+}
+
+// GetBookingInvoice serves JSON by default, and PDF for ?format=pdf or an
+// Accept header asking for application/pdf.
+func (c *InvoiceController) GetBookingInvoice(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(userIDContextKey).(int)
+	if !ok {
+		sendErrorResponse(w, "User ID not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	bookingID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid booking ID", http.StatusBadRequest)
+		return
+	}
+
+	invoices, err := c.invoiceUsecase.GetBookingInvoices(userID, bookingID)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	if r.URL.Query().Get("format") == "pdf" || strings.Contains(r.Header.Get("Accept"), "application/pdf") {
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", invoices.Invoice.Number+".pdf"))
+		w.Write(c.invoiceUsecase.RenderPDF(invoices))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(invoices)
+}
+
+func (c *InvoiceController) CreateCreditNote(w http.ResponseWriter, r *http.Request) {
+	invoiceID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid invoice ID", http.StatusBadRequest)
+		return
+	}
+
+	var req data.CreateCreditNoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	note, err := c.invoiceUsecase.IssueCreditNote(invoiceID, req.Amount, req.Reason)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(note)
+}
@@ -0,0 +1,37 @@
+package deliveries
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"hotel-booking-service/internal/usecases"
+)
+
+type LoyaltyController struct {
+	loyaltyUsecase *usecases.LoyaltyUsecase
+}
+
+func NewLoyaltyController(loyaltyUsecase *usecases.LoyaltyUsecase) *LoyaltyController {
+	return &LoyaltyController{
+		loyaltyUsecase: loyaltyUsecase,
+	}
+}
+
+// GetMyAccount shows the logged-in member's points balance, tier and
+// history.
+func (c *LoyaltyController) GetMyAccount(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(userIDContextKey).(int)
+	if !ok {
+		sendErrorResponse(w, "User ID not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	account, err := c.loyaltyUsecase.GetAccount(userID)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(account)
+}
@@ -0,0 +1,245 @@
+package deliveries
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"hotel-booking-service/internal/data"
+	"hotel-booking-service/internal/usecases"
+)
+
+type MessageController struct {
+	messageUsecase *usecases.MessageUsecase
+}
+
+func NewMessageController(messageUsecase *usecases.MessageUsecase) *MessageController {
+	return &MessageController{
+		messageUsecase: messageUsecase,
+	}
+}
+
+// GetThread returns one of the user's booking threads, or a guest's through
+// their booking link.
+func (c *MessageController) GetThread(w http.ResponseWriter, r *http.Request) {
+	userID, bookingID, ok := messageRequest(w, r)
+	if !ok {
+		return
+	}
+
+	thread, err := c.messageUsecase.GuestThread(userID, bookingID)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(thread)
+}
+
+// PostMessage takes a JSON body {"body": "..."}, or a multipart form with a
+// "body" field and files under "attachments".
+func (c *MessageController) PostMessage(w http.ResponseWriter, r *http.Request) {
+	userID, bookingID, ok := messageRequest(w, r)
+	if !ok {
+		return
+	}
+
+	body, uploads, closeUploads, ok := c.readMessage(w, r)
+	if !ok {
+		return
+	}
+	defer closeUploads()
+
+	message, err := c.messageUsecase.PostAsGuest(r.Context(), userID, bookingID, body, uploads)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(message)
+}
+
+func (c *MessageController) GetAttachment(w http.ResponseWriter, r *http.Request) {
+	userID, bookingID, ok := messageRequest(w, r)
+	if !ok {
+		return
+	}
+
+	attachmentID, err := strconv.Atoi(mux.Vars(r)["attachmentID"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid attachment ID", http.StatusBadRequest)
+		return
+	}
+
+	attachment, file, err := c.messageUsecase.GuestAttachment(userID, bookingID, attachmentID)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+	defer file.Close()
+
+	serveAttachment(w, attachment, file)
+}
+
+func (c *MessageController) GetHotelThread(w http.ResponseWriter, r *http.Request) {
+	staffID, bookingID, ok := messageRequest(w, r)
+	if !ok {
+		return
+	}
+
+	thread, err := c.messageUsecase.HotelThread(staffID, bookingID)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(thread)
+}
+
+// PostHotelMessage replies to the guest on behalf of the hotel. It takes the
+// same bodies as PostMessage.
+func (c *MessageController) PostHotelMessage(w http.ResponseWriter, r *http.Request) {
+	staffID, bookingID, ok := messageRequest(w, r)
+	if !ok {
+		return
+	}
+
+	body, uploads, closeUploads, ok := c.readMessage(w, r)
+	if !ok {
+		return
+	}
+	defer closeUploads()
+
+	message, err := c.messageUsecase.PostAsHotel(r.Context(), staffID, bookingID, body, uploads)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(message)
+}
+
+func (c *MessageController) GetHotelAttachment(w http.ResponseWriter, r *http.Request) {
+	staffID, bookingID, ok := messageRequest(w, r)
+	if !ok {
+		return
+	}
+
+	attachmentID, err := strconv.Atoi(mux.Vars(r)["attachmentID"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid attachment ID", http.StatusBadRequest)
+		return
+	}
+
+	attachment, file, err := c.messageUsecase.HotelAttachment(staffID, bookingID, attachmentID)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+	defer file.Close()
+
+	serveAttachment(w, attachment, file)
+}
+
+// Inbox lists the hotel's bookings with unread guest messages.
+func (c *MessageController) Inbox(w http.ResponseWriter, r *http.Request) {
+	staffID, ok := r.Context().Value(userIDContextKey).(int)
+	if !ok {
+		sendErrorResponse(w, "User ID not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	hotelID, err := strconv.Atoi(mux.Vars(r)["hotelID"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid hotel ID", http.StatusBadRequest)
+		return
+	}
+
+	threads, err := c.messageUsecase.Inbox(staffID, hotelID)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(threads)
+}
+
+func messageRequest(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	userID, ok := r.Context().Value(userIDContextKey).(int)
+	if !ok {
+		sendErrorResponse(w, "User ID not found in context", http.StatusUnauthorized)
+		return 0, 0, false
+	}
+
+	bookingID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid booking ID", http.StatusBadRequest)
+		return 0, 0, false
+	}
+
+	return userID, bookingID, true
+}
+
+// readMessage reads a message's body and attachments from JSON or a
+// multipart form. The returned func closes the attachments' files.
+func (c *MessageController) readMessage(w http.ResponseWriter, r *http.Request) (string, []data.MessageUpload, func(), bool) {
+	maxUpload := c.messageUsecase.MaxUploadBytes()
+	r.Body = http.MaxBytesReader(w, r.Body, maxUpload)
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		var req data.PostMessageRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			sendErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+			return "", nil, nil, false
+		}
+		return req.Body, nil, func() {}, true
+	}
+
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		sendErrorResponse(w, fmt.Sprintf("Invalid form, or larger than %d MB", maxUpload>>20), http.StatusBadRequest)
+		return "", nil, nil, false
+	}
+
+	var uploads []data.MessageUpload
+	var files []io.Closer
+	closeAll := func() {
+		for _, file := range files {
+			file.Close()
+		}
+		r.MultipartForm.RemoveAll()
+	}
+
+	for _, header := range r.MultipartForm.File["attachments"] {
+		file, err := header.Open()
+		if err != nil {
+			closeAll()
+			sendErrorResponse(w, "Invalid attachment", http.StatusBadRequest)
+			return "", nil, nil, false
+		}
+		files = append(files, file)
+		uploads = append(uploads, data.MessageUpload{Filename: header.Filename, Content: file})
+	}
+
+	return r.FormValue("body"), uploads, closeAll, true
+}
+
+func serveAttachment(w http.ResponseWriter, attachment *data.MessageAttachment, file io.Reader) {
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(attachment.Size, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	io.Copy(w, file)
+}
@@ -0,0 +1,162 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"hotel-booking-service/internal/repositories"
+)
+
+const (
+	idempotencyKeyHeader = "Idempotency-Key"
+	maxIdempotencyKeyLen = 255
+)
+
+// idempotencyLease is how long a request may hold its key before a retry
+// can take the key over, for requests whose server stopped before they
+// finished. It is well beyond how long any request takes.
+const idempotencyLease = time.Minute
+
+// Idempotency makes POST, PUT, PATCH and DELETE requests sent with an
+// Idempotency-Key header safe to retry. The first response for a key is
+// stored and replayed for repeats; reusing a key for a different request
+// answers 422. Keys belong to the user in the context, so it must be mounted
+// after the authentication middleware; requests without a user are scoped
+// by the guest email in their body, see publicScope. Server errors are not
+// stored, so those requests can be retried with the same key, and neither
+// is a request that panics.
+func Idempotency(repo *repositories.IdempotencyRepository) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(idempotencyKeyHeader)
+			if key == "" || r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if len(key) > maxIdempotencyKeyLen {
+				sendErrorResponse(w, "Idempotency-Key must be at most 255 characters", http.StatusBadRequest)
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				sendErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			scope := publicScope(body)
+			if userID, ok := r.Context().Value(userIDContextKey).(int); ok {
+				scope = "user:" + strconv.Itoa(userID)
+			}
+
+			fingerprint := requestFingerprint(r, body)
+			record, reserved, err := repo.Reserve(scope, key, fingerprint, idempotencyLease)
+			if err != nil {
+				log.Printf("Failed to reserve idempotency key: %v", err)
+				sendErrorResponse(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+
+			switch {
+			case record == nil || (!reserved && record.StatusCode == 0):
+				w.Header().Set("Retry-After", "1")
+				sendErrorResponse(w, "A request with this Idempotency-Key is still in progress", http.StatusConflict)
+				return
+			case !reserved && record.Fingerprint != fingerprint:
+				sendErrorResponse(w, "Idempotency-Key was already used for a different request", http.StatusUnprocessableEntity)
+				return
+			case !reserved:
+				if record.ContentType != "" {
+					w.Header().Set("Content-Type", record.ContentType)
+				}
+				w.Header().Set("Idempotent-Replayed", "true")
+				w.WriteHeader(record.StatusCode)
+				w.Write(record.ResponseBody)
+				return
+			}
+
+			defer func() {
+				if p := recover(); p != nil {
+					if err := repo.Release(record.ID); err != nil {
+						log.Printf("Failed to release idempotency key %d: %v", record.ID, err)
+					}
+					panic(p)
+				}
+			}()
+
+			recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(recorder, r)
+
+			if recorder.status >= http.StatusInternalServerError {
+				err = repo.Release(record.ID)
+			} else {
+				err = repo.Complete(record.ID, recorder.status, w.Header().Get("Content-Type"), recorder.body.Bytes())
+			}
+			if err != nil {
+				log.Printf("Failed to store response for idempotency key %d: %v", record.ID, err)
+			}
+		})
+	}
+}
+
+// publicScope keys a request without a user by a hash of the guest email in
+// its body, so that guests who happen to pick the same key do not collide.
+// Requests without one share a single scope.
+func publicScope(body []byte) string {
+	var req struct {
+		Guest struct {
+			Email string `json:"email"`
+		} `json:"guest"`
+	}
+	json.Unmarshal(body, &req)
+
+	email := strings.ToLower(strings.TrimSpace(req.Guest.Email))
+	if email == "" {
+		return "public"
+	}
+
+	// Half the hash is plenty, and keeps the scope within its column.
+	hash := sha256.Sum256([]byte(email))
+	return "guest:" + hex.EncodeToString(hash[:16])
+}
+
+// requestFingerprint identifies a request by its method, path and body.
+func requestFingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	io.WriteString(hash, r.Method+" "+r.URL.Path+"\n")
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder passes a response through while keeping a copy of its
+// status and body.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
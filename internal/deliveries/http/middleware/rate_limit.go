@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimit lets each client address make at most limit requests per
+// window and answers the rest with 429. Counts are kept in memory, so with
+// several instances each enforces its own limit.
+func RateLimit(limit int, window time.Duration) func(http.Handler) http.Handler {
+	var (
+		mu      sync.Mutex
+		started = time.Now()
+		counts  = map[string]int{}
+	)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			client, _, err := net.SplitHostPort(r.RemoteAddr)
+			if err != nil {
+				client = r.RemoteAddr
+			}
+
+			mu.Lock()
+			now := time.Now()
+			if now.Sub(started) >= window {
+				started = now
+				counts = map[string]int{}
+			}
+			counts[client]++
+			allowed := counts[client] <= limit
+			retryAfter := started.Add(window).Sub(now)
+			mu.Unlock()
+
+			if !allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+				sendErrorResponse(w, "Too many requests, try again later", http.StatusTooManyRequests)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
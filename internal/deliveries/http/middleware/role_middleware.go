@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http"
+
+	"hotel-booking-service/internal/repositories"
+)
+
+// RequireRole only lets through users whose role is one of roles. It must be
+// mounted after AuthMiddleware so the user ID is already in the context.
+func RequireRole(userRepo *repositories.UserRepository, roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := r.Context().Value(userIDContextKey).(int)
+			if !ok {
+				sendErrorResponse(w, "User ID not found in context", http.StatusUnauthorized)
+				return
+			}
+
+			user, err := userRepo.GetByID(userID)
+			if err != nil {
+				sendErrorResponse(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+
+			if user == nil {
+				sendErrorResponse(w, "User not found", http.StatusUnauthorized)
+				return
+			}
+
+			for _, role := range roles {
+				if user.Role == role {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			sendErrorResponse(w, "Insufficient permissions", http.StatusForbidden)
+		})
+	}
+}
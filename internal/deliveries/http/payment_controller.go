@@ -0,0 +1,155 @@
+package deliveries
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"hotel-booking-service/internal/data"
+	"hotel-booking-service/internal/payments"
+	"hotel-booking-service/internal/usecases"
+)
+
+// maxWebhookBody caps webhook payloads; provider events are a few hundred
+// bytes.
+const maxWebhookBody = 64 << 10
+
+type PaymentController struct {
+	paymentUsecase *usecases.PaymentUsecase
+}
+
+func NewPaymentController(paymentUsecase *usecases.PaymentUsecase) *PaymentController {
+	return &PaymentController{
+		paymentUsecase: paymentUsecase,
+	}
+}
+
+func (c *PaymentController) CreatePayment(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(userIDContextKey).(int)
+	if !ok {
+		sendErrorResponse(w, "User ID not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	bookingID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid booking ID", http.StatusBadRequest)
+		return
+	}
+
+	payment, err := c.paymentUsecase.CreatePayment(r.Context(), userID, bookingID)
+	if err != nil {
+		log.Printf("Error creating payment for booking %d: %v", bookingID, err)
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(payment)
+}
+
+func (c *PaymentController) GetBookingPayments(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(userIDContextKey).(int)
+	if !ok {
+		sendErrorResponse(w, "User ID not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	bookingID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid booking ID", http.StatusBadRequest)
+		return
+	}
+
+	list, err := c.paymentUsecase.GetBookingPayments(userID, bookingID)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+func (c *PaymentController) Capture(w http.ResponseWriter, r *http.Request) {
+	paymentID, req, ok := paymentActionRequest(w, r)
+	if !ok {
+		return
+	}
+
+	payment, err := c.paymentUsecase.Capture(r.Context(), paymentID, req.Amount)
+	sendPaymentResult(w, payment, err)
+}
+
+func (c *PaymentController) Refund(w http.ResponseWriter, r *http.Request) {
+	paymentID, req, ok := paymentActionRequest(w, r)
+	if !ok {
+		return
+	}
+
+	payment, err := c.paymentUsecase.Refund(r.Context(), paymentID, req.Amount)
+	sendPaymentResult(w, payment, err)
+}
+
+func (c *PaymentController) Void(w http.ResponseWriter, r *http.Request) {
+	paymentID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid payment ID", http.StatusBadRequest)
+		return
+	}
+
+	payment, err := c.paymentUsecase.Void(r.Context(), paymentID)
+	sendPaymentResult(w, payment, err)
+}
+
+// Webhook receives provider notifications. It is unauthenticated; the
+// payload signature is what proves the provider sent it.
+func (c *PaymentController) Webhook(w http.ResponseWriter, r *http.Request) {
+	payload, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBody))
+	if err != nil {
+		sendErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.paymentUsecase.HandleWebhook(payload, r.Header.Get(payments.SignatureHeader)); err != nil {
+		log.Printf("Rejected payment webhook: %v", err)
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// paymentActionRequest reads the payment ID and the optional amount shared by
+// the capture and refund endpoints. An empty body means the full amount.
+func paymentActionRequest(w http.ResponseWriter, r *http.Request) (int, data.PaymentAmountRequest, bool) {
+	var req data.PaymentAmountRequest
+
+	paymentID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid payment ID", http.StatusBadRequest)
+		return 0, req, false
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		sendErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return 0, req, false
+	}
+
+	return paymentID, req, true
+}
+
+func sendPaymentResult(w http.ResponseWriter, payment *data.Payment, err error) {
+	if err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(payment)
+}
@@ -0,0 +1,107 @@
+package deliveries
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"hotel-booking-service/internal/data"
+	"hotel-booking-service/internal/usecases"
+)
+
+type RestrictionController struct {
+	restrictionUsecase *usecases.RestrictionUsecase
+}
+
+func NewRestrictionController(restrictionUsecase *usecases.RestrictionUsecase) *RestrictionController {
+	return &RestrictionController{
+		restrictionUsecase: restrictionUsecase,
+	}
+}
+
+func (c *RestrictionController) GetHotelRestrictions(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	hotelID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid hotel ID", http.StatusBadRequest)
+		return
+	}
+
+	restrictions, err := c.restrictionUsecase.GetHotelRestrictions(hotelID)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(restrictions)
+}
+
+func (c *RestrictionController) CreateRestriction(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	hotelID, err := strconv.Atoi(vars["hotelID"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid hotel ID", http.StatusBadRequest)
+		return
+	}
+
+	var restriction data.StayRestriction
+	if err := json.NewDecoder(r.Body).Decode(&restriction); err != nil {
+		sendErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	restriction.HotelID = hotelID
+
+	created, err := c.restrictionUsecase.CreateRestriction(restriction)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+func (c *RestrictionController) UpdateRestriction(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	restrictionID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid restriction ID", http.StatusBadRequest)
+		return
+	}
+
+	var restriction data.StayRestriction
+	if err := json.NewDecoder(r.Body).Decode(&restriction); err != nil {
+		sendErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	restriction.ID = restrictionID
+
+	updated, err := c.restrictionUsecase.UpdateRestriction(restriction)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
+
+func (c *RestrictionController) DeleteRestriction(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	restrictionID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid restriction ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.restrictionUsecase.DeleteRestriction(restrictionID); err != nil {
+		sendErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
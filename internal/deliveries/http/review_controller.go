@@ -0,0 +1,142 @@
+package deliveries
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"hotel-booking-service/internal/data"
+	"hotel-booking-service/internal/usecases"
+)
+
+type ReviewController struct {
+	reviewUsecase *usecases.ReviewUsecase
+}
+
+func NewReviewController(reviewUsecase *usecases.ReviewUsecase) *ReviewController {
+	return &ReviewController{
+		reviewUsecase: reviewUsecase,
+	}
+}
+
+// CreateReview lets the logged-in guest review a booking they checked out of.
+func (c *ReviewController) CreateReview(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(userIDContextKey).(int)
+	if !ok {
+		sendErrorResponse(w, "User ID not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	bookingID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid booking ID", http.StatusBadRequest)
+		return
+	}
+
+	var req data.CreateReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	review, err := c.reviewUsecase.CreateReview(userID, bookingID, req)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(review)
+}
+
+func (c *ReviewController) GetHotelReviews(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid hotel ID", http.StatusBadRequest)
+		return
+	}
+
+	reviews, err := c.reviewUsecase.GetHotelReviews(hotelID)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reviews)
+}
+
+// ListReviews lists reviews for moderation, filtered by ?status=.
+func (c *ReviewController) ListReviews(w http.ResponseWriter, r *http.Request) {
+	reviews, err := c.reviewUsecase.ListReviews(r.URL.Query().Get("status"))
+	if err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reviews)
+}
+
+// Reply posts the hotel's answer to a review.
+func (c *ReviewController) Reply(w http.ResponseWriter, r *http.Request) {
+	staffID, ok := r.Context().Value(userIDContextKey).(int)
+	if !ok {
+		sendErrorResponse(w, "User ID not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	reviewID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid review ID", http.StatusBadRequest)
+		return
+	}
+
+	var req data.ReviewReplyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	review, err := c.reviewUsecase.Reply(staffID, reviewID, req.Reply)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(review)
+}
+
+// Moderate publishes or hides a review.
+func (c *ReviewController) Moderate(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := r.Context().Value(userIDContextKey).(int)
+	if !ok {
+		sendErrorResponse(w, "User ID not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	reviewID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		sendErrorResponse(w, "Invalid review ID", http.StatusBadRequest)
+		return
+	}
+
+	var req data.ModerateReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	review, err := c.reviewUsecase.Moderate(adminID, reviewID, req)
+	if err != nil {
+		sendErrorResponse(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(review)
+}
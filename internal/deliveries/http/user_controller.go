@@ -38,6 +38,7 @@ func (c *UserController) GetUserByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	setETag(w, user.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(user)
 }
@@ -66,6 +67,7 @@ func (c *UserController) GetCurrentUser(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	setETag(w, user.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(user)
 }
@@ -110,6 +112,12 @@ func (c *UserController) UpdateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
 	var updatedUser data.User
 	if err := json.NewDecoder(r.Body).Decode(&updatedUser); err != nil {
 		http.Error(w, "Invalid input", http.StatusBadRequest)
@@ -117,9 +125,10 @@ func (c *UserController) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	updatedUser.ID = id
+	updatedUser.Version = version
 	err = c.userUsecase.UpdateUser(&updatedUser)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), errorStatus(err))
 		return
 	}
 
@@ -134,9 +143,15 @@ func (c *UserController) DeleteUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = c.userUsecase.DeleteUser(id)
+	version, err := ifMatchVersion(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	err = c.userUsecase.DeleteUser(id, version)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err))
 		return
 	}
 
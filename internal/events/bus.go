@@ -0,0 +1,63 @@
+// Package events is an in-process publish/subscribe bus. It lets one part of
+// the service react to changes in another, such as the waitlist offering a
+// room when a booking is cancelled, without the two knowing about each other.
+package events
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+const (
+	// BookingCancelled carries the cancelled data.Booking. It fires for guest
+	// cancellations and for unpaid bookings that expire.
+	BookingCancelled = "booking.cancelled"
+	// BookingNoShow carries a data.Booking released because the guest did
+	// not arrive by the hotel's no-show cutoff.
+	BookingNoShow = "booking.no_show"
+	// BookingCheckedOut carries the data.Booking of a guest who has left,
+	// with the total they paid.
+	BookingCheckedOut = "booking.checked_out"
+	// BookingMessagePosted carries a data.BookingMessage just added to a
+	// booking's thread by the guest or the hotel.
+	BookingMessagePosted = "booking.message_posted"
+)
+
+type Event struct {
+	Name    string
+	Payload interface{}
+}
+
+type Handler func(ctx context.Context, event Event) error
+
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+}
+
+func NewBus() *Bus {
+	return &Bus{handlers: map[string][]Handler{}}
+}
+
+func (b *Bus) Subscribe(name string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[name] = append(b.handlers[name], handler)
+}
+
+// Publish runs the event's handlers in subscription order before returning.
+// Handler errors are logged rather than returned: by the time an event is
+// published the change it describes has already been committed.
+func (b *Bus) Publish(ctx context.Context, name string, payload interface{}) {
+	b.mu.RLock()
+	handlers := b.handlers[name]
+	b.mu.RUnlock()
+
+	event := Event{Name: name, Payload: payload}
+	for _, handler := range handlers {
+		if err := handler(ctx, event); err != nil {
+			log.Printf("Handler for %s failed: %v", name, err)
+		}
+	}
+}
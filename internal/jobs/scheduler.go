@@ -0,0 +1,59 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+type job struct {
+	name     string
+	interval time.Duration
+	run      func(ctx context.Context) error
+}
+
+// Scheduler runs background jobs on fixed intervals. Each job runs in its own
+// goroutine, so a slow job never delays the others.
+type Scheduler struct {
+	jobs []job
+	wg   sync.WaitGroup
+}
+
+func NewScheduler() *Scheduler {
+	return &Scheduler{}
+}
+
+func (s *Scheduler) Every(name string, interval time.Duration, run func(ctx context.Context) error) {
+	s.jobs = append(s.jobs, job{name: name, interval: interval, run: run})
+}
+
+// Start launches every registered job. Jobs stop when ctx is cancelled; Wait
+// blocks until they have all returned.
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go func(j job) {
+			defer s.wg.Done()
+
+			ticker := time.NewTicker(j.interval)
+			defer ticker.Stop()
+
+			for {
+				if err := j.run(ctx); err != nil {
+					log.Printf("Job %s failed: %v", j.name, err)
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}(j)
+	}
+}
+
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
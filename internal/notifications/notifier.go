@@ -0,0 +1,72 @@
+// Package notifications delivers messages to guests. Production setups send
+// email over SMTP; without SMTP settings messages are written to the log so
+// local runs can follow along.
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/smtp"
+	"strings"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, msg Message) error
+}
+
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (n *LogNotifier) Notify(ctx context.Context, msg Message) error {
+	log.Printf("Notification to %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+type SMTPNotifier struct {
+	cfg SMTPConfig
+}
+
+func NewSMTPNotifier(cfg SMTPConfig) *SMTPNotifier {
+	return &SMTPNotifier{cfg: cfg}
+}
+
+func (n *SMTPNotifier) Notify(ctx context.Context, msg Message) error {
+	var auth smtp.Auth
+	if n.cfg.Username != "" {
+		auth = smtp.PlainAuth("", n.cfg.Username, n.cfg.Password, n.cfg.Host)
+	}
+
+	body := strings.Join([]string{
+		"From: " + n.cfg.From,
+		"To: " + msg.To,
+		"Subject: " + msg.Subject,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		msg.Body,
+	}, "\r\n")
+
+	addr := n.cfg.Host + ":" + n.cfg.Port
+	if err := smtp.SendMail(addr, auth, n.cfg.From, []string{msg.To}, []byte(body)); err != nil {
+		return fmt.Errorf("sending mail to %s: %w", msg.To, err)
+	}
+	return nil
+}
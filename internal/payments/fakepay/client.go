@@ -0,0 +1,106 @@
+package fakepay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"hotel-booking-service/internal/payments"
+	"hotel-booking-service/internal/pkg/money"
+)
+
+// Client implements payments.Provider against a fakepay server.
+type Client struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+func NewClient(baseURL, apiKey string) *Client {
+	return &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     apiKey,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// NewInProcessClient talks to an embedded server through its handler, so
+// the app can take payments with no gateway process running at all.
+func NewInProcessClient(server *Server) *Client {
+	return &Client{
+		baseURL:    server.baseURL,
+		apiKey:     server.apiKey,
+		httpClient: &http.Client{Transport: handlerTransport{handler: server.Handler()}},
+	}
+}
+
+type handlerTransport struct {
+	handler http.Handler
+}
+
+func (t handlerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorder := httptest.NewRecorder()
+	t.handler.ServeHTTP(recorder, req)
+	return recorder.Result(), nil
+}
+
+func (c *Client) Name() string {
+	return "fakepay"
+}
+
+func (c *Client) CreateIntent(ctx context.Context, amount money.Money, reference string) (*payments.Intent, error) {
+	return c.post(ctx, "/v1/intents", amountRequest{Amount: &amount, Reference: reference})
+}
+
+func (c *Client) Capture(ctx context.Context, intentID string, amount money.Money) (*payments.Intent, error) {
+	return c.post(ctx, "/v1/intents/"+intentID+"/capture", amountRequest{Amount: &amount})
+}
+
+func (c *Client) Refund(ctx context.Context, intentID string, amount money.Money) (*payments.Intent, error) {
+	return c.post(ctx, "/v1/intents/"+intentID+"/refund", amountRequest{Amount: &amount})
+}
+
+func (c *Client) Void(ctx context.Context, intentID string) (*payments.Intent, error) {
+	return c.post(ctx, "/v1/intents/"+intentID+"/void", amountRequest{})
+}
+
+func (c *Client) post(ctx context.Context, path string, body amountRequest) (*payments.Intent, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", payments.ErrProvider, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var failure struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(resp.Body).Decode(&failure)
+		return nil, fmt.Errorf("%w: %s", payments.ErrProvider, failure.Message)
+	}
+
+	var intent payments.Intent
+	if err := json.NewDecoder(resp.Body).Decode(&intent); err != nil {
+		return nil, fmt.Errorf("%w: %v", payments.ErrProvider, err)
+	}
+	return &intent, nil
+}
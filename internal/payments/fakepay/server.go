@@ -0,0 +1,285 @@
+// Package fakepay is a stand-in payment gateway for local runs. It keeps
+// intents in memory, lets a developer approve or decline them, and sends
+// signed webhooks the same way a real gateway would.
+package fakepay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"hotel-booking-service/internal/payments"
+	"hotel-booking-service/internal/pkg/money"
+)
+
+// Notifier delivers a signed webhook payload to the application.
+type Notifier func(payload []byte, signature string) error
+
+// HTTPNotifier posts webhooks to url, as the standalone server does.
+func HTTPNotifier(url string) Notifier {
+	client := &http.Client{Timeout: 10 * time.Second}
+	return func(payload []byte, signature string) error {
+		req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(payments.SignatureHeader, signature)
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode >= 300 {
+			return fmt.Errorf("webhook rejected with status %d", resp.StatusCode)
+		}
+		return nil
+	}
+}
+
+type Server struct {
+	mu      sync.Mutex
+	intents map[string]*payments.Intent
+	seq     int
+
+	baseURL       string
+	apiKey        string
+	webhookSecret string
+	notify        Notifier
+}
+
+func NewServer(baseURL, apiKey, webhookSecret string, notify Notifier) *Server {
+	return &Server{
+		intents:       map[string]*payments.Intent{},
+		baseURL:       strings.TrimRight(baseURL, "/"),
+		apiKey:        apiKey,
+		webhookSecret: webhookSecret,
+		notify:        notify,
+	}
+}
+
+// SetNotifier replaces the webhook target, which lets an embedded server be
+// wired to the application after both have been built.
+func (s *Server) SetNotifier(notify Notifier) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notify = notify
+}
+
+func (s *Server) Handler() http.Handler {
+	router := mux.NewRouter()
+	router.Use(s.authorize)
+
+	router.HandleFunc("/v1/intents", s.createIntent).Methods("POST")
+	router.HandleFunc("/v1/intents/{id}", s.getIntent).Methods("GET")
+	router.HandleFunc("/v1/intents/{id}/confirm", s.confirmIntent).Methods("POST")
+	router.HandleFunc("/v1/intents/{id}/capture", s.captureIntent).Methods("POST")
+	router.HandleFunc("/v1/intents/{id}/refund", s.refundIntent).Methods("POST")
+	router.HandleFunc("/v1/intents/{id}/void", s.voidIntent).Methods("POST")
+
+	return router
+}
+
+// authorize checks the API key on calls from the application. Confirming an
+// intent stands in for the guest's browser, so it needs no key.
+func (s *Server) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.apiKey != "" && !strings.HasSuffix(r.URL.Path, "/confirm") &&
+			r.Header.Get("Authorization") != "Bearer "+s.apiKey {
+			writeError(w, "invalid API key", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+type amountRequest struct {
+	Amount    *money.Money `json:"amount"`
+	Reference string       `json:"reference"`
+	Outcome   string       `json:"outcome"`
+}
+
+func (s *Server) createIntent(w http.ResponseWriter, r *http.Request) {
+	var req amountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Amount == nil || req.Amount.Amount <= 0 {
+		writeError(w, "a positive amount is required", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	s.seq++
+	id := fmt.Sprintf("fp_%06d", s.seq)
+	intent := &payments.Intent{
+		ID:         id,
+		Status:     payments.StatusRequiresConfirmation,
+		Amount:     *req.Amount,
+		Captured:   money.Zero(req.Amount.Currency),
+		Refunded:   money.Zero(req.Amount.Currency),
+		Reference:  req.Reference,
+		ConfirmURL: s.baseURL + "/v1/intents/" + id + "/confirm",
+	}
+	s.intents[id] = intent
+	snapshot := *intent
+	s.mu.Unlock()
+
+	writeJSON(w, snapshot, http.StatusCreated)
+}
+
+func (s *Server) getIntent(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	intent, ok := s.intents[mux.Vars(r)["id"]]
+	var snapshot payments.Intent
+	if ok {
+		snapshot = *intent
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, "intent not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, snapshot, http.StatusOK)
+}
+
+// confirmIntent simulates the guest completing (or failing) payment. Send
+// {"outcome": "fail"} to simulate a declined card.
+func (s *Server) confirmIntent(w http.ResponseWriter, r *http.Request) {
+	var req amountRequest
+	json.NewDecoder(r.Body).Decode(&req)
+
+	s.transition(w, r, func(intent *payments.Intent) (string, error) {
+		if intent.Status != payments.StatusRequiresConfirmation {
+			return "", fmt.Errorf("intent is %s", intent.Status)
+		}
+		if req.Outcome == "fail" {
+			intent.Status = payments.StatusFailed
+			return payments.EventFailed, nil
+		}
+		intent.Status = payments.StatusAuthorized
+		return payments.EventAuthorized, nil
+	})
+}
+
+func (s *Server) captureIntent(w http.ResponseWriter, r *http.Request) {
+	var req amountRequest
+	json.NewDecoder(r.Body).Decode(&req)
+
+	s.transition(w, r, func(intent *payments.Intent) (string, error) {
+		if intent.Status != payments.StatusAuthorized {
+			return "", fmt.Errorf("intent is %s", intent.Status)
+		}
+		amount := intent.Amount
+		if req.Amount != nil {
+			amount = *req.Amount
+		}
+		if amount.Currency != intent.Amount.Currency || amount.Amount <= 0 || amount.Amount > intent.Amount.Amount {
+			return "", fmt.Errorf("capture amount must be between 0 and %s", intent.Amount)
+		}
+		intent.Captured = amount
+		intent.Status = payments.StatusCaptured
+		return payments.EventCaptured, nil
+	})
+}
+
+func (s *Server) refundIntent(w http.ResponseWriter, r *http.Request) {
+	var req amountRequest
+	json.NewDecoder(r.Body).Decode(&req)
+
+	s.transition(w, r, func(intent *payments.Intent) (string, error) {
+		if intent.Status != payments.StatusCaptured && intent.Status != payments.StatusPartiallyRefunded {
+			return "", fmt.Errorf("intent is %s", intent.Status)
+		}
+		remaining := intent.Captured.Amount - intent.Refunded.Amount
+		amount := money.New(remaining, intent.Amount.Currency)
+		if req.Amount != nil {
+			amount = *req.Amount
+		}
+		if amount.Currency != intent.Amount.Currency || amount.Amount <= 0 || amount.Amount > remaining {
+			return "", fmt.Errorf("refund amount must be between 0 and %s", money.New(remaining, intent.Amount.Currency))
+		}
+		intent.Refunded = money.New(intent.Refunded.Amount+amount.Amount, intent.Amount.Currency)
+		if intent.Refunded.Amount == intent.Captured.Amount {
+			intent.Status = payments.StatusRefunded
+		} else {
+			intent.Status = payments.StatusPartiallyRefunded
+		}
+		return payments.EventRefunded, nil
+	})
+}
+
+func (s *Server) voidIntent(w http.ResponseWriter, r *http.Request) {
+	s.transition(w, r, func(intent *payments.Intent) (string, error) {
+		if intent.Status != payments.StatusRequiresConfirmation && intent.Status != payments.StatusAuthorized {
+			return "", fmt.Errorf("intent is %s", intent.Status)
+		}
+		intent.Status = payments.StatusVoided
+		return payments.EventVoided, nil
+	})
+}
+
+// transition applies change to an intent under the lock, then answers with
+// the new state and emits the resulting webhook.
+func (s *Server) transition(w http.ResponseWriter, r *http.Request, change func(*payments.Intent) (string, error)) {
+	s.mu.Lock()
+	intent, ok := s.intents[mux.Vars(r)["id"]]
+	if !ok {
+		s.mu.Unlock()
+		writeError(w, "intent not found", http.StatusNotFound)
+		return
+	}
+
+	eventType, err := change(intent)
+	snapshot := *intent
+	s.seq++
+	eventID := fmt.Sprintf("evt_%06d", s.seq)
+	notify := s.notify
+	s.mu.Unlock()
+
+	if err != nil {
+		writeError(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	writeJSON(w, snapshot, http.StatusOK)
+
+	if notify != nil {
+		go s.deliver(notify, payments.Event{
+			ID:        eventID,
+			Type:      eventType,
+			Intent:    snapshot,
+			CreatedAt: time.Now().UTC(),
+		})
+	}
+}
+
+func (s *Server) deliver(notify Notifier, event payments.Event) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("fakepay: encoding event %s: %v", event.ID, err)
+		return
+	}
+
+	signature := payments.Sign(s.webhookSecret, payload, time.Now())
+	if err := notify(payload, signature); err != nil {
+		log.Printf("fakepay: delivering %s for %s: %v", event.Type, event.Intent.ID, err)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, body interface{}, status int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, message string, status int) {
+	writeJSON(w, map[string]string{"message": message}, status)
+}
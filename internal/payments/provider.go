@@ -0,0 +1,58 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"hotel-booking-service/internal/pkg/money"
+)
+
+const (
+	StatusRequiresConfirmation = "requires_confirmation"
+	StatusAuthorized           = "authorized"
+	StatusCaptured             = "captured"
+	StatusPartiallyRefunded    = "partially_refunded"
+	StatusRefunded             = "refunded"
+	StatusVoided               = "voided"
+	StatusFailed               = "failed"
+)
+
+const (
+	EventAuthorized = "payment.authorized"
+	EventFailed     = "payment.failed"
+	EventCaptured   = "payment.captured"
+	EventRefunded   = "payment.refunded"
+	EventVoided     = "payment.voided"
+)
+
+var ErrProvider = errors.New("payment provider error")
+
+// Intent is the provider's view of a payment for one booking. Amounts are in
+// the hotel currency; the provider never converts.
+type Intent struct {
+	ID         string      `json:"id"`
+	Status     string      `json:"status"`
+	Amount     money.Money `json:"amount"`
+	Captured   money.Money `json:"captured"`
+	Refunded   money.Money `json:"refunded"`
+	Reference  string      `json:"reference"`
+	ConfirmURL string      `json:"confirm_url,omitempty"`
+}
+
+// Event is a webhook notification about a change to an intent.
+type Event struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Intent    Intent    `json:"intent"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Provider is implemented by each payment gateway integration.
+type Provider interface {
+	Name() string
+	CreateIntent(ctx context.Context, amount money.Money, reference string) (*Intent, error)
+	Capture(ctx context.Context, intentID string, amount money.Money) (*Intent, error)
+	Refund(ctx context.Context, intentID string, amount money.Money) (*Intent, error)
+	Void(ctx context.Context, intentID string) (*Intent, error)
+}
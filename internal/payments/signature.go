@@ -0,0 +1,66 @@
+package payments
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureHeader carries "t=<unix seconds>,v1=<hex hmac-sha256>", where the
+// MAC covers "<t>.<raw body>". Including the timestamp stops old deliveries
+// from being replayed.
+const SignatureHeader = "Payment-Signature"
+
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+func Sign(secret string, payload []byte, at time.Time) string {
+	ts := strconv.FormatInt(at.Unix(), 10)
+	return "t=" + ts + ",v1=" + computeMAC(secret, ts, payload)
+}
+
+func VerifySignature(secret string, payload []byte, header string, tolerance time.Duration, now time.Time) error {
+	var ts, sig string
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			ts = value
+		case "v1":
+			sig = value
+		}
+	}
+	if ts == "" || sig == "" {
+		return fmt.Errorf("%w: malformed header", ErrInvalidSignature)
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: bad timestamp", ErrInvalidSignature)
+	}
+	age := now.Sub(time.Unix(unix, 0))
+	if age > tolerance || age < -tolerance {
+		return fmt.Errorf("%w: timestamp outside tolerance", ErrInvalidSignature)
+	}
+
+	expected := computeMAC(secret, ts, payload)
+	if !hmac.Equal([]byte(expected), []byte(sig)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func computeMAC(secret, ts string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
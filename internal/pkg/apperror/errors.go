@@ -8,4 +8,9 @@ var (
 	ErrForbidden      = errors.New("forbidden")
 	ErrConflict       = errors.New("conflict")
 	ErrInvalidRequest = errors.New("invalid request")
+
+	// ErrPreconditionFailed means the client changed a resource that was
+	// changed by someone else since the client read it.
+	ErrPreconditionFailed   = errors.New("precondition failed")
+	ErrPreconditionRequired = errors.New("precondition required")
 )
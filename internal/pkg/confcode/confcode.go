@@ -0,0 +1,50 @@
+// Package confcode generates the confirmation codes guests quote for their
+// bookings, e.g. HBK-7F3Q9K.
+package confcode
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+)
+
+const (
+	Prefix = "HBK-"
+	Length = 6
+
+	// alphabet leaves out 0, 1, I, L and O, which are easily misread.
+	alphabet = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+)
+
+// New returns a random code. Codes are not unique by construction; the
+// caller must retry when one is already taken.
+func New() (string, error) {
+	var b strings.Builder
+	b.WriteString(Prefix)
+
+	max := big.NewInt(int64(len(alphabet)))
+	for i := 0; i < Length; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(alphabet[n.Int64()])
+	}
+	return b.String(), nil
+}
+
+// Normalize turns a code as typed by a guest, e.g. " hbk 7f3q9k", into its
+// stored form. It returns "" if the result is not a well-formed code.
+func Normalize(code string) string {
+	code = strings.ToUpper(strings.Join(strings.Fields(code), ""))
+	code = strings.TrimPrefix(strings.TrimPrefix(code, "HBK"), "-")
+	if len(code) != Length {
+		return ""
+	}
+	for _, c := range code {
+		if !strings.ContainsRune(alphabet, c) {
+			return ""
+		}
+	}
+	return Prefix + code
+}
@@ -0,0 +1,83 @@
+// Package filestore keeps uploaded files on local disk. Files are stored
+// under random keys, never under the names clients give them, so an upload
+// cannot choose where it lands or overwrite another.
+package filestore
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// ErrTooLarge is returned by Save for content over the size limit.
+var ErrTooLarge = errors.New("file is too large")
+
+type Local struct {
+	dir string
+}
+
+// NewLocal stores files in dir, creating it if needed.
+func NewLocal(dir string) (*Local, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("creating file store: %w", err)
+	}
+	return &Local{dir: dir}, nil
+}
+
+// Save writes content of at most maxBytes to a new file and returns its key
+// and size. Nothing is kept when it fails.
+func (s *Local) Save(content io.Reader, maxBytes int64) (string, int64, error) {
+	key, err := newKey()
+	if err != nil {
+		return "", 0, err
+	}
+
+	path := s.path(key)
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o640)
+	if err != nil {
+		return "", 0, err
+	}
+
+	size, err := io.Copy(file, io.LimitReader(content, maxBytes+1))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && size > maxBytes {
+		err = ErrTooLarge
+	}
+	if err != nil {
+		os.Remove(path)
+		return "", 0, err
+	}
+
+	return key, size, nil
+}
+
+func (s *Local) Open(key string) (*os.File, error) {
+	return os.Open(s.path(key))
+}
+
+func (s *Local) Delete(key string) error {
+	err := os.Remove(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// path keeps keys inside the store's directory whatever they contain.
+func (s *Local) path(key string) string {
+	return filepath.Join(s.dir, filepath.Base(key))
+}
+
+func newKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
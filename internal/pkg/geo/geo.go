@@ -0,0 +1,79 @@
+// Package geo holds the spherical-earth arithmetic behind distance searches,
+// which run in plain SQL rather than needing PostGIS.
+package geo
+
+import (
+	"errors"
+	"math"
+)
+
+// EarthRadiusKm is the mean radius of the earth, which distances assume to
+// be a sphere. That is good to about half a percent.
+const EarthRadiusKm = 6371.0
+
+var ErrInvalidPoint = errors.New("latitude must be between -90 and 90 and longitude between -180 and 180")
+
+// Point is a position in decimal degrees.
+type Point struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+func (p Point) Validate() error {
+	if math.IsNaN(p.Latitude) || math.IsNaN(p.Longitude) ||
+		p.Latitude < -90 || p.Latitude > 90 || p.Longitude < -180 || p.Longitude > 180 {
+		return ErrInvalidPoint
+	}
+	return nil
+}
+
+// Box is a range of latitudes and longitudes. When MinLongitude is more
+// than MaxLongitude the box crosses the antimeridian and takes in the
+// longitudes from MinLongitude up to 180 and from -180 up to MaxLongitude.
+type Box struct {
+	MinLatitude  float64
+	MaxLatitude  float64
+	MinLongitude float64
+	MaxLongitude float64
+}
+
+// BoundingBox returns a box holding every point within radiusKm of p, so
+// that an index on latitude and longitude can rule out most of the rest
+// before distances are worked out. Near a pole the box takes in every
+// longitude.
+func BoundingBox(p Point, radiusKm float64) Box {
+	angle := radiusKm / EarthRadiusKm * 180 / math.Pi
+
+	box := Box{
+		MinLatitude:  p.Latitude - angle,
+		MaxLatitude:  p.Latitude + angle,
+		MinLongitude: -180,
+		MaxLongitude: 180,
+	}
+	if box.MinLatitude <= -90 || box.MaxLatitude >= 90 {
+		box.MinLatitude = math.Max(box.MinLatitude, -90)
+		box.MaxLatitude = math.Min(box.MaxLatitude, 90)
+		return box
+	}
+
+	// The widest point of the circle in longitude is not at p's latitude
+	// but a little towards the nearer pole.
+	sin := math.Sin(radiusKm/EarthRadiusKm) / math.Cos(p.Latitude*math.Pi/180)
+	if sin >= 1 {
+		return box
+	}
+	spread := math.Asin(sin) * 180 / math.Pi
+	box.MinLongitude = wrapLongitude(p.Longitude - spread)
+	box.MaxLongitude = wrapLongitude(p.Longitude + spread)
+	return box
+}
+
+func wrapLongitude(longitude float64) float64 {
+	if longitude < -180 {
+		return longitude + 360
+	}
+	if longitude > 180 {
+		return longitude - 360
+	}
+	return longitude
+}
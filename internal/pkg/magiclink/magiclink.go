@@ -0,0 +1,97 @@
+// Package magiclink signs and checks the tokens in the links emailed to
+// guests who book without an account. A booking token grants access to one
+// booking only, and a claim token lets the holder of a guest's mailbox turn
+// the guest into an account; neither can be used as a login token.
+package magiclink
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	purpose      = "guest_booking"
+	claimPurpose = "guest_claim"
+)
+
+var ErrInvalid = errors.New("invalid or expired booking link")
+
+var ErrInvalidClaim = errors.New("invalid or expired registration link")
+
+// Sign returns a token for the guest's access to the booking until
+// expiresAt.
+func Sign(secret string, bookingID, guestID int, expiresAt time.Time) (string, error) {
+	claims := jwt.MapClaims{
+		"purpose":    purpose,
+		"booking_id": bookingID,
+		"guest_id":   guestID,
+		"exp":        expiresAt.Unix(),
+	}
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+}
+
+// Verify checks a token and returns the booking and guest it was issued for.
+func Verify(secret, token string) (bookingID, guestID int, err error) {
+	claims, ok := parse(secret, token, purpose)
+	if !ok {
+		return 0, 0, ErrInvalid
+	}
+
+	booking, okBooking := claims["booking_id"].(float64)
+	guest, okGuest := claims["guest_id"].(float64)
+	if !okBooking || !okGuest {
+		return 0, 0, ErrInvalid
+	}
+
+	return int(booking), int(guest), nil
+}
+
+// SignClaim returns a token that lets the guest register with their email
+// address, and so keep their bookings, until expiresAt.
+func SignClaim(secret string, guestID int, expiresAt time.Time) (string, error) {
+	claims := jwt.MapClaims{
+		"purpose":  claimPurpose,
+		"guest_id": guestID,
+		"exp":      expiresAt.Unix(),
+	}
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+}
+
+// VerifyClaim checks a claim token and returns the guest it was issued for.
+func VerifyClaim(secret, token string) (guestID int, err error) {
+	claims, ok := parse(secret, token, claimPurpose)
+	if !ok {
+		return 0, ErrInvalidClaim
+	}
+
+	guest, ok := claims["guest_id"].(float64)
+	if !ok {
+		return 0, ErrInvalidClaim
+	}
+
+	return int(guest), nil
+}
+
+func parse(secret, token, want string) (jwt.MapClaims, bool) {
+	parsed, err := jwt.Parse(token, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method %v", t.Header["alg"])
+		}
+		return []byte(secret), nil
+	})
+	if err != nil || !parsed.Valid {
+		return nil, false
+	}
+
+	claims, ok := parsed.Claims.(jwt.MapClaims)
+	if !ok || claims["purpose"] != want {
+		return nil, false
+	}
+
+	return claims, true
+}
@@ -0,0 +1,356 @@
+package money
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is used when an amount arrives without a currency code.
+const DefaultCurrency = "USD"
+
+// Money is an exact amount expressed in the minor units of its currency
+// (cents for USD, whole yen for JPY).
+//
+// Rounding rules:
+//   - Stored prices are always whole minor units, so a nightly rate never
+//     needs rounding.
+//   - A stay total is the sum of the per-night amounts (Times), never a
+//     rounded product of an unrounded rate.
+//   - Any fractional result (percentages, ratios, conversions) is rounded
+//     once, to the nearest minor unit, with halves rounded away from zero.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+var (
+	ErrInvalidAmount    = errors.New("invalid money amount")
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrInvalidRate      = errors.New("invalid exchange rate")
+)
+
+// exponents lists currencies whose minor unit is not 1/100.
+var exponents = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0,
+	"KRW": 0, "PYG": 0, "RWF": 0, "UGX": 0, "VND": 0, "VUV": 0, "XAF": 0,
+	"XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+// Exponent returns the number of decimal places of the currency's minor unit.
+func Exponent(currency string) int {
+	if exp, ok := exponents[strings.ToUpper(currency)]; ok {
+		return exp
+	}
+	return 2
+}
+
+func New(amount int64, currency string) Money {
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	return Money{Amount: amount, Currency: strings.ToUpper(currency)}
+}
+
+func Zero(currency string) Money {
+	return New(0, currency)
+}
+
+// Parse reads a decimal string such as "150.00" or "-3.5" into minor units.
+// More fractional digits than the currency allows is an error, not a rounding.
+func Parse(value, currency string) (Money, error) {
+	m := New(0, currency)
+	exp := Exponent(m.Currency)
+
+	s := strings.TrimSpace(value)
+	negative := false
+	if strings.HasPrefix(s, "-") {
+		negative = true
+		s = s[1:]
+	} else if strings.HasPrefix(s, "+") {
+		s = s[1:]
+	}
+
+	whole, frac, hasDot := strings.Cut(s, ".")
+	if whole == "" && (!hasDot || frac == "") {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, value)
+	}
+	if len(frac) > exp {
+		trimmed := strings.TrimRight(frac[exp:], "0")
+		if trimmed != "" {
+			return Money{}, fmt.Errorf("%w: %q has more than %d decimal places", ErrInvalidAmount, value, exp)
+		}
+		frac = frac[:exp]
+	}
+	frac += strings.Repeat("0", exp-len(frac))
+
+	digits := whole + frac
+	if digits == "" {
+		digits = "0"
+	}
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, value)
+		}
+	}
+
+	amount, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, value)
+	}
+	if negative {
+		amount = -amount
+	}
+	m.Amount = amount
+	return m, nil
+}
+
+// Decimal formats the amount with exactly the currency's number of decimals.
+func (m Money) Decimal() string {
+	exp := Exponent(m.Currency)
+	amount := m.Amount
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	s := strconv.FormatInt(amount, 10)
+	if exp == 0 {
+		return sign + s
+	}
+	if len(s) <= exp {
+		s = strings.Repeat("0", exp-len(s)+1) + s
+	}
+	return sign + s[:len(s)-exp] + "." + s[len(s)-exp:]
+}
+
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Money{}, err
+	}
+	return New(m.Amount+other.Amount, m.Currency), nil
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Money{}, err
+	}
+	return New(m.Amount-other.Amount, m.Currency), nil
+}
+
+func (m Money) Neg() Money {
+	return New(-m.Amount, m.Currency)
+}
+
+// Times multiplies by a whole count, e.g. a nightly rate by a number of nights.
+func (m Money) Times(n int64) Money {
+	return New(m.Amount*n, m.Currency)
+}
+
+// MulRatio returns m * num / den rounded half away from zero.
+func (m Money) MulRatio(num, den int64) Money {
+	if den == 0 {
+		panic("money: division by zero")
+	}
+	return New(divRound(m.Amount*num, den), m.Currency)
+}
+
+// Percent applies a percentage given in basis points (1250 = 12.5%).
+func (m Money) Percent(basisPoints int64) Money {
+	return m.MulRatio(basisPoints, 10000)
+}
+
+// Min returns the smaller of two amounts in the same currency.
+func (m Money) Min(other Money) Money {
+	if other.Amount < m.Amount {
+		return other
+	}
+	return m
+}
+
+func (m Money) sameCurrency(other Money) error {
+	if m.Currency != other.Currency {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+	return nil
+}
+
+func divRound(n, d int64) int64 {
+	if d < 0 {
+		n, d = -n, -d
+	}
+	q, r := n/d, n%d
+	if r < 0 {
+		r = -r
+	}
+	if 2*r >= d {
+		if n < 0 {
+			q--
+		} else {
+			q++
+		}
+	}
+	return q
+}
+
+type jsonMoney struct {
+	Amount   json.RawMessage `json:"amount"`
+	Currency string          `json:"currency"`
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Amount   string `json:"amount"`
+		Currency string `json:"currency"`
+	}{
+		Amount:   m.Decimal(),
+		Currency: m.Currency,
+	})
+}
+
+// UnmarshalJSON accepts {"amount": "150.00", "currency": "USD"}, with the
+// amount as a string or a JSON number, or a bare amount in DefaultCurrency.
+// Numbers are read from their literal text so they never pass through float64.
+func (m *Money) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	if bytes.Equal(b, []byte("null")) {
+		return nil
+	}
+
+	currency := m.Currency
+	raw := b
+	if len(b) > 0 && b[0] == '{' {
+		var obj jsonMoney
+		if err := json.Unmarshal(b, &obj); err != nil {
+			return err
+		}
+		if obj.Currency != "" {
+			currency = obj.Currency
+		}
+		raw = bytes.TrimSpace(obj.Amount)
+	}
+
+	value := string(raw)
+	if len(raw) > 0 && raw[0] == '"' {
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return err
+		}
+	}
+
+	parsed, err := Parse(value, currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Convert applies an exchange rate, given as a decimal string in units of
+// `to` per one unit of m.Currency. The result is rounded once, to the
+// target currency's minor unit, with halves rounded away from zero.
+func Convert(m Money, rate, to string) (Money, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(rate))
+	if !ok || r.Sign() <= 0 {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidRate, rate)
+	}
+	to = strings.ToUpper(to)
+
+	v := new(big.Rat).SetInt64(m.Amount)
+	v.Mul(v, r)
+
+	shift := Exponent(to) - Exponent(m.Currency)
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(shift))), nil))
+	if shift >= 0 {
+		v.Mul(v, scale)
+	} else {
+		v.Quo(v, scale)
+	}
+
+	return New(roundRat(v), to), nil
+}
+
+// ConversionFactor returns what an amount in minor units of `from` is
+// multiplied by to give minor units of `to` at the rate, as a decimal
+// string. It lets a database convert and compare amounts the same way
+// Convert does, rounding the product once.
+func ConversionFactor(rate, from, to string) (string, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(rate))
+	if !ok || r.Sign() <= 0 {
+		return "", fmt.Errorf("%w: %q", ErrInvalidRate, rate)
+	}
+
+	shift := Exponent(to) - Exponent(from)
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(shift))), nil))
+	if shift >= 0 {
+		r.Mul(r, scale)
+	} else {
+		r.Quo(r, scale)
+	}
+
+	return r.FloatString(16), nil
+}
+
+// InvertRate returns 1/rate, for reading a BASE/QUOTE rate as QUOTE/BASE.
+func InvertRate(rate string) (string, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(rate))
+	if !ok || r.Sign() <= 0 {
+		return "", fmt.Errorf("%w: %q", ErrInvalidRate, rate)
+	}
+	return new(big.Rat).Inv(r).FloatString(10), nil
+}
+
+// ValidRate reports whether rate is a positive decimal number.
+func ValidRate(rate string) bool {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(rate))
+	return ok && r.Sign() > 0
+}
+
+// ValidCurrency reports whether code looks like an ISO 4217 alphabetic code.
+func ValidCurrency(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, c := range code {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+func roundRat(v *big.Rat) int64 {
+	num := new(big.Int).Abs(v.Num())
+	den := v.Denom()
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if r.Lsh(r, 1).Cmp(den) >= 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	if v.Sign() < 0 {
+		q.Neg(q)
+	}
+	return q.Int64()
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,231 @@
+package repositories
+
+import (
+	"database/sql"
+
+	"github.com/lib/pq"
+
+	"hotel-booking-service/internal/data"
+)
+
+type AmenityRepository struct {
+	db *sql.DB
+}
+
+func NewAmenityRepository(db *sql.DB) *AmenityRepository {
+	return &AmenityRepository{db: db}
+}
+
+const amenityColumns = `a.id, a.code, a.name, a.scope`
+
+func scanAmenity(row rowScanner) (data.Amenity, error) {
+	var amenity data.Amenity
+	err := row.Scan(
+		&amenity.ID,
+		&amenity.Code,
+		&amenity.Name,
+		&amenity.Scope,
+	)
+	return amenity, err
+}
+
+// hasAmenities is true when every amenity whose code is in the array
+// placeholder that fills it in belongs to hotel h or room type t, or else
+// when one room of type t has the rest. Hotel amenities are only assigned
+// to hotels and room amenities to room types and rooms.
+const hasAmenities = `(NOT EXISTS (
+	SELECT 1 FROM amenities a
+	WHERE a.code = ANY(%[1]s)
+	AND NOT EXISTS (SELECT 1 FROM hotel_amenities ha WHERE ha.hotel_id = h.id AND ha.amenity_id = a.id)
+	AND NOT EXISTS (SELECT 1 FROM room_type_amenities ra WHERE ra.room_type_id = t.id AND ra.amenity_id = a.id))
+OR EXISTS (
+	SELECT 1 FROM rooms r
+	WHERE r.room_type_id = t.id
+	AND NOT EXISTS (
+		SELECT 1 FROM amenities a
+		WHERE a.code = ANY(%[1]s)
+		AND NOT EXISTS (SELECT 1 FROM hotel_amenities ha WHERE ha.hotel_id = h.id AND ha.amenity_id = a.id)
+		AND NOT EXISTS (SELECT 1 FROM room_type_amenities ra WHERE ra.room_type_id = t.id AND ra.amenity_id = a.id)
+		AND NOT EXISTS (SELECT 1 FROM room_amenities rm WHERE rm.room_id = r.id AND rm.amenity_id = a.id))))`
+
+// GetAll lists the catalog, hotel amenities first.
+func (r *AmenityRepository) GetAll() ([]data.Amenity, error) {
+	rows, err := r.db.Query(`SELECT ` + amenityColumns + ` FROM amenities a ORDER BY a.scope, a.name, a.id`)
+	if err != nil {
+		return nil, err
+	}
+	return scanAmenities(rows)
+}
+
+func (r *AmenityRepository) GetByID(id int) (*data.Amenity, error) {
+	amenity, err := scanAmenity(r.db.QueryRow(`SELECT `+amenityColumns+` FROM amenities a WHERE a.id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &amenity, nil
+}
+
+// GetByCodes returns the amenities with the given codes; codes that are not
+// in the catalog are left out.
+func (r *AmenityRepository) GetByCodes(codes []string) ([]data.Amenity, error) {
+	rows, err := r.db.Query(`SELECT `+amenityColumns+` FROM amenities a WHERE a.code = ANY($1) ORDER BY a.name, a.id`, pq.Array(codes))
+	if err != nil {
+		return nil, err
+	}
+	return scanAmenities(rows)
+}
+
+// Create adds an amenity to the catalog, or returns nil if its code is
+// taken.
+func (r *AmenityRepository) Create(amenity *data.Amenity) (*data.Amenity, error) {
+	err := r.db.QueryRow(
+		`INSERT INTO amenities (code, name, scope) VALUES ($1, $2, $3) RETURNING id`,
+		amenity.Code, amenity.Name, amenity.Scope,
+	).Scan(&amenity.ID)
+	if isUniqueViolation(err, "amenities_code_key") {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return amenity, nil
+}
+
+// Update renames an amenity, or returns nil if it is gone or its new code is
+// taken.
+func (r *AmenityRepository) Update(amenity *data.Amenity) (*data.Amenity, error) {
+	result, err := r.db.Exec(`UPDATE amenities SET code = $1, name = $2 WHERE id = $3`, amenity.Code, amenity.Name, amenity.ID)
+	if isUniqueViolation(err, "amenities_code_key") {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if affected == 0 {
+		return nil, nil
+	}
+	return r.GetByID(amenity.ID)
+}
+
+// Delete removes an amenity from the catalog and from every hotel, room
+// type and room that had it, and reports whether there was one.
+func (r *AmenityRepository) Delete(id int) (bool, error) {
+	result, err := r.db.Exec(`DELETE FROM amenities WHERE id = $1`, id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// SetForHotel replaces the hotel's amenities.
+func (r *AmenityRepository) SetForHotel(hotelID int, amenityIDs []int) error {
+	return r.replace(`hotel_amenities`, `hotel_id`, hotelID, amenityIDs)
+}
+
+// SetForRoomType replaces the room type's amenities.
+func (r *AmenityRepository) SetForRoomType(roomTypeID int, amenityIDs []int) error {
+	return r.replace(`room_type_amenities`, `room_type_id`, roomTypeID, amenityIDs)
+}
+
+// SetForRoom replaces the amenities the room has on top of its type's.
+func (r *AmenityRepository) SetForRoom(roomID int, amenityIDs []int) error {
+	return r.replace(`room_amenities`, `room_id`, roomID, amenityIDs)
+}
+
+func (r *AmenityRepository) replace(table, column string, ownerID int, amenityIDs []int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM `+table+` WHERE `+column+` = $1`, ownerID); err != nil {
+		return err
+	}
+
+	if len(amenityIDs) > 0 {
+		_, err := tx.Exec(
+			`INSERT INTO `+table+` (`+column+`, amenity_id) SELECT $1, unnest($2::int[]) ON CONFLICT DO NOTHING`,
+			ownerID, pq.Array(amenityIDs),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ForHotels returns the amenities of each of the hotels, keyed by hotel.
+func (r *AmenityRepository) ForHotels(hotelIDs []int) (map[int][]data.Amenity, error) {
+	return r.forOwners(`hotel_amenities`, `hotel_id`, hotelIDs)
+}
+
+// ForRoomTypes returns the amenities of each of the room types, keyed by
+// room type.
+func (r *AmenityRepository) ForRoomTypes(roomTypeIDs []int) (map[int][]data.Amenity, error) {
+	return r.forOwners(`room_type_amenities`, `room_type_id`, roomTypeIDs)
+}
+
+// ForRooms returns the amenities each of the rooms has on top of its
+// type's, keyed by room.
+func (r *AmenityRepository) ForRooms(roomIDs []int) (map[int][]data.Amenity, error) {
+	return r.forOwners(`room_amenities`, `room_id`, roomIDs)
+}
+
+func (r *AmenityRepository) forOwners(table, column string, ownerIDs []int) (map[int][]data.Amenity, error) {
+	amenities := make(map[int][]data.Amenity)
+	if len(ownerIDs) == 0 {
+		return amenities, nil
+	}
+
+	query := `SELECT o.` + column + `, ` + amenityColumns + `
+		FROM ` + table + ` o JOIN amenities a ON a.id = o.amenity_id
+		WHERE o.` + column + ` = ANY($1)
+		ORDER BY o.` + column + `, a.name, a.id`
+
+	rows, err := r.db.Query(query, pq.Array(ownerIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ownerID int
+		var amenity data.Amenity
+		if err := rows.Scan(&ownerID, &amenity.ID, &amenity.Code, &amenity.Name, &amenity.Scope); err != nil {
+			return nil, err
+		}
+		amenities[ownerID] = append(amenities[ownerID], amenity)
+	}
+
+	return amenities, rows.Err()
+}
+
+func scanAmenities(rows *sql.Rows) ([]data.Amenity, error) {
+	defer rows.Close()
+
+	amenities := []data.Amenity{}
+	for rows.Next() {
+		amenity, err := scanAmenity(rows)
+		if err != nil {
+			return nil, err
+		}
+		amenities = append(amenities, amenity)
+	}
+
+	return amenities, rows.Err()
+}
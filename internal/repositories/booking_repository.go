@@ -2,9 +2,14 @@ package repositories
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
-	
+
+	"github.com/lib/pq"
+
 	"hotel-booking-service/internal/data"
+	"hotel-booking-service/internal/pkg/confcode"
+	"hotel-booking-service/internal/pkg/money"
 )
 
 type BookingRepository struct {
@@ -15,139 +20,639 @@ func NewBookingRepository(db *sql.DB) *BookingRepository {
 	return &BookingRepository{db: db}
 }
 
-func (r *BookingRepository) CreateBooking(userID, roomID int, fromDate, toDate time.Time) (*data.Booking, error) {
-	query := `
-		INSERT INTO bookings (user_id, room_id, from_date, to_date, status)
-		VALUES ($1, $2, $3, $4, 'confirmed')
-		RETURNING id, user_id, room_id, from_date, to_date, status, created_at
-	`
-	
+// releasedStatuses are the booking states that no longer hold a room.
+const releasedStatuses = `'cancelled', 'walked', 'no_show'`
+
+const bookingColumns = `
+	b.id, b.confirmation_code, b.user_id, (SELECT bt.hotel_id FROM room_types bt WHERE bt.id = b.room_type_id), b.room_type_id, b.room_id, b.from_date, b.to_date, (b.to_date - b.from_date),
+	b.nightly_amount, b.total_amount, b.currency,
+	b.display_currency, b.exchange_rate, b.display_total_amount,
+	b.cancellation_policy, b.cancelled_at, b.penalty_amount, b.refund_amount,
+	b.checked_in_at, b.guest_id_document, b.checked_out_at,
+	b.points_redeemed, b.loyalty_discount_amount, b.extras_amount,
+	b.status, b.version, b.created_at`
+
+func scanBooking(row rowScanner) (data.Booking, error) {
 	var booking data.Booking
-	err := r.db.QueryRow(
-		query,
-		userID,
-		roomID,
-		fromDate,
-		toDate,
-	).Scan(
+	var displayCurrency, exchangeRate sql.NullString
+	var displayTotal, penalty, refund, loyaltyDiscount sql.NullInt64
+	var extrasAmount int64
+	var policy []byte
+	var cancelledAt, checkedInAt, checkedOutAt sql.NullTime
+	var idDocument sql.NullString
+	var roomID sql.NullInt64
+	err := row.Scan(
 		&booking.ID,
+		&booking.ConfirmationCode,
 		&booking.UserID,
-		&booking.RoomID,
+		&booking.HotelID,
+		&booking.RoomTypeID,
+		&roomID,
 		&booking.FromDate,
 		&booking.ToDate,
+		&booking.Nights,
+		&booking.NightlyRate.Amount,
+		&booking.TotalPrice.Amount,
+		&booking.NightlyRate.Currency,
+		&displayCurrency,
+		&exchangeRate,
+		&displayTotal,
+		&policy,
+		&cancelledAt,
+		&penalty,
+		&refund,
+		&checkedInAt,
+		&idDocument,
+		&checkedOutAt,
+		&booking.PointsRedeemed,
+		&loyaltyDiscount,
+		&extrasAmount,
 		&booking.Status,
+		&booking.Version,
 		&booking.CreatedAt,
 	)
-	
 	if err != nil {
+		return booking, err
+	}
+
+	booking.RoomID = int(roomID.Int64)
+	booking.TotalPrice.Currency = booking.NightlyRate.Currency
+	if displayCurrency.Valid && displayTotal.Valid {
+		total := money.New(displayTotal.Int64, displayCurrency.String)
+		booking.DisplayCurrency = displayCurrency.String
+		booking.ExchangeRate = exchangeRate.String
+		booking.DisplayTotal = &total
+	}
+	if len(policy) > 0 {
+		booking.CancellationPolicy = &data.CancellationPolicy{}
+		if err := json.Unmarshal(policy, booking.CancellationPolicy); err != nil {
+			return booking, err
+		}
+	}
+	if cancelledAt.Valid {
+		booking.CancelledAt = &cancelledAt.Time
+	}
+	if checkedInAt.Valid {
+		booking.CheckedInAt = &checkedInAt.Time
+	}
+	if checkedOutAt.Valid {
+		booking.CheckedOutAt = &checkedOutAt.Time
+	}
+	booking.GuestIDDocument = idDocument.String
+	booking.Penalty = nullMoney(penalty, booking.TotalPrice.Currency)
+	booking.Refund = nullMoney(refund, booking.TotalPrice.Currency)
+	booking.LoyaltyDiscount = nullMoney(loyaltyDiscount, booking.TotalPrice.Currency)
+	if extrasAmount != 0 {
+		extras := money.New(extrasAmount, booking.TotalPrice.Currency)
+		booking.ExtrasTotal = &extras
+	}
+	return booking, nil
+}
+
+func nullMoney(amount sql.NullInt64, currency string) *money.Money {
+	if !amount.Valid {
+		return nil
+	}
+	m := money.New(amount.Int64, currency)
+	return &m
+}
+
+func scanBookings(rows *sql.Rows) ([]data.Booking, error) {
+	defer rows.Close()
+
+	var bookings []data.Booking
+	for rows.Next() {
+		booking, err := scanBooking(rows)
+		if err != nil {
+			return nil, err
+		}
+		bookings = append(bookings, booking)
+	}
+
+	if err := rows.Err(); err != nil {
 		return nil, err
 	}
-	
-	return &booking, nil
+
+	return bookings, nil
 }
 
-func (r *BookingRepository) GetBooking(id int) (*data.Booking, error) {
+// CreateBooking stores a pending booking under a fresh confirmation code,
+// drawing another code in the rare case one is already taken. The room type
+// is locked while available is asked whether the stay can still be sold and
+// until the booking is stored, so that two bookings cannot both take the
+// last unit; nil is returned if it cannot. A nil available books
+// unconditionally.
+func (r *BookingRepository) CreateBooking(booking *data.Booking, available func() (bool, error)) (*data.Booking, error) {
 	query := `
-		SELECT id, user_id, room_id, from_date, to_date, status, created_at
-		FROM bookings
-		WHERE id = $1
-	`
-	
-	var booking data.Booking
-	err := r.db.QueryRow(query, id).Scan(
-		&booking.ID,
-		&booking.UserID,
-		&booking.RoomID,
-		&booking.FromDate,
-		&booking.ToDate,
-		&booking.Status,
-		&booking.CreatedAt,
-	)
-	
+		INSERT INTO bookings AS b (
+			confirmation_code, user_id, room_type_id, room_id, from_date, to_date, nightly_amount, total_amount, currency,
+			display_currency, exchange_rate, display_total_amount, cancellation_policy,
+			points_redeemed, loyalty_discount_amount, extras_amount, status
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, 'pending')
+		RETURNING ` + bookingColumns
+
+	var displayCurrency, exchangeRate sql.NullString
+	var displayTotal sql.NullInt64
+	if booking.DisplayTotal != nil {
+		displayCurrency = sql.NullString{String: booking.DisplayCurrency, Valid: true}
+		exchangeRate = sql.NullString{String: booking.ExchangeRate, Valid: true}
+		displayTotal = sql.NullInt64{Int64: booking.DisplayTotal.Amount, Valid: true}
+	}
+
+	var roomID sql.NullInt64
+	if booking.RoomID != 0 {
+		roomID = sql.NullInt64{Int64: int64(booking.RoomID), Valid: true}
+	}
+
+	var loyaltyDiscount sql.NullInt64
+	if booking.LoyaltyDiscount != nil {
+		loyaltyDiscount = sql.NullInt64{Int64: booking.LoyaltyDiscount.Amount, Valid: true}
+	}
+
+	var extrasAmount int64
+	if booking.ExtrasTotal != nil {
+		extrasAmount = booking.ExtrasTotal.Amount
+	}
+
+	var policy sql.NullString
+	if booking.CancellationPolicy != nil {
+		snapshot, err := json.Marshal(booking.CancellationPolicy)
+		if err != nil {
+			return nil, err
+		}
+		policy = sql.NullString{String: string(snapshot), Valid: true}
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT id FROM room_types WHERE id = $1 FOR UPDATE`, booking.RoomTypeID); err != nil {
+		return nil, err
+	}
+
+	if available != nil {
+		ok, err := available()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, nil
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		code, err := confcode.New()
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := tx.Exec(`SAVEPOINT confirmation_code`); err != nil {
+			return nil, err
+		}
+
+		created, err := scanBooking(tx.QueryRow(
+			query,
+			code,
+			booking.UserID,
+			booking.RoomTypeID,
+			roomID,
+			booking.FromDate,
+			booking.ToDate,
+			booking.NightlyRate.Amount,
+			booking.TotalPrice.Amount,
+			booking.TotalPrice.Currency,
+			displayCurrency,
+			exchangeRate,
+			displayTotal,
+			policy,
+			booking.PointsRedeemed,
+			loyaltyDiscount,
+			extrasAmount,
+		))
+		if isUniqueViolation(err, "bookings_confirmation_code_key") && attempt < 5 {
+			if _, err := tx.Exec(`ROLLBACK TO SAVEPOINT confirmation_code`); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return &created, tx.Commit()
+	}
+}
+
+func isUniqueViolation(err error, constraint string) bool {
+	pqErr, ok := err.(*pq.Error)
+	return ok && pqErr.Code == "23505" && pqErr.Constraint == constraint
+}
+
+func (r *BookingRepository) GetBooking(id int) (*data.Booking, error) {
+	query := `SELECT ` + bookingColumns + ` FROM bookings b WHERE b.id = $1`
+
+	booking, err := scanBooking(r.db.QueryRow(query, id))
+
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
 		return nil, err
 	}
-	
+
 	return &booking, nil
 }
 
-func (r *BookingRepository) UpdateBookingStatus(id int, status string) error {
-	query := `UPDATE bookings SET status = $1 WHERE id = $2`
-	
-	_, err := r.db.Exec(query, status, id)
-	return err
+// GetByConfirmationCode finds a booking by the code quoted by its guest.
+func (r *BookingRepository) GetByConfirmationCode(code string) (*data.Booking, error) {
+	query := `SELECT ` + bookingColumns + ` FROM bookings b WHERE b.confirmation_code = $1`
+
+	booking, err := scanBooking(r.db.QueryRow(query, code))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &booking, nil
 }
 
-func (r *BookingRepository) GetUserBookings(userID int) ([]data.Booking, error) {
+// CancelBooking records the cancellation outcome. It only touches bookings
+// that still hold a room, whose guest has not checked in and that are still
+// at version, and reports whether a row changed.
+func (r *BookingRepository) CancelBooking(id, version int, penalty, refund money.Money) (bool, error) {
 	query := `
-		SELECT id, user_id, room_id, from_date, to_date, status, created_at
-		FROM bookings
-		WHERE user_id = $1
-		ORDER BY created_at DESC
+		UPDATE bookings
+		SET status = 'cancelled', cancelled_at = CURRENT_TIMESTAMP,
+			penalty_amount = $1, refund_amount = $2
+		WHERE id = $3 AND version = $4
+		AND status NOT IN (` + releasedStatuses + `, 'checked_in', 'checked_out')
 	`
-	
-	rows, err := r.db.Query(query, userID)
+
+	result, err := r.db.Exec(query, penalty.Amount, refund.Amount, id, version)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// ConfirmBooking moves a pending booking to confirmed once it is paid for,
+// and reports whether it was still pending.
+func (r *BookingRepository) ConfirmBooking(id int) (bool, error) {
+	result, err := r.db.Exec(`UPDATE bookings SET status = 'confirmed' WHERE id = $1 AND status = 'pending'`, id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// ExpirePendingBookings cancels bookings left unpaid since before cutoff so
+// they stop holding their rooms.
+func (r *BookingRepository) ExpirePendingBookings(cutoff time.Time) ([]int, error) {
+	query := `
+		UPDATE bookings
+		SET status = 'cancelled', cancelled_at = CURRENT_TIMESTAMP, penalty_amount = 0, refund_amount = 0
+		WHERE status = 'pending' AND created_at < $1
+		RETURNING id
+	`
+
+	rows, err := r.db.Query(query, cutoff)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	
-	var bookings []data.Booking
+
+	var ids []int
 	for rows.Next() {
-		var booking data.Booking
-		if err := rows.Scan(
-			&booking.ID,
-			&booking.UserID,
-			&booking.RoomID,
-			&booking.FromDate,
-			&booking.ToDate,
-			&booking.Status,
-			&booking.CreatedAt,
-		); err != nil {
+		var id int
+		if err := rows.Scan(&id); err != nil {
 			return nil, err
 		}
-		bookings = append(bookings, booking)
+		ids = append(ids, id)
 	}
-	
-	if err = rows.Err(); err != nil {
+
+	return ids, rows.Err()
+}
+
+// GetHotelBookings returns the hotel's bookings, assigned to a room or not,
+// that still hold a unit on any night from fromDate up to, but not
+// including, toDate.
+func (r *BookingRepository) GetHotelBookings(hotelID int, fromDate, toDate time.Time) ([]data.Booking, error) {
+	query := `
+		SELECT ` + bookingColumns + `
+		FROM bookings b
+		JOIN room_types t ON t.id = b.room_type_id
+		WHERE t.hotel_id = $1
+		AND b.status NOT IN (` + releasedStatuses + `)
+		AND b.from_date < $3 AND b.to_date > $2
+		ORDER BY b.from_date, b.id
+	`
+
+	rows, err := r.db.Query(query, hotelID, fromDate, toDate)
+	if err != nil {
 		return nil, err
 	}
-	
-	return bookings, nil
+
+	return scanBookings(rows)
 }
 
-func (r *BookingRepository) GetAllBookings() ([]data.Booking, error) {
-	query := `SELECT id, user_id, room_id, from_date, to_date, status, created_at FROM bookings`
+// ReassignRoom moves a live booking to another room, which may be of
+// another type.
+func (r *BookingRepository) ReassignRoom(id, roomID, roomTypeID int) (bool, error) {
+	query := `
+		UPDATE bookings SET room_id = $1, room_type_id = $2
+		WHERE id = $3 AND status NOT IN (` + releasedStatuses + `)
+	`
 
-	rows, err := r.db.Query(query)
+	result, err := r.db.Exec(query, roomID, roomTypeID, id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// WalkBooking releases a booking the hotel cannot honour and records where
+// the guest was sent. The guest owes nothing, so the whole total is refunded.
+func (r *BookingRepository) WalkBooking(walk *data.BookingWalk, refund money.Money) (*data.BookingWalk, error) {
+	tx, err := r.db.Begin()
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	defer tx.Rollback()
 
-	var bookings []data.Booking
-	for rows.Next() {
-		var booking data.Booking
-		if err := rows.Scan(
-			&booking.ID,
-			&booking.UserID,
-			&booking.RoomID,
-			&booking.FromDate,
-			&booking.ToDate,
-			&booking.Status,
-			&booking.CreatedAt,
-		); err != nil {
-			return nil, err
+	result, err := tx.Exec(`
+		UPDATE bookings
+		SET status = 'walked', cancelled_at = CURRENT_TIMESTAMP, penalty_amount = 0, refund_amount = $1
+		WHERE id = $2 AND status NOT IN (`+releasedStatuses+`)
+	`, refund.Amount, walk.BookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if affected == 0 {
+		return nil, nil
+	}
+
+	created := *walk
+	err = tx.QueryRow(`
+		INSERT INTO booking_walks (booking_id, partner_name, partner_reference, notes, walked_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`, walk.BookingID, walk.PartnerName, walk.PartnerReference, walk.Notes, walk.WalkedBy).Scan(&created.ID, &created.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+// AssignRoom gives a live booking that has no room yet its room.
+func (r *BookingRepository) AssignRoom(id, roomID int) (bool, error) {
+	query := `
+		UPDATE bookings SET room_id = $1
+		WHERE id = $2 AND room_id IS NULL AND status NOT IN (` + releasedStatuses + `)
+	`
+
+	result, err := r.db.Exec(query, roomID, id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// GetUnassignedArrivals returns live bookings arriving on or before the
+// given day that still have no room, earliest arrival first.
+func (r *BookingRepository) GetUnassignedArrivals(arrivingBy time.Time) ([]data.Booking, error) {
+	query := `
+		SELECT ` + bookingColumns + `
+		FROM bookings b
+		WHERE b.room_id IS NULL
+		AND b.status NOT IN (` + releasedStatuses + `)
+		AND b.from_date <= $1 AND b.to_date > CURRENT_DATE
+		ORDER BY b.from_date, b.id
+	`
+
+	rows, err := r.db.Query(query, arrivingBy)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanBookings(rows)
+}
+
+// GetNoShows returns the confirmed bookings whose guest has not checked in
+// by the hotel's no-show cutoff, measured from midnight on the arrival day.
+func (r *BookingRepository) GetNoShows(now time.Time) ([]data.Booking, error) {
+	query := `
+		SELECT ` + bookingColumns + `
+		FROM bookings b
+		JOIN room_types t ON t.id = b.room_type_id
+		JOIN hotels h ON h.id = t.hotel_id
+		WHERE b.status = 'confirmed'
+		AND b.from_date + h.no_show_cutoff_hour * INTERVAL '1 hour' <= $1
+		ORDER BY b.from_date, b.id
+	`
+
+	rows, err := r.db.Query(query, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanBookings(rows)
+}
+
+// MarkNoShow releases a booking whose guest never arrived and records the
+// cancellation outcome. It only touches bookings still confirmed, so of
+// several callers exactly one sees true.
+func (r *BookingRepository) MarkNoShow(id int, penalty, refund money.Money) (bool, error) {
+	query := `
+		UPDATE bookings
+		SET status = 'no_show', cancelled_at = CURRENT_TIMESTAMP,
+			penalty_amount = $1, refund_amount = $2
+		WHERE id = $3 AND status = 'confirmed'
+	`
+
+	result, err := r.db.Exec(query, penalty.Amount, refund.Amount, id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// CheckIn records the arrival of a confirmed booking's guest in the given
+// room, of the given type, moving the booking there. It reports whether the
+// booking was still waiting to check in.
+func (r *BookingRepository) CheckIn(id, roomID, roomTypeID int, idDocument string, staffID int) (bool, error) {
+	query := `
+		UPDATE bookings
+		SET status = 'checked_in', room_id = $1, room_type_id = $2, guest_id_document = $3,
+			checked_in_at = CURRENT_TIMESTAMP, checked_in_by = $4
+		WHERE id = $5 AND status = 'confirmed'
+	`
+
+	result, err := r.db.Exec(query, roomID, roomTypeID, idDocument, staffID, id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// CheckOut records the departure of a checked-in guest and leaves the room
+// for housekeeping. toDate and total replace the booked ones when the guest
+// leaves early; the quote shown in another currency no longer applies then
+// and is dropped.
+func (r *BookingRepository) CheckOut(id int, toDate time.Time, total money.Money, staffID int) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var roomID int
+	err = tx.QueryRow(`
+		UPDATE bookings
+		SET status = 'checked_out', checked_out_at = CURRENT_TIMESTAMP, checked_out_by = $1,
+			display_currency = CASE WHEN total_amount = $2 THEN display_currency END,
+			exchange_rate = CASE WHEN total_amount = $2 THEN exchange_rate END,
+			display_total_amount = CASE WHEN total_amount = $2 THEN display_total_amount END,
+			to_date = $3, total_amount = $2
+		WHERE id = $4 AND status = 'checked_in'
+		RETURNING room_id
+	`, staffID, total.Amount, toDate, id).Scan(&roomID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
 		}
-		bookings = append(bookings, booking)
+		return false, err
+	}
+
+	if _, err := tx.Exec(`UPDATE rooms SET housekeeping_status = 'dirty' WHERE id = $1`, roomID); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// RoomOccupied reports whether a guest other than the given booking's is
+// checked in to the room.
+func (r *BookingRepository) RoomOccupied(roomID, exceptBookingID int) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM bookings
+			WHERE room_id = $1 AND id <> $2 AND status = 'checked_in'
+		)
+	`
+
+	var occupied bool
+	err := r.db.QueryRow(query, roomID, exceptBookingID).Scan(&occupied)
+	return occupied, err
+}
+
+// GetArrivals returns the hotel's live bookings arriving on date, including
+// guests who have already checked in.
+func (r *BookingRepository) GetArrivals(hotelID int, date time.Time) ([]data.Booking, error) {
+	return r.getHotelDay(hotelID, date, `b.from_date = $2 AND b.status NOT IN (`+releasedStatuses+`)`)
+}
+
+// GetInHouse returns the guests checked in to the hotel for the night of
+// date.
+func (r *BookingRepository) GetInHouse(hotelID int, date time.Time) ([]data.Booking, error) {
+	return r.getHotelDay(hotelID, date, `b.from_date <= $2 AND b.to_date > $2 AND b.status IN ('checked_in', 'checked_out')`)
+}
+
+// GetDepartures returns the hotel's live bookings leaving on date, including
+// guests who have already checked out.
+func (r *BookingRepository) GetDepartures(hotelID int, date time.Time) ([]data.Booking, error) {
+	return r.getHotelDay(hotelID, date, `b.to_date = $2 AND b.status NOT IN (`+releasedStatuses+`)`)
+}
+
+func (r *BookingRepository) getHotelDay(hotelID int, date time.Time, condition string) ([]data.Booking, error) {
+	query := `
+		SELECT ` + bookingColumns + `
+		FROM bookings b
+		JOIN room_types t ON t.id = b.room_type_id
+		WHERE t.hotel_id = $1 AND ` + condition + `
+		ORDER BY b.id
+	`
+
+	rows, err := r.db.Query(query, hotelID, date)
+	if err != nil {
+		return nil, err
+	}
+
+	bookings, err := scanBookings(rows)
+	if bookings == nil && err == nil {
+		bookings = []data.Booking{}
+	}
+	return bookings, err
+}
+
+func (r *BookingRepository) GetUserBookings(userID int) ([]data.Booking, error) {
+	query := `
+		SELECT ` + bookingColumns + `
+		FROM bookings b
+		WHERE b.user_id = $1
+		ORDER BY b.created_at DESC
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
 	}
 
-	if err = rows.Err(); err != nil {
+	return scanBookings(rows)
+}
+
+func (r *BookingRepository) GetAllBookings() ([]data.Booking, error) {
+	query := `SELECT ` + bookingColumns + ` FROM bookings b`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
 		return nil, err
 	}
 
-	return bookings, nil
-}
\ No newline at end of file
+	return scanBookings(rows)
+}
@@ -0,0 +1,162 @@
+package repositories
+
+import (
+	"database/sql"
+
+	"hotel-booking-service/internal/data"
+)
+
+type CancellationPolicyRepository struct {
+	db *sql.DB
+}
+
+func NewCancellationPolicyRepository(db *sql.DB) *CancellationPolicyRepository {
+	return &CancellationPolicyRepository{db: db}
+}
+
+const cancellationPolicyColumns = `
+	id, hotel_id, room_type_id, name, free_cancellation_days,
+	penalty_type, penalty_percent, non_refundable, created_at`
+
+func scanCancellationPolicy(row rowScanner) (data.CancellationPolicy, error) {
+	var policy data.CancellationPolicy
+	var roomTypeID sql.NullInt64
+	err := row.Scan(
+		&policy.ID,
+		&policy.HotelID,
+		&roomTypeID,
+		&policy.Name,
+		&policy.FreeCancellationDays,
+		&policy.PenaltyType,
+		&policy.PenaltyPercent,
+		&policy.NonRefundable,
+		&policy.CreatedAt,
+	)
+	if roomTypeID.Valid {
+		id := int(roomTypeID.Int64)
+		policy.RoomTypeID = &id
+	}
+	return policy, err
+}
+
+func (r *CancellationPolicyRepository) GetByID(id int) (*data.CancellationPolicy, error) {
+	query := `SELECT ` + cancellationPolicyColumns + ` FROM cancellation_policies WHERE id = $1`
+
+	policy, err := scanCancellationPolicy(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+func (r *CancellationPolicyRepository) GetByHotelID(hotelID int) ([]data.CancellationPolicy, error) {
+	query := `
+		SELECT ` + cancellationPolicyColumns + `
+		FROM cancellation_policies
+		WHERE hotel_id = $1
+		ORDER BY room_type_id NULLS FIRST, id
+	`
+
+	rows, err := r.db.Query(query, hotelID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var policies []data.CancellationPolicy
+	for rows.Next() {
+		policy, err := scanCancellationPolicy(rows)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, policy)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return policies, nil
+}
+
+// FindForRoomType returns the room type's own policy, falling back to the
+// hotel default. It returns nil when neither is configured.
+func (r *CancellationPolicyRepository) FindForRoomType(hotelID, roomTypeID int) (*data.CancellationPolicy, error) {
+	query := `
+		SELECT ` + cancellationPolicyColumns + `
+		FROM cancellation_policies
+		WHERE hotel_id = $1 AND (room_type_id = $2 OR room_type_id IS NULL)
+		ORDER BY room_type_id NULLS LAST
+		LIMIT 1
+	`
+
+	policy, err := scanCancellationPolicy(r.db.QueryRow(query, hotelID, roomTypeID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+func (r *CancellationPolicyRepository) Create(policy *data.CancellationPolicy) (*data.CancellationPolicy, error) {
+	query := `
+		INSERT INTO cancellation_policies (
+			hotel_id, room_type_id, name, free_cancellation_days,
+			penalty_type, penalty_percent, non_refundable
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRow(
+		query,
+		policy.HotelID,
+		policy.RoomTypeID,
+		policy.Name,
+		policy.FreeCancellationDays,
+		policy.PenaltyType,
+		policy.PenaltyPercent,
+		policy.NonRefundable,
+	).Scan(&policy.ID, &policy.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+func (r *CancellationPolicyRepository) Update(policy *data.CancellationPolicy) (*data.CancellationPolicy, error) {
+	query := `
+		UPDATE cancellation_policies
+		SET name = $1, free_cancellation_days = $2, penalty_type = $3,
+			penalty_percent = $4, non_refundable = $5
+		WHERE id = $6
+	`
+
+	_, err := r.db.Exec(
+		query,
+		policy.Name,
+		policy.FreeCancellationDays,
+		policy.PenaltyType,
+		policy.PenaltyPercent,
+		policy.NonRefundable,
+		policy.ID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+func (r *CancellationPolicyRepository) Delete(id int) error {
+	_, err := r.db.Exec(`DELETE FROM cancellation_policies WHERE id = $1`, id)
+	return err
+}
@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"database/sql"
+
+	"hotel-booking-service/internal/data"
+)
+
+type ExchangeRateRepository struct {
+	db *sql.DB
+}
+
+func NewExchangeRateRepository(db *sql.DB) *ExchangeRateRepository {
+	return &ExchangeRateRepository{db: db}
+}
+
+func (r *ExchangeRateRepository) GetAll() ([]data.ExchangeRate, error) {
+	query := `
+		SELECT base_currency, quote_currency, rate, updated_at
+		FROM exchange_rates
+		ORDER BY base_currency, quote_currency
+	`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rates []data.ExchangeRate
+	for rows.Next() {
+		var rate data.ExchangeRate
+		if err := rows.Scan(&rate.BaseCurrency, &rate.QuoteCurrency, &rate.Rate, &rate.UpdatedAt); err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return rates, nil
+}
+
+func (r *ExchangeRateRepository) Get(base, quote string) (*data.ExchangeRate, error) {
+	query := `
+		SELECT base_currency, quote_currency, rate, updated_at
+		FROM exchange_rates
+		WHERE base_currency = $1 AND quote_currency = $2
+	`
+
+	var rate data.ExchangeRate
+	err := r.db.QueryRow(query, base, quote).Scan(&rate.BaseCurrency, &rate.QuoteCurrency, &rate.Rate, &rate.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &rate, nil
+}
+
+// Upsert loads a batch of rates in one transaction so a partial upload never
+// leaves a mix of old and new rates.
+func (r *ExchangeRateRepository) Upsert(rates []data.ExchangeRate) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO exchange_rates (base_currency, quote_currency, rate, updated_at)
+		VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
+		ON CONFLICT (base_currency, quote_currency)
+		DO UPDATE SET rate = EXCLUDED.rate, updated_at = EXCLUDED.updated_at
+	`
+
+	for _, rate := range rates {
+		if _, err := tx.Exec(query, rate.BaseCurrency, rate.QuoteCurrency, rate.Rate); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *ExchangeRateRepository) Delete(base, quote string) error {
+	_, err := r.db.Exec(`DELETE FROM exchange_rates WHERE base_currency = $1 AND quote_currency = $2`, base, quote)
+	return err
+}
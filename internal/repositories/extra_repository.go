@@ -0,0 +1,362 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+
+	"hotel-booking-service/internal/data"
+)
+
+type ExtraRepository struct {
+	db *sql.DB
+}
+
+func NewExtraRepository(db *sql.DB) *ExtraRepository {
+	return &ExtraRepository{db: db}
+}
+
+const extraColumns = `x.id, x.hotel_id, x.name, x.description, x.pricing, x.price_amount, h.currency, x.daily_inventory, x.active, x.created_at`
+
+func scanExtra(row rowScanner) (data.Extra, error) {
+	var extra data.Extra
+	var inventory sql.NullInt64
+	err := row.Scan(
+		&extra.ID,
+		&extra.HotelID,
+		&extra.Name,
+		&extra.Description,
+		&extra.Pricing,
+		&extra.Price.Amount,
+		&extra.Price.Currency,
+		&inventory,
+		&extra.Active,
+		&extra.CreatedAt,
+	)
+	if inventory.Valid {
+		units := int(inventory.Int64)
+		extra.DailyInventory = &units
+	}
+	return extra, err
+}
+
+const bookingExtraColumns = `e.id, e.booking_id, e.extra_id, e.name, e.pricing, e.quantity, e.from_date, e.to_date,
+	e.unit_amount, e.total_amount, h.currency, e.created_at`
+
+const bookingExtraTables = `booking_extras e JOIN extras x ON x.id = e.extra_id JOIN hotels h ON h.id = x.hotel_id`
+
+func scanBookingExtra(row rowScanner) (data.BookingExtra, error) {
+	var line data.BookingExtra
+	var bookingID sql.NullInt64
+	err := row.Scan(
+		&line.ID,
+		&bookingID,
+		&line.ExtraID,
+		&line.Name,
+		&line.Pricing,
+		&line.Quantity,
+		&line.FromDate,
+		&line.ToDate,
+		&line.UnitPrice.Amount,
+		&line.Total.Amount,
+		&line.UnitPrice.Currency,
+		&line.CreatedAt,
+	)
+	line.BookingID = int(bookingID.Int64)
+	line.Total.Currency = line.UnitPrice.Currency
+	return line, err
+}
+
+// extraUsedUnits counts the units of extra x held on night n.night by live
+// bookings and by bookings still being made.
+const extraUsedUnits = `
+	(SELECT COALESCE(SUM(e.quantity), 0) FROM booking_extras e
+		LEFT JOIN bookings b ON b.id = e.booking_id
+		WHERE e.extra_id = x.id
+		AND (e.booking_id IS NULL OR b.status NOT IN (` + releasedStatuses + `))
+		AND e.from_date <= n.night AND e.to_date > n.night)`
+
+func (r *ExtraRepository) GetByID(id int) (*data.Extra, error) {
+	query := `SELECT ` + extraColumns + ` FROM extras x JOIN hotels h ON h.id = x.hotel_id WHERE x.id = $1`
+
+	extra, err := scanExtra(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &extra, nil
+}
+
+// GetByHotelID lists the hotel's extras, only those still sold unless
+// inactive ones are asked for too.
+func (r *ExtraRepository) GetByHotelID(hotelID int, includeInactive bool) ([]data.Extra, error) {
+	query := `
+		SELECT ` + extraColumns + `
+		FROM extras x JOIN hotels h ON h.id = x.hotel_id
+		WHERE x.hotel_id = $1 AND (x.active OR $2)
+		ORDER BY x.name, x.id
+	`
+
+	rows, err := r.db.Query(query, hotelID, includeInactive)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	extras := []data.Extra{}
+	for rows.Next() {
+		extra, err := scanExtra(rows)
+		if err != nil {
+			return nil, err
+		}
+		extras = append(extras, extra)
+	}
+
+	return extras, rows.Err()
+}
+
+// Remaining returns, for each of the hotel's extras with limited
+// inventory, the fewest units left on any night from fromDate up to, but not
+// including, toDate.
+func (r *ExtraRepository) Remaining(hotelID int, fromDate, toDate time.Time) (map[int]int, error) {
+	query := `
+		SELECT x.id, MIN(x.daily_inventory - ` + extraUsedUnits + `)
+		FROM extras x
+		CROSS JOIN generate_series($2::date, $3::date - 1, interval '1 day') AS n(night)
+		WHERE x.hotel_id = $1 AND x.daily_inventory IS NOT NULL
+		GROUP BY x.id
+	`
+
+	rows, err := r.db.Query(query, hotelID, fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	remaining := make(map[int]int)
+	for rows.Next() {
+		var id, units int
+		if err := rows.Scan(&id, &units); err != nil {
+			return nil, err
+		}
+		remaining[id] = units
+	}
+
+	return remaining, rows.Err()
+}
+
+func (r *ExtraRepository) Create(extra *data.Extra) (*data.Extra, error) {
+	query := `
+		INSERT INTO extras (hotel_id, name, description, pricing, price_amount, daily_inventory, active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+
+	var id int
+	err := r.db.QueryRow(
+		query,
+		extra.HotelID,
+		extra.Name,
+		extra.Description,
+		extra.Pricing,
+		extra.Price.Amount,
+		extra.DailyInventory,
+		extra.Active,
+	).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(id)
+}
+
+// Update changes an extra's terms. Bookings that have it keep the price
+// they were given.
+func (r *ExtraRepository) Update(extra *data.Extra) (*data.Extra, error) {
+	query := `
+		UPDATE extras
+		SET name = $1, description = $2, pricing = $3, price_amount = $4, daily_inventory = $5, active = $6
+		WHERE id = $7
+	`
+
+	result, err := r.db.Exec(
+		query,
+		extra.Name,
+		extra.Description,
+		extra.Pricing,
+		extra.Price.Amount,
+		extra.DailyInventory,
+		extra.Active,
+		extra.ID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if affected == 0 {
+		return nil, nil
+	}
+
+	return r.GetByID(extra.ID)
+}
+
+// Delete removes an extra that was never booked and reports whether it did.
+func (r *ExtraRepository) Delete(id int) (bool, error) {
+	result, err := r.db.Exec(
+		`DELETE FROM extras WHERE id = $1 AND NOT EXISTS (SELECT 1 FROM booking_extras WHERE extra_id = $1)`,
+		id,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// GetForBookings returns the extras of the given bookings, keyed by booking.
+func (r *ExtraRepository) GetForBookings(bookingIDs []int) (map[int][]data.BookingExtra, error) {
+	query := `SELECT ` + bookingExtraColumns + ` FROM ` + bookingExtraTables + `
+		WHERE e.booking_id = ANY($1)
+		ORDER BY e.booking_id, e.id`
+
+	rows, err := r.db.Query(query, pq.Array(bookingIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines := make(map[int][]data.BookingExtra)
+	for rows.Next() {
+		line, err := scanBookingExtra(rows)
+		if err != nil {
+			return nil, err
+		}
+		lines[line.BookingID] = append(lines[line.BookingID], line)
+	}
+
+	return lines, rows.Err()
+}
+
+// Hold takes the lines' units out of inventory, and returns nil if an extra
+// does not have enough left on one of the nights. The extras are locked so
+// that two bookings cannot take the last units. With a bookingID the lines
+// are added to that booking, or nil returned if it is no longer pending or
+// a payment for it has been opened, and its total goes up by theirs; the
+// quote shown in another currency no longer applies then and is dropped. Without one they are held for a booking still being made,
+// to be attached with Attach or given back with Release.
+func (r *ExtraRepository) Hold(bookingID int, lines []data.BookingExtra) ([]data.BookingExtra, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	extraIDs := make([]int, len(lines))
+	for i, line := range lines {
+		extraIDs[i] = line.ExtraID
+	}
+	if _, err := tx.Exec(`SELECT id FROM extras WHERE id = ANY($1) ORDER BY id FOR UPDATE`, pq.Array(extraIDs)); err != nil {
+		return nil, err
+	}
+
+	var booking sql.NullInt64
+	var added int64
+	if bookingID != 0 {
+		booking = sql.NullInt64{Int64: int64(bookingID), Valid: true}
+		for _, line := range lines {
+			added += line.Total.Amount
+		}
+
+		result, err := tx.Exec(`
+			UPDATE bookings
+			SET extras_amount = extras_amount + $1, total_amount = total_amount + $1,
+				display_currency = NULL, exchange_rate = NULL, display_total_amount = NULL
+			WHERE id = $2 AND status = 'pending'
+			AND NOT EXISTS (
+				SELECT 1 FROM payments p
+				WHERE p.booking_id = bookings.id AND p.status NOT IN ('failed', 'voided', 'refunded')
+			)
+		`, added, bookingID)
+		if err != nil {
+			return nil, err
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		if affected == 0 {
+			return nil, nil
+		}
+	}
+
+	held := make([]data.BookingExtra, 0, len(lines))
+	for _, line := range lines {
+		var short bool
+		err := tx.QueryRow(`
+			SELECT EXISTS (
+				SELECT 1 FROM extras x
+				CROSS JOIN generate_series($2::date, $3::date - 1, interval '1 day') AS n(night)
+				WHERE x.id = $1 AND x.daily_inventory IS NOT NULL
+				AND x.daily_inventory - `+extraUsedUnits+` < $4
+			)
+		`, line.ExtraID, line.FromDate, line.ToDate, line.Quantity).Scan(&short)
+		if err != nil {
+			return nil, err
+		}
+		if short {
+			return nil, nil
+		}
+
+		created := line
+		created.BookingID = bookingID
+		err = tx.QueryRow(`
+			INSERT INTO booking_extras (booking_id, extra_id, name, pricing, quantity, from_date, to_date, unit_amount, total_amount)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			RETURNING id, created_at
+		`,
+			booking,
+			line.ExtraID,
+			line.Name,
+			line.Pricing,
+			line.Quantity,
+			line.FromDate,
+			line.ToDate,
+			line.UnitPrice.Amount,
+			line.Total.Amount,
+		).Scan(&created.ID, &created.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		held = append(held, created)
+	}
+
+	return held, tx.Commit()
+}
+
+// Attach links extras held with Hold to the booking they were held for.
+func (r *ExtraRepository) Attach(lineIDs []int, bookingID int) error {
+	_, err := r.db.Exec(
+		`UPDATE booking_extras SET booking_id = $1 WHERE id = ANY($2) AND booking_id IS NULL`,
+		bookingID, pq.Array(lineIDs),
+	)
+	return err
+}
+
+// Release gives back extras held for a booking that was never made.
+func (r *ExtraRepository) Release(lineIDs []int) error {
+	_, err := r.db.Exec(`DELETE FROM booking_extras WHERE id = ANY($1) AND booking_id IS NULL`, pq.Array(lineIDs))
+	return err
+}
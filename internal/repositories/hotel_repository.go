@@ -14,10 +14,76 @@ func NewHotelRepository(db *sql.DB) *HotelRepository {
 	return &HotelRepository{db: db}
 }
 
-func (r *HotelRepository) GetAllHotels() ([]data.Hotel, error) {
-	query := `SELECT id, name, city FROM hotels`
+// hotelColumns and hotelTables select hotels along with the summary of
+// their published reviews.
+const (
+	hotelColumns = `h.id, h.name, h.city, h.currency, h.description, h.latitude, h.longitude, h.tax_rate_bp, h.no_show_cutoff_hour, h.version,
+		s.reviews, s.overall, s.cleanliness, s.location, s.service`
+	hotelTables = `hotels h LEFT JOIN (
+		SELECT hotel_id, COUNT(*) AS reviews,
+			ROUND(AVG((cleanliness + location + service) / 3.0), 2) AS overall,
+			ROUND(AVG(cleanliness), 2) AS cleanliness,
+			ROUND(AVG(location), 2) AS location,
+			ROUND(AVG(service), 2) AS service
+		FROM reviews
+		WHERE status = 'published'
+		GROUP BY hotel_id
+	) s ON s.hotel_id = h.id`
+)
+
+func scanHotel(row rowScanner) (data.Hotel, error) {
+	var hotel data.Hotel
+	var reviews sql.NullInt64
+	var overall, cleanliness, location, service sql.NullFloat64
+	var latitude, longitude sql.NullFloat64
+	err := row.Scan(
+		&hotel.ID,
+		&hotel.Name,
+		&hotel.City,
+		&hotel.Currency,
+		&hotel.Description,
+		&latitude,
+		&longitude,
+		&hotel.TaxRateBP,
+		&hotel.NoShowCutoffHour,
+		&hotel.Version,
+		&reviews,
+		&overall,
+		&cleanliness,
+		&location,
+		&service,
+	)
+	if reviews.Valid {
+		hotel.Rating = &data.HotelRating{
+			Overall:     overall.Float64,
+			Cleanliness: cleanliness.Float64,
+			Location:    location.Float64,
+			Service:     service.Float64,
+			Reviews:     int(reviews.Int64),
+		}
+	}
+	if latitude.Valid && longitude.Valid {
+		hotel.Latitude = &latitude.Float64
+		hotel.Longitude = &longitude.Float64
+	}
+	return hotel, err
+}
+
+// GetAllHotels lists hotels, best rated first when the filter asks for it.
+func (r *HotelRepository) GetAllHotels(filter data.HotelFilter) ([]data.Hotel, error) {
+	query := `SELECT ` + hotelColumns + ` FROM ` + hotelTables
+	args := []interface{}{}
+	if filter.MinRating > 0 {
+		args = append(args, filter.MinRating)
+		query += ` WHERE s.overall >= $1`
+	}
+	if filter.Sort == "rating" {
+		query += ` ORDER BY s.overall DESC NULLS LAST, s.reviews DESC NULLS LAST, h.id`
+	} else {
+		query += ` ORDER BY h.id`
+	}
 	
-	rows, err := r.db.Query(query)
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -25,12 +91,8 @@ func (r *HotelRepository) GetAllHotels() ([]data.Hotel, error) {
 	
 	var hotels []data.Hotel
 	for rows.Next() {
-		var hotel data.Hotel
-		if err := rows.Scan(
-			&hotel.ID,
-			&hotel.Name,
-			&hotel.City,
-		); err != nil {
+		hotel, err := scanHotel(rows)
+		if err != nil {
 			return nil, err
 		}
 		hotels = append(hotels, hotel)
@@ -44,15 +106,9 @@ func (r *HotelRepository) GetAllHotels() ([]data.Hotel, error) {
 }
 
 func (r *HotelRepository) GetByID(id int) (*data.Hotel, error) {
-	query := `SELECT id, name, city FROM hotels WHERE id = $1`
-	
-	var hotel data.Hotel
-	err := r.db.QueryRow(query, id).Scan(
-		&hotel.ID,
-		&hotel.Name,
-		&hotel.City,
-	)
+	query := `SELECT ` + hotelColumns + ` FROM ` + hotelTables + ` WHERE h.id = $1`
 	
+	hotel, err := scanHotel(r.db.QueryRow(query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -65,58 +121,59 @@ func (r *HotelRepository) GetByID(id int) (*data.Hotel, error) {
 
 func (r *HotelRepository) GetRoomsByHotelID(hotelID int) ([]data.Room, error) {
 	query := `
-		SELECT id, hotel_id, number, capacity, price
-		FROM rooms
-		WHERE hotel_id = $1
+		SELECT ` + roomColumns + `
+		FROM ` + roomTables + `
+		WHERE r.hotel_id = $1
 	`
 	
 	rows, err := r.db.Query(query, hotelID)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-	
-	var rooms []data.Room
-	for rows.Next() {
-		var room data.Room
-		if err := rows.Scan(
-			&room.ID,
-			&room.HotelID,
-			&room.Number,
-			&room.Capacity,
-			&room.Price,
-		); err != nil {
-			return nil, err
-		}
-		rooms = append(rooms, room)
-	}
-	
-	if err = rows.Err(); err != nil {
-		return nil, err
-	}
 	
-	return rooms, nil
+	return scanRooms(rows)
 }
 
 func (r *HotelRepository) CreateHotel(hotel data.Hotel) (*data.Hotel, error) {
-	query := `INSERT INTO hotels (name, city) VALUES ($1, $2) RETURNING id`
-	err := r.db.QueryRow(query, hotel.Name, hotel.City).Scan(&hotel.ID)
+	query := `INSERT INTO hotels (name, city, currency, description, latitude, longitude, tax_rate_bp, no_show_cutoff_hour) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, version`
+	err := r.db.QueryRow(query, hotel.Name, hotel.City, hotel.Currency, hotel.Description, hotel.Latitude, hotel.Longitude, hotel.TaxRateBP, hotel.NoShowCutoffHour).Scan(&hotel.ID, &hotel.Version)
 	if err != nil {
 		return nil, fmt.Errorf("could not insert hotel: %v", err)
 	}
 	return &hotel, nil
 }
 
+// UpdateHotel saves the hotel if it is still at hotel.Version and returns
+// it with its new version, or nil if it has changed or gone.
 func (r *HotelRepository) UpdateHotel(hotel data.Hotel) (*data.Hotel, error) {
-	query := `UPDATE hotels SET name=$1, city=$2 WHERE id=$3`
-	_, err := r.db.Exec(query, hotel.Name, hotel.City, hotel.ID)
+	query := `UPDATE hotels SET name=$1, city=$2, currency=$3, description=$4, latitude=$5, longitude=$6, tax_rate_bp=$7, no_show_cutoff_hour=$8 WHERE id=$9 AND version=$10 RETURNING version`
+	err := r.db.QueryRow(query, hotel.Name, hotel.City, hotel.Currency, hotel.Description, hotel.Latitude, hotel.Longitude, hotel.TaxRateBP, hotel.NoShowCutoffHour, hotel.ID, hotel.Version).Scan(&hotel.Version)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
 		return nil, err
 	}
 	return &hotel, nil
 }
 
-func (r *HotelRepository) DeleteHotel(id int) error {
-	_, err := r.db.Exec(`DELETE FROM hotels WHERE id = $1`, id)
-	return err
+func (r *HotelRepository) CountRoomTypes(hotelID int) (int, error) {
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM room_types WHERE hotel_id = $1`, hotelID).Scan(&count)
+	return count, err
+}
+
+// DeleteHotel deletes the hotel if it is still at version and reports
+// whether it did.
+func (r *HotelRepository) DeleteHotel(id, version int) (bool, error) {
+	result, err := r.db.Exec(`DELETE FROM hotels WHERE id = $1 AND version = $2`, id, version)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
 }
\ No newline at end of file
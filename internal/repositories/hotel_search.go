@@ -0,0 +1,306 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"html"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"hotel-booking-service/internal/data"
+	"hotel-booking-service/internal/pkg/geo"
+	"hotel-booking-service/internal/pkg/money"
+)
+
+// Currencies returns the currencies the hotels price their rooms in.
+func (r *HotelRepository) Currencies() ([]string, error) {
+	rows, err := r.db.Query(`SELECT DISTINCT currency FROM hotels ORDER BY currency`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var currencies []string
+	for rows.Next() {
+		var currency string
+		if err := rows.Scan(&currency); err != nil {
+			return nil, err
+		}
+		currencies = append(currencies, currency)
+	}
+
+	return currencies, rows.Err()
+}
+
+// Search returns a page of the hotels matching the search, each with its
+// matching room types, cheapest first, and the total number of matches.
+// factors converts each hotel currency's minor units into the search
+// currency's for price filters and sorting; hotels in a currency without a
+// factor cannot be compared and are left out of those searches. Without
+// factors prices are not compared. Amenities must all be had by the hotel or, for room amenities,
+// by the matching room type. Text searches match words by prefix and return a highlighted
+// snippet with each hotel. The hotels and their room types take two queries
+// whatever the page size.
+func (r *HotelRepository) Search(search data.HotelSearch, factors map[string]string) ([]data.Hotel, int, error) {
+	currencies := []string{}
+	rates := []string{}
+	for currency, factor := range factors {
+		currencies = append(currencies, currency)
+		rates = append(rates, factor)
+	}
+
+	args := []interface{}{search.FromDate, search.ToDate, search.Guests, pq.Array(currencies), pq.Array(rates)}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	matchWhere := []string{"t.capacity >= $3"}
+	if search.City != "" {
+		matchWhere = append(matchWhere, "LOWER(h.city) = LOWER("+arg(search.City)+")")
+	}
+
+	if len(search.Amenities) > 0 {
+		matchWhere = append(matchWhere, fmt.Sprintf(hasAmenities, arg(pq.Array(search.Amenities))))
+	}
+
+	// Prices are only compared with factors, and then only for hotels
+	// whose currency has one.
+	fxJoin := "LEFT JOIN fx ON fx.currency = h.currency"
+	if factors != nil {
+		fxJoin = "JOIN fx ON fx.currency = h.currency"
+	}
+
+	tsquery := ""
+	snippet := "''"
+	if search.Text != "" {
+		tsquery = "to_tsquery('english', " + arg(prefixQuery(search.Text)) + ")"
+		matchWhere = append(matchWhere, "h.search_vector @@ "+tsquery)
+		snippet = "ts_headline('english', h.name || ', ' || h.city || '. ' || h.description, " + tsquery + ", " + headlineOptions + ")"
+	}
+
+	// Searches around a point let the coordinates index rule out hotels
+	// outside a box around the circle before distances are worked out.
+	distance := "NULL::float8"
+	if search.Near != nil {
+		box := geo.BoundingBox(*search.Near, search.RadiusKm)
+		distance = fmt.Sprintf(haversineKm, arg(search.Near.Latitude), arg(search.Near.Longitude))
+		matchWhere = append(matchWhere, "h.latitude BETWEEN "+arg(box.MinLatitude)+" AND "+arg(box.MaxLatitude))
+		if box.MinLongitude <= box.MaxLongitude {
+			matchWhere = append(matchWhere, "h.longitude BETWEEN "+arg(box.MinLongitude)+" AND "+arg(box.MaxLongitude))
+		} else {
+			matchWhere = append(matchWhere, "(h.longitude >= "+arg(box.MinLongitude)+" OR h.longitude <= "+arg(box.MaxLongitude)+")")
+		}
+		matchWhere = append(matchWhere, distance+" <= "+arg(search.RadiusKm))
+	}
+
+	offerWhere := []string{"TRUE"}
+	if search.MinPrice != nil {
+		offerWhere = append(offerWhere, "price >= "+arg(search.MinPrice.Amount))
+	}
+	if search.MaxPrice != nil {
+		offerWhere = append(offerWhere, "price <= "+arg(search.MaxPrice.Amount))
+	}
+
+	hotelWhere := []string{"TRUE"}
+	if search.MinRating > 0 {
+		hotelWhere = append(hotelWhere, "s.overall >= "+arg(search.MinRating))
+	}
+
+	// matches are the room types that sleep the party and have a unit left
+	// on every night, with their rate in the search currency; offers sum
+	// them up per hotel.
+	base := `
+		WITH fx (currency, factor) AS (
+			SELECT * FROM unnest($4::text[], $5::numeric[])
+		),
+		matches AS (
+			SELECT * FROM (
+				SELECT t.id, t.hotel_id, t.price_amount,
+					ROUND(t.price_amount * fx.factor) AS price,
+					MIN(` + typeFreeUnits + `) AS units
+				FROM room_types t
+				JOIN hotels h ON h.id = t.hotel_id
+				` + fxJoin + `
+				CROSS JOIN generate_series($1::date, $2::date - 1, interval '1 day') AS n(night)
+				WHERE ` + strings.Join(matchWhere, " AND ") + `
+				GROUP BY t.id, t.hotel_id, t.price_amount, fx.factor
+			) f
+			WHERE units > 0
+		),
+		offers AS (
+			SELECT hotel_id, MIN(price_amount) AS lowest, MIN(price) AS lowest_price,
+				array_agg(id ORDER BY price_amount, id) AS type_ids,
+				array_agg(units ORDER BY price_amount, id) AS type_units
+			FROM matches
+			WHERE ` + strings.Join(offerWhere, " AND ") + `
+			GROUP BY hotel_id
+		)
+		SELECT ` + hotelColumns + `, o.lowest, o.type_ids, o.type_units, ` + snippet + `, ` + distance + `, COUNT(*) OVER ()
+		FROM ` + hotelTables + `
+		JOIN offers o ON o.hotel_id = h.id
+		WHERE ` + strings.Join(hotelWhere, " AND ")
+
+	query := base + ` ORDER BY ` + searchOrder(search, tsquery, distance) + ` LIMIT ` + arg(search.Limit) + ` OFFSET ` + arg(search.Offset)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	hotels := []data.Hotel{}
+	typeIDs := []int64{}
+	units := map[int64]int{}
+	total := 0
+	for rows.Next() {
+		var lowest int64
+		var ids, counts []int64
+		var snippet string
+		var distance sql.NullFloat64
+		hotel, err := scanHotel(searchRow{rows, []interface{}{&lowest, pq.Array(&ids), pq.Array(&counts), &snippet, &distance, &total}})
+		if err != nil {
+			return nil, 0, err
+		}
+		hotel.Snippet = markedSnippet(snippet)
+		if distance.Valid {
+			km := math.Round(distance.Float64*100) / 100
+			hotel.DistanceKm = &km
+		}
+
+		price := money.New(lowest, hotel.Currency)
+		hotel.LowestPrice = &price
+		for i, id := range ids {
+			typeIDs = append(typeIDs, id)
+			units[id] = int(counts[i])
+		}
+		hotels = append(hotels, hotel)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	if len(hotels) == 0 && search.Offset > 0 {
+		// The page is past the end, so the window count never came back.
+		countArgs := args[:len(args)-2]
+		if err := r.db.QueryRow(`SELECT COUNT(*) FROM (`+base+`) c`, countArgs...).Scan(&total); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	if len(typeIDs) == 0 {
+		return hotels, total, nil
+	}
+
+	roomTypes, err := r.roomTypesByID(typeIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	byHotel := map[int][]data.RoomType{}
+	for _, id := range typeIDs {
+		roomType, ok := roomTypes[id]
+		if !ok {
+			continue
+		}
+		available := units[id]
+		roomType.Available = &available
+		byHotel[roomType.HotelID] = append(byHotel[roomType.HotelID], roomType)
+	}
+	for i := range hotels {
+		hotels[i].RoomTypes = byHotel[hotels[i].ID]
+	}
+
+	return hotels, total, nil
+}
+
+func (r *HotelRepository) roomTypesByID(ids []int64) (map[int64]data.RoomType, error) {
+	query := `SELECT ` + roomTypeColumns + ` FROM room_types t JOIN hotels h ON h.id = t.hotel_id WHERE t.id = ANY($1)`
+
+	rows, err := r.db.Query(query, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roomTypes := map[int64]data.RoomType{}
+	for rows.Next() {
+		roomType, err := scanRoomType(rows)
+		if err != nil {
+			return nil, err
+		}
+		roomTypes[int64(roomType.ID)] = roomType
+	}
+
+	return roomTypes, rows.Err()
+}
+
+// searchOrder is the ORDER BY clause for the search's sort; tsquery is the
+// text search's query and distance the expression for the distance from
+// its point, if it has them. Ties go to the oldest hotel so that pages do
+// not overlap.
+func searchOrder(search data.HotelSearch, tsquery, distance string) string {
+	direction := "ASC"
+	if search.Order == "desc" {
+		direction = "DESC"
+	}
+
+	switch search.Sort {
+	case data.SearchSortPrice:
+		return "o.lowest_price " + direction + ", h.id"
+	case data.SearchSortRating:
+		return "s.overall " + direction + " NULLS LAST, s.reviews DESC NULLS LAST, h.id"
+	case data.SearchSortName:
+		return "h.name " + direction + ", h.id"
+	case data.SearchSortRelevance:
+		if tsquery != "" {
+			return "ts_rank(h.search_vector, " + tsquery + ") " + direction + ", h.id"
+		}
+	case data.SearchSortDistance:
+		if search.Near != nil {
+			return distance + " " + direction + ", h.id"
+		}
+	}
+	return "h.id"
+}
+
+// haversineKm is the great-circle distance in kilometres from a hotel to
+// the point whose latitude and longitude placeholders fill it in.
+var haversineKm = `(2 * ` + strconv.FormatFloat(geo.EarthRadiusKm, 'f', -1, 64) + ` * asin(sqrt(LEAST(1,
+	power(sin(radians(h.latitude - %[1]s) / 2), 2) +
+	cos(radians(%[1]s)) * cos(radians(h.latitude)) * power(sin(radians(h.longitude - %[2]s) / 2), 2)))))`
+
+// headlineOptions mark the matched words in snippets and keep them to a
+// sentence or two.
+const headlineOptions = `'StartSel=<mark>, StopSel=</mark>, MinWords=10, MaxWords=30, MaxFragments=2, FragmentDelimiter=" … "'`
+
+// prefixQuery turns search words into a tsquery that needs all of them,
+// each as a whole word or the start of one. The words must be letters and
+// digits only.
+func prefixQuery(text string) string {
+	terms := strings.Fields(text)
+	for i, term := range terms {
+		terms[i] = term + ":*"
+	}
+	return strings.Join(terms, " & ")
+}
+
+// markedSnippet escapes a headline for HTML, keeping its <mark> tags.
+func markedSnippet(headline string) string {
+	escaped := html.EscapeString(headline)
+	escaped = strings.ReplaceAll(escaped, "&lt;mark&gt;", "<mark>")
+	return strings.ReplaceAll(escaped, "&lt;/mark&gt;", "</mark>")
+}
+
+// searchRow scans a hotel row followed by extra search columns.
+type searchRow struct {
+	row   rowScanner
+	extra []interface{}
+}
+
+func (s searchRow) Scan(dest ...interface{}) error {
+	return s.row.Scan(append(dest, s.extra...)...)
+}
@@ -0,0 +1,112 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"hotel-booking-service/internal/data"
+)
+
+type IdempotencyRepository struct {
+	db *sql.DB
+}
+
+func NewIdempotencyRepository(db *sql.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+const idempotencyColumns = `id, scope, idempotency_key, fingerprint, status_code, content_type, response_body, created_at`
+
+func scanIdempotencyRecord(row rowScanner) (data.IdempotencyRecord, error) {
+	var record data.IdempotencyRecord
+	var statusCode sql.NullInt64
+	err := row.Scan(
+		&record.ID,
+		&record.Scope,
+		&record.Key,
+		&record.Fingerprint,
+		&statusCode,
+		&record.ContentType,
+		&record.ResponseBody,
+		&record.CreatedAt,
+	)
+	record.StatusCode = int(statusCode.Int64)
+	return record, err
+}
+
+// Reserve claims key for a request with the given fingerprint. It returns
+// the new record and true, or the record left by an earlier request with
+// the same key and false. A reservation for the same request that has gone
+// unfinished for longer than lease is taken over, since the request that
+// made it is taken to have died with its server.
+func (r *IdempotencyRepository) Reserve(scope, key, fingerprint string, lease time.Duration) (*data.IdempotencyRecord, bool, error) {
+	query := `
+		INSERT INTO idempotency_keys (scope, idempotency_key, fingerprint)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (scope, idempotency_key) DO NOTHING
+		RETURNING ` + idempotencyColumns
+
+	record, err := scanIdempotencyRecord(r.db.QueryRow(query, scope, key, fingerprint))
+	if err == nil {
+		return &record, true, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, false, err
+	}
+
+	query = `
+		UPDATE idempotency_keys SET reserved_at = CURRENT_TIMESTAMP
+		WHERE scope = $1 AND idempotency_key = $2 AND fingerprint = $3 AND status_code IS NULL
+		AND reserved_at < CURRENT_TIMESTAMP - $4::int * INTERVAL '1 second'
+		RETURNING ` + idempotencyColumns
+
+	record, err = scanIdempotencyRecord(r.db.QueryRow(query, scope, key, fingerprint, int(lease.Seconds())))
+	if err == nil {
+		return &record, true, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, false, err
+	}
+
+	query = `SELECT ` + idempotencyColumns + ` FROM idempotency_keys WHERE scope = $1 AND idempotency_key = $2`
+
+	record, err = scanIdempotencyRecord(r.db.QueryRow(query, scope, key))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			// Released between the insert and the select; the caller may
+			// retry.
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	return &record, false, nil
+}
+
+// Complete stores the response to replay for the reserved key.
+func (r *IdempotencyRepository) Complete(id, statusCode int, contentType string, body []byte) error {
+	query := `
+		UPDATE idempotency_keys
+		SET status_code = $1, content_type = $2, response_body = $3
+		WHERE id = $4
+	`
+
+	_, err := r.db.Exec(query, statusCode, contentType, body, id)
+	return err
+}
+
+// Release forgets a reserved key so that the request can be tried again.
+func (r *IdempotencyRepository) Release(id int) error {
+	_, err := r.db.Exec(`DELETE FROM idempotency_keys WHERE id = $1`, id)
+	return err
+}
+
+// DeleteOlderThan forgets keys first used before cutoff and returns how
+// many were removed.
+func (r *IdempotencyRepository) DeleteOlderThan(cutoff time.Time) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM idempotency_keys WHERE created_at < $1`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,155 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"hotel-booking-service/internal/data"
+)
+
+type InvoiceRepository struct {
+	db *sql.DB
+}
+
+func NewInvoiceRepository(db *sql.DB) *InvoiceRepository {
+	return &InvoiceRepository{db: db}
+}
+
+var invoicePrefixes = map[string]string{
+	data.InvoiceKindInvoice:    "INV",
+	data.InvoiceKindCreditNote: "CN",
+}
+
+func scanInvoice(row rowScanner) (data.Invoice, error) {
+	var invoice data.Invoice
+	var id int
+	var document []byte
+	if err := row.Scan(&id, &document); err != nil {
+		return invoice, err
+	}
+
+	if err := json.Unmarshal(document, &invoice); err != nil {
+		return invoice, err
+	}
+	invoice.ID = id
+	return invoice, nil
+}
+
+// Issue numbers and stores a new invoice or credit note. Numbers run per
+// hotel and kind without gaps: the sequence row stays locked until the
+// invoice is committed, and a failed insert rolls the number back.
+func (r *InvoiceRepository) Issue(invoice *data.Invoice) (*data.Invoice, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	sequenceQuery := `
+		INSERT INTO invoice_sequences (hotel_id, kind, last_number)
+		VALUES ($1, $2, 1)
+		ON CONFLICT (hotel_id, kind)
+		DO UPDATE SET last_number = invoice_sequences.last_number + 1
+		RETURNING last_number
+	`
+
+	var sequence int
+	if err := tx.QueryRow(sequenceQuery, invoice.HotelID, invoice.Kind).Scan(&sequence); err != nil {
+		return nil, err
+	}
+
+	issued := *invoice
+	issued.Number = fmt.Sprintf("%s-%d-%06d", invoicePrefixes[invoice.Kind], invoice.HotelID, sequence)
+
+	document, err := json.Marshal(issued)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO invoices (
+			hotel_id, booking_id, kind, sequence_number, number, credited_invoice_id,
+			currency, total_amount, document, issued_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id
+	`
+
+	err = tx.QueryRow(
+		query,
+		issued.HotelID,
+		issued.BookingID,
+		issued.Kind,
+		sequence,
+		issued.Number,
+		issued.CreditedInvoiceID,
+		issued.Total.Currency,
+		issued.Total.Amount,
+		string(document),
+		issued.IssuedAt,
+	).Scan(&issued.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &issued, nil
+}
+
+func (r *InvoiceRepository) GetByID(id int) (*data.Invoice, error) {
+	query := `SELECT id, document FROM invoices WHERE id = $1`
+
+	invoice, err := scanInvoice(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &invoice, nil
+}
+
+func (r *InvoiceRepository) GetForBooking(bookingID int) (*data.Invoice, error) {
+	query := `SELECT id, document FROM invoices WHERE booking_id = $1 AND kind = 'invoice'`
+
+	invoice, err := scanInvoice(r.db.QueryRow(query, bookingID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &invoice, nil
+}
+
+func (r *InvoiceRepository) GetCreditNotes(invoiceID int) ([]data.Invoice, error) {
+	query := `
+		SELECT id, document
+		FROM invoices
+		WHERE credited_invoice_id = $1
+		ORDER BY issued_at, id
+	`
+
+	rows, err := r.db.Query(query, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notes := []data.Invoice{}
+	for rows.Next() {
+		note, err := scanInvoice(rows)
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, note)
+	}
+
+	return notes, rows.Err()
+}
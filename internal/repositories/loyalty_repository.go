@@ -0,0 +1,206 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"hotel-booking-service/internal/data"
+)
+
+type LoyaltyRepository struct {
+	db *sql.DB
+}
+
+func NewLoyaltyRepository(db *sql.DB) *LoyaltyRepository {
+	return &LoyaltyRepository{db: db}
+}
+
+const loyaltyEntryColumns = `e.id, e.user_id, e.booking_id, e.kind, e.points, e.description, e.reverses_entry_id, e.created_at`
+
+func scanLoyaltyEntry(row rowScanner) (data.LoyaltyEntry, error) {
+	var entry data.LoyaltyEntry
+	var bookingID, reverses sql.NullInt64
+	err := row.Scan(
+		&entry.ID,
+		&entry.UserID,
+		&bookingID,
+		&entry.Kind,
+		&entry.Points,
+		&entry.Description,
+		&reverses,
+		&entry.CreatedAt,
+	)
+	entry.BookingID = int(bookingID.Int64)
+	entry.ReversesEntryID = int(reverses.Int64)
+	return entry, err
+}
+
+// GetForUser returns the member's ledger, newest first.
+func (r *LoyaltyRepository) GetForUser(userID int) ([]data.LoyaltyEntry, error) {
+	query := `SELECT ` + loyaltyEntryColumns + ` FROM loyalty_entries e WHERE e.user_id = $1 ORDER BY e.created_at DESC, e.id DESC`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []data.LoyaltyEntry{}
+	for rows.Next() {
+		entry, err := scanLoyaltyEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+func (r *LoyaltyRepository) Balance(userID int) (int, error) {
+	var balance int
+	err := r.db.QueryRow(`SELECT COALESCE(SUM(points), 0) FROM loyalty_entries WHERE user_id = $1`, userID).Scan(&balance)
+	return balance, err
+}
+
+// QualifyingPoints returns the points the member earned since the given
+// time, less earnings that were reversed.
+func (r *LoyaltyRepository) QualifyingPoints(userID int, since time.Time) (int, error) {
+	query := `
+		SELECT COALESCE(SUM(e.points), 0)
+		FROM loyalty_entries e
+		LEFT JOIN loyalty_entries reversed ON reversed.id = e.reverses_entry_id
+		WHERE e.user_id = $1 AND e.created_at >= $2
+		AND (e.kind = 'earn' OR reversed.kind = 'earn')
+	`
+
+	var points int
+	err := r.db.QueryRow(query, userID, since).Scan(&points)
+	return points, err
+}
+
+// LastActivity returns when the member last earned, spent or got back
+// points, or nil if they never did.
+func (r *LoyaltyRepository) LastActivity(userID int) (*time.Time, error) {
+	var last sql.NullTime
+	err := r.db.QueryRow(`SELECT MAX(created_at) FROM loyalty_entries WHERE user_id = $1 AND kind <> 'expiry'`, userID).Scan(&last)
+	if err != nil || !last.Valid {
+		return nil, err
+	}
+	return &last.Time, nil
+}
+
+// Earn credits points for a stay. A stay earns once; it returns nil if the
+// booking already earned.
+func (r *LoyaltyRepository) Earn(userID, bookingID, points int, description string) (*data.LoyaltyEntry, error) {
+	query := `
+		INSERT INTO loyalty_entries AS e (user_id, booking_id, kind, points, description)
+		VALUES ($1, $2, 'earn', $3, $4)
+		ON CONFLICT (booking_id) WHERE kind = 'earn' DO NOTHING
+		RETURNING ` + loyaltyEntryColumns
+
+	entry, err := scanLoyaltyEntry(r.db.QueryRow(query, userID, bookingID, points, description))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// Redeem spends points if the member has enough, and returns nil if not.
+// The member's row is locked so that two bookings cannot spend the same
+// points. The entry is linked to its booking with AttachBooking once the
+// booking exists.
+func (r *LoyaltyRepository) Redeem(userID, points int, description string) (*data.LoyaltyEntry, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT id FROM users WHERE id = $1 FOR UPDATE`, userID); err != nil {
+		return nil, err
+	}
+
+	var balance int
+	err = tx.QueryRow(`SELECT COALESCE(SUM(points), 0) FROM loyalty_entries WHERE user_id = $1`, userID).Scan(&balance)
+	if err != nil {
+		return nil, err
+	}
+	if balance < points {
+		return nil, nil
+	}
+
+	query := `
+		INSERT INTO loyalty_entries AS e (user_id, kind, points, description)
+		VALUES ($1, 'redeem', $2, $3)
+		RETURNING ` + loyaltyEntryColumns
+
+	entry, err := scanLoyaltyEntry(tx.QueryRow(query, userID, -points, description))
+	if err != nil {
+		return nil, err
+	}
+
+	return &entry, tx.Commit()
+}
+
+func (r *LoyaltyRepository) AttachBooking(entryID, bookingID int) error {
+	_, err := r.db.Exec(`UPDATE loyalty_entries SET booking_id = $1 WHERE id = $2`, bookingID, entryID)
+	return err
+}
+
+// Release gives back points redeemed for a booking that was never made.
+func (r *LoyaltyRepository) Release(entryID int) error {
+	_, err := r.db.Exec(`DELETE FROM loyalty_entries WHERE id = $1 AND kind = 'redeem' AND booking_id IS NULL`, entryID)
+	return err
+}
+
+// ReverseBooking undoes every earning and redemption of the booking that is
+// not undone yet, and returns the reversal entries.
+func (r *LoyaltyRepository) ReverseBooking(bookingID int, description string) ([]data.LoyaltyEntry, error) {
+	query := `
+		INSERT INTO loyalty_entries AS e (user_id, booking_id, kind, points, description, reverses_entry_id)
+		SELECT o.user_id, o.booking_id, 'reversal', -o.points, $2, o.id
+		FROM loyalty_entries o
+		WHERE o.booking_id = $1 AND o.kind IN ('earn', 'redeem')
+		ON CONFLICT (reverses_entry_id) DO NOTHING
+		RETURNING ` + loyaltyEntryColumns
+
+	rows, err := r.db.Query(query, bookingID, description)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []data.LoyaltyEntry{}
+	for rows.Next() {
+		entry, err := scanLoyaltyEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// ExpireInactive zeroes the balances of members with no loyalty activity
+// since cutoff and returns how many were expired.
+func (r *LoyaltyRepository) ExpireInactive(cutoff time.Time, description string) (int64, error) {
+	query := `
+		INSERT INTO loyalty_entries (user_id, kind, points, description)
+		SELECT user_id, 'expiry', -SUM(points), $2
+		FROM loyalty_entries
+		GROUP BY user_id
+		HAVING SUM(points) > 0 AND MAX(created_at) < $1
+	`
+
+	result, err := r.db.Exec(query, cutoff, description)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
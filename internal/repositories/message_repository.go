@@ -0,0 +1,253 @@
+package repositories
+
+import (
+	"database/sql"
+
+	"github.com/lib/pq"
+
+	"hotel-booking-service/internal/data"
+)
+
+type MessageRepository struct {
+	db *sql.DB
+}
+
+func NewMessageRepository(db *sql.DB) *MessageRepository {
+	return &MessageRepository{db: db}
+}
+
+// Hotel messages are signed with the hotel's name rather than the staff
+// member's; guest messages with the guest's first name.
+const messageColumns = `m.id, m.booking_id, COALESCE(m.sender_id, 0),
+	CASE WHEN m.from_party = 'hotel' THEN h.name
+		ELSE COALESCE(NULLIF(split_part(u.name, ' ', 1), ''), 'Guest') END,
+	m.from_party, m.body, m.read_at, m.created_at`
+
+const messageTables = `booking_messages m
+	JOIN bookings b ON b.id = m.booking_id
+	JOIN room_types t ON t.id = b.room_type_id
+	JOIN hotels h ON h.id = t.hotel_id
+	LEFT JOIN users u ON u.id = m.sender_id`
+
+const attachmentColumns = `a.id, a.message_id, a.filename, a.content_type, a.size_bytes, a.storage_key, a.created_at`
+
+func scanMessage(row rowScanner) (data.BookingMessage, error) {
+	var message data.BookingMessage
+	var readAt sql.NullTime
+	err := row.Scan(
+		&message.ID,
+		&message.BookingID,
+		&message.SenderID,
+		&message.SenderName,
+		&message.From,
+		&message.Body,
+		&readAt,
+		&message.CreatedAt,
+	)
+	if readAt.Valid {
+		message.ReadAt = &readAt.Time
+	}
+	return message, err
+}
+
+func scanAttachment(row rowScanner) (data.MessageAttachment, error) {
+	var attachment data.MessageAttachment
+	err := row.Scan(
+		&attachment.ID,
+		&attachment.MessageID,
+		&attachment.Filename,
+		&attachment.ContentType,
+		&attachment.Size,
+		&attachment.StorageKey,
+		&attachment.CreatedAt,
+	)
+	return attachment, err
+}
+
+// Create stores a message together with its attachments, whose files must
+// already be in the file store.
+func (r *MessageRepository) Create(message *data.BookingMessage, attachments []data.MessageAttachment) (*data.BookingMessage, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var id int
+	err = tx.QueryRow(`
+		INSERT INTO booking_messages (booking_id, sender_id, from_party, body)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`, message.BookingID, message.SenderID, message.From, message.Body).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, attachment := range attachments {
+		_, err := tx.Exec(`
+			INSERT INTO message_attachments (message_id, filename, content_type, size_bytes, storage_key)
+			VALUES ($1, $2, $3, $4, $5)
+		`, id, attachment.Filename, attachment.ContentType, attachment.Size, attachment.StorageKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(id)
+}
+
+func (r *MessageRepository) GetByID(id int) (*data.BookingMessage, error) {
+	query := `SELECT ` + messageColumns + ` FROM ` + messageTables + ` WHERE m.id = $1`
+
+	message, err := scanMessage(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	attachments, err := r.attachmentsFor([]int{id})
+	if err != nil {
+		return nil, err
+	}
+	message.Attachments = attachments[id]
+
+	return &message, nil
+}
+
+// GetForBooking returns a booking's messages, oldest first.
+func (r *MessageRepository) GetForBooking(bookingID int) ([]data.BookingMessage, error) {
+	query := `SELECT ` + messageColumns + ` FROM ` + messageTables + `
+		WHERE m.booking_id = $1
+		ORDER BY m.created_at, m.id`
+
+	rows, err := r.db.Query(query, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := []data.BookingMessage{}
+	ids := []int{}
+	for rows.Next() {
+		message, err := scanMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+		ids = append(ids, message.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	attachments, err := r.attachmentsFor(ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range messages {
+		messages[i].Attachments = attachments[messages[i].ID]
+	}
+
+	return messages, nil
+}
+
+func (r *MessageRepository) attachmentsFor(messageIDs []int) (map[int][]data.MessageAttachment, error) {
+	attachments := make(map[int][]data.MessageAttachment)
+	if len(messageIDs) == 0 {
+		return attachments, nil
+	}
+
+	rows, err := r.db.Query(`SELECT `+attachmentColumns+` FROM message_attachments a
+		WHERE a.message_id = ANY($1)
+		ORDER BY a.message_id, a.id`, pq.Array(messageIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		attachments[attachment.MessageID] = append(attachments[attachment.MessageID], attachment)
+	}
+
+	return attachments, rows.Err()
+}
+
+// MarkRead marks the booking's unread messages from one side as read and
+// returns how many there were.
+func (r *MessageRepository) MarkRead(bookingID int, from string) (int, error) {
+	result, err := r.db.Exec(`
+		UPDATE booking_messages SET read_at = NOW()
+		WHERE booking_id = $1 AND from_party = $2 AND read_at IS NULL
+	`, bookingID, from)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := result.RowsAffected()
+	return int(affected), err
+}
+
+// GetAttachment returns an attachment sent on the booking, or nil if there
+// is no such attachment on it.
+func (r *MessageRepository) GetAttachment(bookingID, attachmentID int) (*data.MessageAttachment, error) {
+	query := `SELECT ` + attachmentColumns + ` FROM message_attachments a
+		JOIN booking_messages m ON m.id = a.message_id
+		WHERE a.id = $1 AND m.booking_id = $2`
+
+	attachment, err := scanAttachment(r.db.QueryRow(query, attachmentID, bookingID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// UnreadForHotel returns the hotel's bookings with guest messages it has not
+// read, the longest waiting first.
+func (r *MessageRepository) UnreadForHotel(hotelID int) ([]data.UnreadThread, error) {
+	query := `
+		SELECT b.id, b.confirmation_code, COALESCE(u.name, ''), COUNT(*), MAX(m.created_at)
+		FROM booking_messages m
+		JOIN bookings b ON b.id = m.booking_id
+		JOIN room_types t ON t.id = b.room_type_id
+		LEFT JOIN users u ON u.id = b.user_id
+		WHERE t.hotel_id = $1 AND m.from_party = 'guest' AND m.read_at IS NULL
+		GROUP BY b.id, b.confirmation_code, u.name
+		ORDER BY MIN(m.created_at)
+	`
+
+	rows, err := r.db.Query(query, hotelID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	threads := []data.UnreadThread{}
+	for rows.Next() {
+		var thread data.UnreadThread
+		if err := rows.Scan(
+			&thread.BookingID,
+			&thread.ConfirmationCode,
+			&thread.GuestName,
+			&thread.Unread,
+			&thread.LastMessageAt,
+		); err != nil {
+			return nil, err
+		}
+		threads = append(threads, thread)
+	}
+
+	return threads, rows.Err()
+}
@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"hotel-booking-service/internal/data"
+)
+
+type OverbookingRepository struct {
+	db *sql.DB
+}
+
+func NewOverbookingRepository(db *sql.DB) *OverbookingRepository {
+	return &OverbookingRepository{db: db}
+}
+
+const allowanceColumns = `id, hotel_id, from_date, to_date, percent, created_at`
+
+func scanAllowance(row rowScanner) (data.OverbookingAllowance, error) {
+	var allowance data.OverbookingAllowance
+	err := row.Scan(
+		&allowance.ID,
+		&allowance.HotelID,
+		&allowance.FromDate,
+		&allowance.ToDate,
+		&allowance.Percent,
+		&allowance.CreatedAt,
+	)
+	return allowance, err
+}
+
+func (r *OverbookingRepository) GetByHotelID(hotelID int) ([]data.OverbookingAllowance, error) {
+	query := `
+		SELECT ` + allowanceColumns + `
+		FROM overbooking_allowances
+		WHERE hotel_id = $1
+		ORDER BY from_date, id
+	`
+
+	return r.queryAllowances(query, hotelID)
+}
+
+// GetForRange returns the allowances that cover any night from fromDate up
+// to, but not including, toDate.
+func (r *OverbookingRepository) GetForRange(hotelID int, fromDate, toDate time.Time) ([]data.OverbookingAllowance, error) {
+	query := `
+		SELECT ` + allowanceColumns + `
+		FROM overbooking_allowances
+		WHERE hotel_id = $1 AND from_date < $3 AND to_date >= $2
+	`
+
+	return r.queryAllowances(query, hotelID, fromDate, toDate)
+}
+
+func (r *OverbookingRepository) queryAllowances(query string, args ...interface{}) ([]data.OverbookingAllowance, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	allowances := []data.OverbookingAllowance{}
+	for rows.Next() {
+		allowance, err := scanAllowance(rows)
+		if err != nil {
+			return nil, err
+		}
+		allowances = append(allowances, allowance)
+	}
+
+	return allowances, rows.Err()
+}
+
+func (r *OverbookingRepository) Create(allowance *data.OverbookingAllowance) (*data.OverbookingAllowance, error) {
+	query := `
+		INSERT INTO overbooking_allowances (hotel_id, from_date, to_date, percent)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + allowanceColumns
+
+	created, err := scanAllowance(r.db.QueryRow(query, allowance.HotelID, allowance.FromDate, allowance.ToDate, allowance.Percent))
+	if err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+func (r *OverbookingRepository) Delete(id int) error {
+	_, err := r.db.Exec(`DELETE FROM overbooking_allowances WHERE id = $1`, id)
+	return err
+}
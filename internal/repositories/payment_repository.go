@@ -0,0 +1,172 @@
+package repositories
+
+import (
+	"database/sql"
+
+	"hotel-booking-service/internal/data"
+)
+
+type PaymentRepository struct {
+	db *sql.DB
+}
+
+func NewPaymentRepository(db *sql.DB) *PaymentRepository {
+	return &PaymentRepository{db: db}
+}
+
+const paymentColumns = `
+	id, booking_id, provider, provider_ref, amount, captured_amount, refunded_amount,
+	currency, status, COALESCE(confirm_url, ''), created_at, updated_at`
+
+func scanPayment(row rowScanner) (data.Payment, error) {
+	var payment data.Payment
+	var currency string
+	err := row.Scan(
+		&payment.ID,
+		&payment.BookingID,
+		&payment.Provider,
+		&payment.ProviderRef,
+		&payment.Amount.Amount,
+		&payment.Captured.Amount,
+		&payment.Refunded.Amount,
+		&currency,
+		&payment.Status,
+		&payment.ConfirmURL,
+		&payment.CreatedAt,
+		&payment.UpdatedAt,
+	)
+	payment.Amount.Currency = currency
+	payment.Captured.Currency = currency
+	payment.Refunded.Currency = currency
+	return payment, err
+}
+
+func (r *PaymentRepository) Create(payment *data.Payment) (*data.Payment, error) {
+	query := `
+		INSERT INTO payments (booking_id, provider, provider_ref, amount, currency, status, confirm_url)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + paymentColumns
+
+	created, err := scanPayment(r.db.QueryRow(
+		query,
+		payment.BookingID,
+		payment.Provider,
+		payment.ProviderRef,
+		payment.Amount.Amount,
+		payment.Amount.Currency,
+		payment.Status,
+		payment.ConfirmURL,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+func (r *PaymentRepository) GetByID(id int) (*data.Payment, error) {
+	query := `SELECT ` + paymentColumns + ` FROM payments WHERE id = $1`
+
+	payment, err := scanPayment(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &payment, nil
+}
+
+func (r *PaymentRepository) GetByProviderRef(provider, ref string) (*data.Payment, error) {
+	query := `SELECT ` + paymentColumns + ` FROM payments WHERE provider = $1 AND provider_ref = $2`
+
+	payment, err := scanPayment(r.db.QueryRow(query, provider, ref))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &payment, nil
+}
+
+func (r *PaymentRepository) GetByBookingID(bookingID int) ([]data.Payment, error) {
+	query := `SELECT ` + paymentColumns + ` FROM payments WHERE booking_id = $1 ORDER BY created_at DESC, id DESC`
+
+	rows, err := r.db.Query(query, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payments []data.Payment
+	for rows.Next() {
+		payment, err := scanPayment(rows)
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, payment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return payments, nil
+}
+
+// GetOpenForBooking returns the booking's most recent payment that still
+// holds or owes money, i.e. one that is neither failed nor voided.
+func (r *PaymentRepository) GetOpenForBooking(bookingID int) (*data.Payment, error) {
+	query := `
+		SELECT ` + paymentColumns + `
+		FROM payments
+		WHERE booking_id = $1 AND status NOT IN ('failed', 'voided', 'refunded')
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1
+	`
+
+	payment, err := scanPayment(r.db.QueryRow(query, bookingID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &payment, nil
+}
+
+func (r *PaymentRepository) UpdateState(payment *data.Payment) error {
+	query := `
+		UPDATE payments
+		SET status = $1, captured_amount = $2, refunded_amount = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $4
+	`
+
+	_, err := r.db.Exec(query, payment.Status, payment.Captured.Amount, payment.Refunded.Amount, payment.ID)
+	return err
+}
+
+// RecordEvent stores a webhook event and reports whether it was new. A
+// duplicate delivery returns false so the caller can skip it.
+func (r *PaymentRepository) RecordEvent(provider, eventID, eventType string, payload []byte) (bool, error) {
+	query := `
+		INSERT INTO payment_events (provider, event_id, event_type, payload)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (provider, event_id) DO NOTHING
+	`
+
+	result, err := r.db.Exec(query, provider, eventID, eventType, string(payload))
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
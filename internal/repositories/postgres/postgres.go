@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"log"
 	"hotel-booking-service/internal/data"
+	"hotel-booking-service/internal/pkg/confcode"
 	_ "github.com/lib/pq"
 )
 
@@ -80,7 +81,7 @@ func (r *UserRepo) CreateUser(user *data.User) error {
 }
 
 func (r *HotelRepo) GetAllHotels() ([]*data.Hotel, error) {
-	rows, err := r.db.Query("SELECT id, name, city FROM hotels")
+	rows, err := r.db.Query("SELECT id, name, city, currency FROM hotels")
 	if err != nil {
 		log.Printf("Error fetching hotels: %v", err)
 		return nil, err
@@ -90,7 +91,7 @@ func (r *HotelRepo) GetAllHotels() ([]*data.Hotel, error) {
 	var hotels []*data.Hotel
 	for rows.Next() {
 		var hotel data.Hotel
-		if err := rows.Scan(&hotel.ID, &hotel.Name, &hotel.City); err != nil {
+		if err := rows.Scan(&hotel.ID, &hotel.Name, &hotel.City, &hotel.Currency); err != nil {
 			log.Printf("Error scanning hotel: %v", err)
 			return nil, err
 		}
@@ -100,9 +101,9 @@ func (r *HotelRepo) GetAllHotels() ([]*data.Hotel, error) {
 }
 
 func (r *HotelRepo) GetHotelByID(id int) (*data.Hotel, error) {
-	row := r.db.QueryRow("SELECT id, name, city FROM hotels WHERE id = $1", id)
+	row := r.db.QueryRow("SELECT id, name, city, currency FROM hotels WHERE id = $1", id)
 	var hotel data.Hotel
-	if err := row.Scan(&hotel.ID, &hotel.Name, &hotel.City); err != nil {
+	if err := row.Scan(&hotel.ID, &hotel.Name, &hotel.City, &hotel.Currency); err != nil {
 		log.Printf("Error fetching hotel: %v", err)
 		return nil, err
 	}
@@ -110,7 +111,7 @@ func (r *HotelRepo) GetHotelByID(id int) (*data.Hotel, error) {
 }
 
 func (r *HotelRepo) CreateHotel(hotel *data.Hotel) error {
-	_, err := r.db.Exec("INSERT INTO hotels (name, city) VALUES ($1, $2)", hotel.Name, hotel.City)
+	_, err := r.db.Exec("INSERT INTO hotels (name, city, currency) VALUES ($1, $2, $3)", hotel.Name, hotel.City, hotel.Currency)
 	if err != nil {
 		log.Printf("Error creating hotel: %v", err)
 		return err
@@ -119,7 +120,7 @@ func (r *HotelRepo) CreateHotel(hotel *data.Hotel) error {
 }
 
 func (r *RoomRepo) GetAllRooms() ([]*data.Room, error) {
-	rows, err := r.db.Query("SELECT id, hotel_id, number, capacity, price FROM rooms")
+	rows, err := r.db.Query("SELECT r.id, r.hotel_id, r.room_type_id, r.number, t.capacity, t.price_amount, h.currency FROM rooms r JOIN room_types t ON t.id = r.room_type_id JOIN hotels h ON h.id = r.hotel_id")
 	if err != nil {
 		log.Printf("Error fetching rooms: %v", err)
 		return nil, err
@@ -129,7 +130,7 @@ func (r *RoomRepo) GetAllRooms() ([]*data.Room, error) {
 	var rooms []*data.Room
 	for rows.Next() {
 		var room data.Room
-		if err := rows.Scan(&room.ID, &room.HotelID, &room.Number, &room.Capacity, &room.Price); err != nil {
+		if err := rows.Scan(&room.ID, &room.HotelID, &room.RoomTypeID, &room.Number, &room.Capacity, &room.Price.Amount, &room.Price.Currency); err != nil {
 			log.Printf("Error scanning room: %v", err)
 			return nil, err
 		}
@@ -139,9 +140,9 @@ func (r *RoomRepo) GetAllRooms() ([]*data.Room, error) {
 }
 
 func (r *RoomRepo) GetRoomByID(id int) (*data.Room, error) {
-	row := r.db.QueryRow("SELECT id, hotel_id, number, capacity, price FROM rooms WHERE id = $1", id)
+	row := r.db.QueryRow("SELECT r.id, r.hotel_id, r.room_type_id, r.number, t.capacity, t.price_amount, h.currency FROM rooms r JOIN room_types t ON t.id = r.room_type_id JOIN hotels h ON h.id = r.hotel_id WHERE r.id = $1", id)
 	var room data.Room
-	if err := row.Scan(&room.ID, &room.HotelID, &room.Number, &room.Capacity, &room.Price); err != nil {
+	if err := row.Scan(&room.ID, &room.HotelID, &room.RoomTypeID, &room.Number, &room.Capacity, &room.Price.Amount, &room.Price.Currency); err != nil {
 		log.Printf("Error fetching room: %v", err)
 		return nil, err
 	}
@@ -149,7 +150,7 @@ func (r *RoomRepo) GetRoomByID(id int) (*data.Room, error) {
 }
 
 func (r *RoomRepo) CreateRoom(room *data.Room) error {
-	_, err := r.db.Exec("INSERT INTO rooms (hotel_id, number, capacity, price) VALUES ($1, $2, $3, $4)", room.HotelID, room.Number, room.Capacity, room.Price)
+	_, err := r.db.Exec("INSERT INTO rooms (hotel_id, room_type_id, number) VALUES ($1, $2, $3)", room.HotelID, room.RoomTypeID, room.Number)
 	if err != nil {
 		log.Printf("Error creating room: %v", err)
 		return err
@@ -158,7 +159,7 @@ func (r *RoomRepo) CreateRoom(room *data.Room) error {
 }
 
 func (r *BookingRepo) GetAllBookings() ([]*data.Booking, error) {
-	rows, err := r.db.Query("SELECT id, user_id, room_id, from_date, to_date, status, created_at FROM bookings")
+	rows, err := r.db.Query("SELECT id, user_id, room_type_id, COALESCE(room_id, 0), from_date, to_date, status, created_at FROM bookings")
 	if err != nil {
 		log.Printf("Error fetching bookings: %v", err)
 		return nil, err
@@ -168,7 +169,7 @@ func (r *BookingRepo) GetAllBookings() ([]*data.Booking, error) {
 	var bookings []*data.Booking
 	for rows.Next() {
 		var booking data.Booking
-		if err := rows.Scan(&booking.ID, &booking.UserID, &booking.RoomID, &booking.FromDate, &booking.ToDate, &booking.Status, &booking.CreatedAt); err != nil {
+		if err := rows.Scan(&booking.ID, &booking.UserID, &booking.RoomTypeID, &booking.RoomID, &booking.FromDate, &booking.ToDate, &booking.Status, &booking.CreatedAt); err != nil {
 			log.Printf("Error scanning booking: %v", err)
 			return nil, err
 		}
@@ -178,9 +179,9 @@ func (r *BookingRepo) GetAllBookings() ([]*data.Booking, error) {
 }
 
 func (r *BookingRepo) GetBookingByID(id int) (*data.Booking, error) {
-	row := r.db.QueryRow("SELECT id, user_id, room_id, from_date, to_date, status, created_at FROM bookings WHERE id = $1", id)
+	row := r.db.QueryRow("SELECT id, user_id, room_type_id, COALESCE(room_id, 0), from_date, to_date, status, created_at FROM bookings WHERE id = $1", id)
 	var booking data.Booking
-	if err := row.Scan(&booking.ID, &booking.UserID, &booking.RoomID, &booking.FromDate, &booking.ToDate, &booking.Status, &booking.CreatedAt); err != nil {
+	if err := row.Scan(&booking.ID, &booking.UserID, &booking.RoomTypeID, &booking.RoomID, &booking.FromDate, &booking.ToDate, &booking.Status, &booking.CreatedAt); err != nil {
 		log.Printf("Error fetching booking: %v", err)
 		return nil, err
 	}
@@ -188,7 +189,11 @@ func (r *BookingRepo) GetBookingByID(id int) (*data.Booking, error) {
 }
 
 func (r *BookingRepo) CreateBooking(booking *data.Booking) error {
-	_, err := r.db.Exec("INSERT INTO bookings (user_id, room_id, from_date, to_date, status, created_at) VALUES ($1, $2, $3, $4, $5, $6)", booking.UserID, booking.RoomID, booking.FromDate, booking.ToDate, booking.Status, booking.CreatedAt)
+	code, err := confcode.New()
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec("INSERT INTO bookings (confirmation_code, user_id, room_type_id, room_id, from_date, to_date, status, created_at) VALUES ($1, $2, (SELECT room_type_id FROM rooms WHERE id = $3), $3, $4, $5, $6, $7)", code, booking.UserID, booking.RoomID, booking.FromDate, booking.ToDate, booking.Status, booking.CreatedAt)
 	if err != nil {
 		log.Printf("Error creating booking: %v", err)
 		return err
@@ -0,0 +1,192 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+
+	"hotel-booking-service/internal/data"
+)
+
+type RestrictionRepository struct {
+	db *sql.DB
+}
+
+func NewRestrictionRepository(db *sql.DB) *RestrictionRepository {
+	return &RestrictionRepository{db: db}
+}
+
+const restrictionColumns = `
+	id, hotel_id, room_type_id, from_date, to_date, days_of_week,
+	min_stay, max_stay, closed_to_arrival, closed_to_departure,
+	min_lead_days, max_lead_days, created_at`
+
+func scanRestriction(row rowScanner) (data.StayRestriction, error) {
+	var restriction data.StayRestriction
+	var roomTypeID sql.NullInt64
+	var days pq.Int64Array
+	err := row.Scan(
+		&restriction.ID,
+		&restriction.HotelID,
+		&roomTypeID,
+		&restriction.FromDate,
+		&restriction.ToDate,
+		&days,
+		&restriction.MinStay,
+		&restriction.MaxStay,
+		&restriction.ClosedToArrival,
+		&restriction.ClosedToDeparture,
+		&restriction.MinLeadDays,
+		&restriction.MaxLeadDays,
+		&restriction.CreatedAt,
+	)
+	if roomTypeID.Valid {
+		id := int(roomTypeID.Int64)
+		restriction.RoomTypeID = &id
+	}
+	for _, day := range days {
+		restriction.DaysOfWeek = append(restriction.DaysOfWeek, int(day))
+	}
+	return restriction, err
+}
+
+func (r *RestrictionRepository) GetByID(id int) (*data.StayRestriction, error) {
+	query := `SELECT ` + restrictionColumns + ` FROM stay_restrictions WHERE id = $1`
+
+	restriction, err := scanRestriction(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &restriction, nil
+}
+
+func (r *RestrictionRepository) GetByHotelID(hotelID int) ([]data.StayRestriction, error) {
+	query := `
+		SELECT ` + restrictionColumns + `
+		FROM stay_restrictions
+		WHERE hotel_id = $1
+		ORDER BY from_date, room_type_id NULLS FIRST, id
+	`
+
+	return r.queryRestrictions(query, hotelID)
+}
+
+// GetForStay returns the hotel's rules, for all rooms and for single room types,
+// that cover the arrival or the departure date of a stay.
+func (r *RestrictionRepository) GetForStay(hotelID int, fromDate, toDate time.Time) ([]data.StayRestriction, error) {
+	return r.GetForStayByHotels([]int{hotelID}, fromDate, toDate)
+}
+
+// GetForStayByHotels is GetForStay for several hotels at once, grouped by
+// hotel.
+func (r *RestrictionRepository) GetForStayByHotels(hotelIDs []int, fromDate, toDate time.Time) ([]data.StayRestriction, error) {
+	query := `
+		SELECT ` + restrictionColumns + `
+		FROM stay_restrictions
+		WHERE hotel_id = ANY($1)
+		AND (($2::date BETWEEN from_date AND to_date) OR ($3::date BETWEEN from_date AND to_date))
+		ORDER BY hotel_id, room_type_id NULLS FIRST, id
+	`
+
+	return r.queryRestrictions(query, pq.Array(hotelIDs), fromDate, toDate)
+}
+
+func (r *RestrictionRepository) queryRestrictions(query string, args ...interface{}) ([]data.StayRestriction, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	restrictions := []data.StayRestriction{}
+	for rows.Next() {
+		restriction, err := scanRestriction(rows)
+		if err != nil {
+			return nil, err
+		}
+		restrictions = append(restrictions, restriction)
+	}
+
+	return restrictions, rows.Err()
+}
+
+func (r *RestrictionRepository) Create(restriction *data.StayRestriction) (*data.StayRestriction, error) {
+	query := `
+		INSERT INTO stay_restrictions (
+			hotel_id, room_type_id, from_date, to_date, days_of_week,
+			min_stay, max_stay, closed_to_arrival, closed_to_departure,
+			min_lead_days, max_lead_days
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING ` + restrictionColumns
+
+	created, err := scanRestriction(r.db.QueryRow(
+		query,
+		restriction.HotelID,
+		restriction.RoomTypeID,
+		restriction.FromDate,
+		restriction.ToDate,
+		weekdayArray(restriction.DaysOfWeek),
+		restriction.MinStay,
+		restriction.MaxStay,
+		restriction.ClosedToArrival,
+		restriction.ClosedToDeparture,
+		restriction.MinLeadDays,
+		restriction.MaxLeadDays,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+func (r *RestrictionRepository) Update(restriction *data.StayRestriction) (*data.StayRestriction, error) {
+	query := `
+		UPDATE stay_restrictions
+		SET from_date = $1, to_date = $2, days_of_week = $3,
+			min_stay = $4, max_stay = $5, closed_to_arrival = $6, closed_to_departure = $7,
+			min_lead_days = $8, max_lead_days = $9
+		WHERE id = $10
+		RETURNING ` + restrictionColumns
+
+	updated, err := scanRestriction(r.db.QueryRow(
+		query,
+		restriction.FromDate,
+		restriction.ToDate,
+		weekdayArray(restriction.DaysOfWeek),
+		restriction.MinStay,
+		restriction.MaxStay,
+		restriction.ClosedToArrival,
+		restriction.ClosedToDeparture,
+		restriction.MinLeadDays,
+		restriction.MaxLeadDays,
+		restriction.ID,
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+func (r *RestrictionRepository) Delete(id int) error {
+	_, err := r.db.Exec(`DELETE FROM stay_restrictions WHERE id = $1`, id)
+	return err
+}
+
+func weekdayArray(days []int) pq.Int64Array {
+	array := pq.Int64Array{}
+	for _, day := range days {
+		array = append(array, int64(day))
+	}
+	return array
+}
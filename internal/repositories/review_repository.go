@@ -0,0 +1,154 @@
+package repositories
+
+import (
+	"database/sql"
+
+	"hotel-booking-service/internal/data"
+)
+
+type ReviewRepository struct {
+	db *sql.DB
+}
+
+func NewReviewRepository(db *sql.DB) *ReviewRepository {
+	return &ReviewRepository{db: db}
+}
+
+// Reviews are signed with the author's first name only.
+const reviewColumns = `rv.id, rv.booking_id, rv.hotel_id, rv.user_id,
+	COALESCE(NULLIF(split_part(u.name, ' ', 1), ''), 'Guest'),
+	rv.cleanliness, rv.location, rv.service, rv.comment,
+	rv.status, rv.moderation_reason, rv.moderated_at, rv.reply, rv.replied_at, rv.created_at`
+
+const reviewTables = `reviews rv JOIN users u ON u.id = rv.user_id`
+
+func scanReview(row rowScanner) (data.Review, error) {
+	var review data.Review
+	var moderatedAt, repliedAt sql.NullTime
+	var reply sql.NullString
+	err := row.Scan(
+		&review.ID,
+		&review.BookingID,
+		&review.HotelID,
+		&review.UserID,
+		&review.AuthorName,
+		&review.Cleanliness,
+		&review.Location,
+		&review.Service,
+		&review.Comment,
+		&review.Status,
+		&review.ModerationReason,
+		&moderatedAt,
+		&reply,
+		&repliedAt,
+		&review.CreatedAt,
+	)
+	review.Overall = float64(review.Cleanliness+review.Location+review.Service) / 3
+	review.Reply = reply.String
+	if moderatedAt.Valid {
+		review.ModeratedAt = &moderatedAt.Time
+	}
+	if repliedAt.Valid {
+		review.RepliedAt = &repliedAt.Time
+	}
+	return review, err
+}
+
+// Create stores a review, or returns nil if the booking was already
+// reviewed.
+func (r *ReviewRepository) Create(review *data.Review) (*data.Review, error) {
+	query := `
+		INSERT INTO reviews (booking_id, hotel_id, user_id, cleanliness, location, service, comment)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (booking_id) DO NOTHING
+		RETURNING id
+	`
+
+	var id int
+	err := r.db.QueryRow(query,
+		review.BookingID,
+		review.HotelID,
+		review.UserID,
+		review.Cleanliness,
+		review.Location,
+		review.Service,
+		review.Comment,
+	).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(id)
+}
+
+func (r *ReviewRepository) GetByID(id int) (*data.Review, error) {
+	query := `SELECT ` + reviewColumns + ` FROM ` + reviewTables + ` WHERE rv.id = $1`
+
+	review, err := scanReview(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &review, nil
+}
+
+// GetForHotel returns a hotel's published reviews, newest first.
+func (r *ReviewRepository) GetForHotel(hotelID int) ([]data.Review, error) {
+	query := `SELECT ` + reviewColumns + ` FROM ` + reviewTables + `
+		WHERE rv.hotel_id = $1 AND rv.status = 'published'
+		ORDER BY rv.created_at DESC, rv.id DESC`
+
+	return r.list(query, hotelID)
+}
+
+// GetByStatus returns the reviews in a status across all hotels, newest
+// first.
+func (r *ReviewRepository) GetByStatus(status string) ([]data.Review, error) {
+	query := `SELECT ` + reviewColumns + ` FROM ` + reviewTables + `
+		WHERE rv.status = $1
+		ORDER BY rv.created_at DESC, rv.id DESC`
+
+	return r.list(query, status)
+}
+
+func (r *ReviewRepository) list(query string, args ...interface{}) ([]data.Review, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reviews := []data.Review{}
+	for rows.Next() {
+		review, err := scanReview(rows)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, review)
+	}
+
+	return reviews, rows.Err()
+}
+
+// SetReply stores the hotel's reply, replacing any earlier one.
+func (r *ReviewRepository) SetReply(id, staffID int, reply string) error {
+	_, err := r.db.Exec(
+		`UPDATE reviews SET reply = $2, replied_by = $3, replied_at = CURRENT_TIMESTAMP WHERE id = $1`,
+		id, reply, staffID,
+	)
+	return err
+}
+
+func (r *ReviewRepository) Moderate(id, adminID int, status, reason string) error {
+	_, err := r.db.Exec(
+		`UPDATE reviews SET status = $2, moderation_reason = $3, moderated_by = $4, moderated_at = CURRENT_TIMESTAMP WHERE id = $1`,
+		id, status, reason, adminID,
+	)
+	return err
+}
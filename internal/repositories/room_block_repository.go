@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"hotel-booking-service/internal/data"
+)
+
+type RoomBlockRepository struct {
+	db *sql.DB
+}
+
+func NewRoomBlockRepository(db *sql.DB) *RoomBlockRepository {
+	return &RoomBlockRepository{db: db}
+}
+
+const roomBlockColumns = `k.id, k.room_id, k.from_date, k.to_date, k.reason, k.created_by, k.created_at`
+
+func scanRoomBlock(row rowScanner) (data.RoomBlock, error) {
+	var block data.RoomBlock
+	var createdBy sql.NullInt64
+	err := row.Scan(
+		&block.ID,
+		&block.RoomID,
+		&block.FromDate,
+		&block.ToDate,
+		&block.Reason,
+		&createdBy,
+		&block.CreatedAt,
+	)
+	block.CreatedBy = int(createdBy.Int64)
+	return block, err
+}
+
+func (r *RoomBlockRepository) GetByID(id int) (*data.RoomBlock, error) {
+	query := `SELECT ` + roomBlockColumns + ` FROM room_blocks k WHERE k.id = $1`
+
+	block, err := scanRoomBlock(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &block, nil
+}
+
+// GetForHotel returns the blocks on the hotel's rooms that cover any night
+// from fromDate up to, but not including, toDate.
+func (r *RoomBlockRepository) GetForHotel(hotelID int, fromDate, toDate time.Time) ([]data.RoomBlock, error) {
+	query := `
+		SELECT ` + roomBlockColumns + `
+		FROM room_blocks k
+		JOIN rooms r ON r.id = k.room_id
+		WHERE r.hotel_id = $1
+		AND k.from_date < $3 AND k.to_date > $2
+		ORDER BY k.from_date, k.id
+	`
+
+	rows, err := r.db.Query(query, hotelID, fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	blocks := []data.RoomBlock{}
+	for rows.Next() {
+		block, err := scanRoomBlock(rows)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, block)
+	}
+
+	return blocks, rows.Err()
+}
+
+func (r *RoomBlockRepository) Create(block *data.RoomBlock) (*data.RoomBlock, error) {
+	query := `
+		INSERT INTO room_blocks (room_id, from_date, to_date, reason, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRow(
+		query,
+		block.RoomID,
+		block.FromDate,
+		block.ToDate,
+		block.Reason,
+		block.CreatedBy,
+	).Scan(&block.ID, &block.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return block, nil
+}
+
+func (r *RoomBlockRepository) Delete(id int) error {
+	_, err := r.db.Exec(`DELETE FROM room_blocks WHERE id = $1`, id)
+	return err
+}
@@ -5,6 +5,8 @@ import (
 
 	"database/sql"
 	"hotel-booking-service/internal/data"
+
+	"github.com/lib/pq"
 )
 
 type RoomRepository struct {
@@ -15,144 +17,196 @@ func NewRoomRepository(db *sql.DB) *RoomRepository {
 	return &RoomRepository{db: db}
 }
 
-func (r *RoomRepository) GetByID(id int) (*data.Room, error) {
-	query := `SELECT id, hotel_id, number, capacity, price FROM rooms WHERE id = $1`
-	
+const (
+	roomColumns = `r.id, r.hotel_id, r.room_type_id, t.name, r.number, t.capacity, t.price_amount, h.currency, r.housekeeping_status, r.version`
+	roomTables  = `rooms r JOIN room_types t ON t.id = r.room_type_id JOIN hotels h ON h.id = r.hotel_id`
+)
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanRoom(row rowScanner) (data.Room, error) {
 	var room data.Room
-	err := r.db.QueryRow(query, id).Scan(
+	err := row.Scan(
 		&room.ID,
 		&room.HotelID,
+		&room.RoomTypeID,
+		&room.RoomType,
 		&room.Number,
 		&room.Capacity,
-		&room.Price,
+		&room.Price.Amount,
+		&room.Price.Currency,
+		&room.HousekeepingStatus,
+		&room.Version,
 	)
-	
+	return room, err
+}
+
+func scanRooms(rows *sql.Rows) ([]data.Room, error) {
+	defer rows.Close()
+
+	var rooms []data.Room
+	for rows.Next() {
+		room, err := scanRoom(rows)
+		if err != nil {
+			return nil, err
+		}
+		rooms = append(rooms, room)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return rooms, nil
+}
+
+func (r *RoomRepository) GetByID(id int) (*data.Room, error) {
+	query := `SELECT ` + roomColumns + ` FROM ` + roomTables + ` WHERE r.id = $1`
+
+	room, err := scanRoom(r.db.QueryRow(query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
 		return nil, err
 	}
-	
+
 	return &room, nil
 }
 
+// roomAvailable matches rooms r that can be sold for the stay $2 to $3: no
+// live booking overlaps it, no waitlist guest holds an offer on it, and it
+// is not blocked for maintenance on any night of the stay.
+const roomAvailable = `
+	NOT EXISTS (
+		SELECT 1 FROM bookings b
+		WHERE b.room_id = r.id
+		AND b.status NOT IN (` + releasedStatuses + `)
+		AND b.from_date < $3 AND b.to_date > $2
+	)
+	AND NOT EXISTS (
+		SELECT 1 FROM waitlist_entries w
+		WHERE w.offered_room_id = r.id
+		AND w.status = 'offered'
+		AND w.offer_expires_at > NOW()
+		AND w.from_date < $3 AND w.to_date > $2
+	)
+	AND NOT EXISTS (
+		SELECT 1 FROM room_blocks k
+		WHERE k.room_id = r.id
+		AND k.from_date < $3 AND k.to_date > $2
+	)`
+
 func (r *RoomRepository) CheckRoomAvailability(roomID int, fromDate, toDate time.Time) (bool, error) {
-	query := `
-		SELECT COUNT(*) FROM bookings 
-		WHERE room_id = $1 
-		AND status != 'cancelled'
-		AND (
-			(from_date <= $2 AND to_date >= $2) OR
-			(from_date <= $3 AND to_date >= $3) OR
-			(from_date >= $2 AND to_date <= $3)
-		)
-	`
-	
-	var count int
-	err := r.db.QueryRow(query, roomID, fromDate, toDate).Scan(&count)
+	query := `SELECT ` + roomAvailable + ` FROM rooms r WHERE r.id = $1`
+
+	var available bool
+	err := r.db.QueryRow(query, roomID, fromDate, toDate).Scan(&available)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
 		return false, err
 	}
-	
-	return count == 0, nil
+
+	return available, nil
 }
 
 func (r *RoomRepository) GetAvailableRoomsByHotelID(hotelID int, fromDate, toDate time.Time) ([]data.Room, error) {
+	return r.GetAvailableRoomsByHotelIDs([]int{hotelID}, fromDate, toDate)
+}
+
+// GetAvailableRoomsByHotelIDs returns the rooms of several hotels that are
+// free for the stay, in a single query, grouped by hotel.
+func (r *RoomRepository) GetAvailableRoomsByHotelIDs(hotelIDs []int, fromDate, toDate time.Time) ([]data.Room, error) {
 	query := `
-		SELECT r.id, r.hotel_id, r.number, r.capacity, r.price
-		FROM rooms r
-		WHERE r.hotel_id = $1
-		AND NOT EXISTS (
-			SELECT 1 FROM bookings b
-			WHERE b.room_id = r.id
-			AND b.status != 'cancelled'
-			AND (
-				(b.from_date <= $2 AND b.to_date >= $2) OR
-				(b.from_date <= $3 AND b.to_date >= $3) OR
-				(b.from_date >= $2 AND b.to_date <= $3)
-			)
-		)
-	`
-	
-	rows, err := r.db.Query(query, hotelID, fromDate, toDate)
+		SELECT ` + roomColumns + `
+		FROM ` + roomTables + `
+		WHERE r.hotel_id = ANY($1)
+		AND ` + roomAvailable + `
+		ORDER BY r.hotel_id, r.id`
+
+	rows, err := r.db.Query(query, pq.Array(hotelIDs), fromDate, toDate)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-	
-	var rooms []data.Room
-	for rows.Next() {
-		var room data.Room
-		if err := rows.Scan(
-			&room.ID,
-			&room.HotelID,
-			&room.Number,
-			&room.Capacity,
-			&room.Price,
-		); err != nil {
-			return nil, err
-		}
-		rooms = append(rooms, room)
-	}
-	
-	if err = rows.Err(); err != nil {
-		return nil, err
-	}
-	
-	return rooms, nil
+
+	return scanRooms(rows)
 }
 
 func (r *RoomRepository) GetAllRooms() ([]data.Room, error) {
-	query := `SELECT id, hotel_id, number, capacity, price FROM rooms`
+	query := `SELECT ` + roomColumns + ` FROM ` + roomTables
 	rows, err := r.db.Query(query)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	var rooms []data.Room
-	for rows.Next() {
-		var room data.Room
-		if err := rows.Scan(&room.ID, &room.HotelID, &room.Number, &room.Capacity, &room.Price); err != nil {
-			return nil, err
-		}
-		rooms = append(rooms, room)
-	}
 
-	if err = rows.Err(); err != nil {
-		return nil, err
-	}
-	return rooms, nil
+	return scanRooms(rows)
 }
 
 func (r *RoomRepository) CreateRoom(room *data.Room) (*data.Room, error) {
-	query := `INSERT INTO rooms (hotel_id, number, capacity, price) 
-	          VALUES ($1, $2, $3, $4) 
+	query := `INSERT INTO rooms (hotel_id, room_type_id, number)
+	          VALUES ($1, $2, $3)
 	          RETURNING id`
-	
-	err := r.db.QueryRow(query, room.HotelID, room.Number, room.Capacity, room.Price).Scan(&room.ID)
+
+	err := r.db.QueryRow(query, room.HotelID, room.RoomTypeID, room.Number).Scan(&room.ID)
 	if err != nil {
 		return nil, err
 	}
 
-	return room, nil
+	return r.GetByID(room.ID)
 }
 
+// UpdateRoom saves the room if it is still at room.Version and returns it
+// as stored, or nil if it has changed or gone.
 func (r *RoomRepository) UpdateRoom(room *data.Room) (*data.Room, error) {
-	query := `UPDATE rooms SET hotel_id = $1, number = $2, capacity = $3, price = $4 WHERE id = $5`
-	_, err := r.db.Exec(query, room.HotelID, room.Number, room.Capacity, room.Price, room.ID)
+	query := `UPDATE rooms SET hotel_id = $1, room_type_id = $2, number = $3 WHERE id = $4 AND version = $5`
+	result, err := r.db.Exec(query, room.HotelID, room.RoomTypeID, room.Number, room.ID, room.Version)
 	if err != nil {
 		return nil, err
 	}
-	return room, nil
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if affected == 0 {
+		return nil, nil
+	}
+	return r.GetByID(room.ID)
+}
+
+// GetRoomsByType returns the type's rooms in room number order.
+func (r *RoomRepository) GetRoomsByType(roomTypeID int) ([]data.Room, error) {
+	query := `SELECT ` + roomColumns + ` FROM ` + roomTables + ` WHERE r.room_type_id = $1 ORDER BY r.number, r.id`
+
+	rows, err := r.db.Query(query, roomTypeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanRooms(rows)
 }
 
-func (r *RoomRepository) DeleteRoom(roomID int) error {
-	query := `DELETE FROM rooms WHERE id = $1`
-	_, err := r.db.Exec(query, roomID)
+// DeleteRoom deletes the room if it is still at version and reports
+// whether it did.
+func (r *RoomRepository) DeleteRoom(roomID, version int) (bool, error) {
+	query := `DELETE FROM rooms WHERE id = $1 AND version = $2`
+	result, err := r.db.Exec(query, roomID, version)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
 	if err != nil {
-		return err
+		return false, err
 	}
-	return nil
-}
\ No newline at end of file
+	return affected > 0, nil
+}
+
+func (r *RoomRepository) SetHousekeepingStatus(roomID int, status string) error {
+	_, err := r.db.Exec(`UPDATE rooms SET housekeeping_status = $1 WHERE id = $2`, status, roomID)
+	return err
+}
@@ -0,0 +1,242 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+
+	"hotel-booking-service/internal/data"
+)
+
+type RoomTypeRepository struct {
+	db *sql.DB
+}
+
+func NewRoomTypeRepository(db *sql.DB) *RoomTypeRepository {
+	return &RoomTypeRepository{db: db}
+}
+
+const roomTypeColumns = `t.id, t.hotel_id, t.name, t.description, t.capacity, t.price_amount, h.currency, t.version, t.created_at`
+
+func scanRoomType(row rowScanner) (data.RoomType, error) {
+	var roomType data.RoomType
+	err := row.Scan(
+		&roomType.ID,
+		&roomType.HotelID,
+		&roomType.Name,
+		&roomType.Description,
+		&roomType.Capacity,
+		&roomType.Price.Amount,
+		&roomType.Price.Currency,
+		&roomType.Version,
+		&roomType.CreatedAt,
+	)
+	return roomType, err
+}
+
+// typeFreeUnits counts the units of type t left on night n.night: its rooms
+// that are not blocked, less the live bookings of the type, assigned or not,
+// and the rooms held for waitlist guests.
+const typeFreeUnits = `
+	(SELECT COUNT(*) FROM rooms r
+		WHERE r.room_type_id = t.id
+		AND NOT EXISTS (
+			SELECT 1 FROM room_blocks k
+			WHERE k.room_id = r.id AND k.from_date <= n.night AND k.to_date > n.night
+		))
+	- (SELECT COUNT(*) FROM bookings b
+		WHERE b.room_type_id = t.id
+		AND b.status NOT IN (` + releasedStatuses + `)
+		AND b.from_date <= n.night AND b.to_date > n.night)
+	- (SELECT COUNT(*) FROM waitlist_entries w
+		JOIN rooms r ON r.id = w.offered_room_id
+		WHERE r.room_type_id = t.id
+		AND w.status = 'offered'
+		AND w.offer_expires_at > NOW()
+		AND w.from_date <= n.night AND w.to_date > n.night)`
+
+// typeAvailability selects, for each type matched by the WHERE clause
+// appended to it, the fewest units left on any night from $2 up to, but not
+// including, $3.
+const typeAvailability = `
+	SELECT t.id, MIN(` + typeFreeUnits + `)
+	FROM room_types t
+	CROSS JOIN generate_series($2::date, $3::date - 1, interval '1 day') AS n(night)`
+
+func (r *RoomTypeRepository) GetByID(id int) (*data.RoomType, error) {
+	query := `SELECT ` + roomTypeColumns + ` FROM room_types t JOIN hotels h ON h.id = t.hotel_id WHERE t.id = $1`
+
+	roomType, err := scanRoomType(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &roomType, nil
+}
+
+func (r *RoomTypeRepository) GetByHotelID(hotelID int) ([]data.RoomType, error) {
+	return r.GetByHotelIDs([]int{hotelID})
+}
+
+// GetByHotelIDs returns the types of several hotels, grouped by hotel and
+// cheapest first within each.
+func (r *RoomTypeRepository) GetByHotelIDs(hotelIDs []int) ([]data.RoomType, error) {
+	query := `
+		SELECT ` + roomTypeColumns + `
+		FROM room_types t
+		JOIN hotels h ON h.id = t.hotel_id
+		WHERE t.hotel_id = ANY($1)
+		ORDER BY t.hotel_id, t.price_amount, t.name
+	`
+
+	rows, err := r.db.Query(query, pq.Array(hotelIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roomTypes := []data.RoomType{}
+	for rows.Next() {
+		roomType, err := scanRoomType(rows)
+		if err != nil {
+			return nil, err
+		}
+		roomTypes = append(roomTypes, roomType)
+	}
+
+	return roomTypes, rows.Err()
+}
+
+// GetAvailability returns the units left per type of the hotel for the
+// whole stay. Types without rooms are reported with zero units.
+func (r *RoomTypeRepository) GetAvailability(hotelID int, fromDate, toDate time.Time) (map[int]int, error) {
+	return r.GetAvailabilityForHotels([]int{hotelID}, fromDate, toDate)
+}
+
+// GetAvailabilityForHotels is GetAvailability for several hotels at once,
+// in a single query.
+func (r *RoomTypeRepository) GetAvailabilityForHotels(hotelIDs []int, fromDate, toDate time.Time) (map[int]int, error) {
+	query := typeAvailability + ` WHERE t.hotel_id = ANY($1) GROUP BY t.id`
+
+	rows, err := r.db.Query(query, pq.Array(hotelIDs), fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	available := map[int]int{}
+	for rows.Next() {
+		var typeID, units int
+		if err := rows.Scan(&typeID, &units); err != nil {
+			return nil, err
+		}
+		available[typeID] = units
+	}
+
+	return available, rows.Err()
+}
+
+// AvailableUnits returns the units of one type left for the whole stay.
+func (r *RoomTypeRepository) AvailableUnits(roomTypeID int, fromDate, toDate time.Time) (int, error) {
+	query := typeAvailability + ` WHERE t.id = $1 GROUP BY t.id`
+
+	var typeID, units int
+	err := r.db.QueryRow(query, roomTypeID, fromDate, toDate).Scan(&typeID, &units)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	return units, nil
+}
+
+func (r *RoomTypeRepository) Create(roomType *data.RoomType) (*data.RoomType, error) {
+	query := `
+		INSERT INTO room_types (hotel_id, name, description, capacity, price_amount)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+
+	var id int
+	err := r.db.QueryRow(
+		query,
+		roomType.HotelID,
+		roomType.Name,
+		roomType.Description,
+		roomType.Capacity,
+		roomType.Price.Amount,
+	).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(id)
+}
+
+// Update saves the type if it is still at roomType.Version, and returns nil
+// if it is not.
+func (r *RoomTypeRepository) Update(roomType *data.RoomType) (*data.RoomType, error) {
+	query := `
+		UPDATE room_types
+		SET name = $1, description = $2, capacity = $3, price_amount = $4
+		WHERE id = $5 AND version = $6
+	`
+
+	result, err := r.db.Exec(
+		query,
+		roomType.Name,
+		roomType.Description,
+		roomType.Capacity,
+		roomType.Price.Amount,
+		roomType.ID,
+		roomType.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if affected == 0 {
+		return nil, nil
+	}
+	return r.GetByID(roomType.ID)
+}
+
+func (r *RoomTypeRepository) CountRooms(roomTypeID int) (int, error) {
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM rooms WHERE room_type_id = $1`, roomTypeID).Scan(&count)
+	return count, err
+}
+
+// CountLiveBookings counts the type's bookings that still hold a unit.
+func (r *RoomTypeRepository) CountLiveBookings(roomTypeID int) (int, error) {
+	query := `SELECT COUNT(*) FROM bookings WHERE room_type_id = $1 AND status NOT IN (` + releasedStatuses + `)`
+
+	var count int
+	err := r.db.QueryRow(query, roomTypeID).Scan(&count)
+	return count, err
+}
+
+// Delete deletes the type if it is still at version and reports whether it
+// did.
+func (r *RoomTypeRepository) Delete(id, version int) (bool, error) {
+	result, err := r.db.Exec(`DELETE FROM room_types WHERE id = $1 AND version = $2`, id, version)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
@@ -14,17 +14,21 @@ func NewUserRepository(db *sql.DB) *UserRepository {
 	return &UserRepository{db: db}
 }
 
-func (r *UserRepository) Create(email, hashedPassword string) (*data.User, error) {
+func (r *UserRepository) Create(email, hashedPassword, name string) (*data.User, error) {
 	query := `
-		INSERT INTO users (email, password)
-		VALUES ($1, $2)
-		RETURNING id, email, created_at
+		INSERT INTO users (email, password, name)
+		VALUES ($1, $2, $3)
+		RETURNING id, email, name, phone, role, version, created_at
 	`
 	
 	var user data.User
-	err := r.db.QueryRow(query, email, hashedPassword).Scan(
+	err := r.db.QueryRow(query, email, hashedPassword, name).Scan(
 		&user.ID,
 		&user.Email,
+		&user.Name,
+		&user.Phone,
+		&user.Role,
+		&user.Version,
 		&user.CreatedAt,
 	)
 	
@@ -37,7 +41,7 @@ func (r *UserRepository) Create(email, hashedPassword string) (*data.User, error
 
 func (r *UserRepository) FindByEmail(email string) (*data.User, error) {
 	query := `
-		SELECT id, email, password, created_at
+		SELECT id, email, password, name, phone, role, version, created_at
 		FROM users
 		WHERE email = $1
 	`
@@ -47,6 +51,10 @@ func (r *UserRepository) FindByEmail(email string) (*data.User, error) {
 		&user.ID,
 		&user.Email,
 		&user.Password,
+		&user.Name,
+		&user.Phone,
+		&user.Role,
+		&user.Version,
 		&user.CreatedAt,
 	)
 	
@@ -62,15 +70,21 @@ func (r *UserRepository) FindByEmail(email string) (*data.User, error) {
 
 func (r *UserRepository) GetByID(id int) (*data.User, error) {
 	query := `
-		SELECT id, email, created_at
+		SELECT id, email, name, phone, role, hotel_id, version, created_at
 		FROM users
 		WHERE id = $1
 	`
 	
 	var user data.User
+	var hotelID sql.NullInt64
 	err := r.db.QueryRow(query, id).Scan(
 		&user.ID,
 		&user.Email,
+		&user.Name,
+		&user.Phone,
+		&user.Role,
+		&hotelID,
+		&user.Version,
 		&user.CreatedAt,
 	)
 	
@@ -81,11 +95,97 @@ func (r *UserRepository) GetByID(id int) (*data.User, error) {
 		return nil, err
 	}
 	
+	if hotelID.Valid {
+		id := int(hotelID.Int64)
+		user.HotelID = &id
+	}
+	
+	return &user, nil
+}
+
+// UpsertGuest returns the guest identity for email, creating it with the
+// given contact details if there is none. An existing guest keeps the
+// details they have; only the guest can change them, with UpdateContact.
+// It returns nil if the email belongs to a registered account, whose holder
+// must log in to book.
+func (r *UserRepository) UpsertGuest(email, name, phone string) (*data.User, error) {
+	insert := `
+		INSERT INTO users (email, password, name, phone, role)
+		VALUES ($1, '', $2, $3, 'guest')
+		ON CONFLICT (email) DO NOTHING
+		RETURNING id, email, name, phone, role, version, created_at
+	`
+
+	var user data.User
+	scan := func(row *sql.Row) error {
+		return row.Scan(
+			&user.ID,
+			&user.Email,
+			&user.Name,
+			&user.Phone,
+			&user.Role,
+			&user.Version,
+			&user.CreatedAt,
+		)
+	}
+
+	err := scan(r.db.QueryRow(insert, email, name, phone))
+	if err == sql.ErrNoRows {
+		err = scan(r.db.QueryRow(`
+			SELECT id, email, name, phone, role, version, created_at
+			FROM users
+			WHERE email = $1 AND role = 'guest'
+		`, email))
+	}
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
 	return &user, nil
 }
 
+// ClaimGuest turns a guest identity into a full account with a password.
+// The guest's bookings stay with the same user and so move with it. An empty
+// name keeps the one given when booking.
+func (r *UserRepository) ClaimGuest(id int, hashedPassword, name string) (*data.User, error) {
+	query := `
+		UPDATE users
+		SET password = $1, role = 'user', name = COALESCE(NULLIF($2, ''), name)
+		WHERE id = $3 AND role = 'guest'
+		RETURNING id, email, name, phone, role, version, created_at
+	`
+
+	var user data.User
+	err := r.db.QueryRow(query, hashedPassword, name, id).Scan(
+		&user.ID,
+		&user.Email,
+		&user.Name,
+		&user.Phone,
+		&user.Role,
+		&user.Version,
+		&user.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// UpdateContact changes a user's name and phone number.
+func (r *UserRepository) UpdateContact(id int, name, phone string) error {
+	_, err := r.db.Exec(`UPDATE users SET name = $1, phone = $2 WHERE id = $3`, name, phone, id)
+	return err
+}
+
 func (r *UserRepository) GetAllUsers() ([]data.User, error) {
-	query := `SELECT id, email, created_at FROM users`
+	query := `SELECT id, email, name, role, version, created_at FROM users`
 	rows, err := r.db.Query(query)
 	if err != nil {
 		return nil, err
@@ -95,7 +195,7 @@ func (r *UserRepository) GetAllUsers() ([]data.User, error) {
 	var users []data.User
 	for rows.Next() {
 		var user data.User
-		if err := rows.Scan(&user.ID, &user.Email, &user.CreatedAt); err != nil {
+		if err := rows.Scan(&user.ID, &user.Email, &user.Name, &user.Role, &user.Version, &user.CreatedAt); err != nil {
 			return nil, err
 		}
 		users = append(users, user)
@@ -107,14 +207,34 @@ func (r *UserRepository) GetAllUsers() ([]data.User, error) {
 	return users, nil
 }
 
-func (r *UserRepository) Update(user *data.User) error {
-	query := `UPDATE users SET name = $1, email = $2, password = $3 WHERE id = $4`
-	_, err := r.db.Exec(query, user.Name, user.Email, user.Password, user.ID)
-	return err
+// Update saves the user if it is still at user.Version and reports whether
+// it did.
+func (r *UserRepository) Update(user *data.User) (bool, error) {
+	query := `UPDATE users SET name = $1, email = $2, password = $3 WHERE id = $4 AND version = $5`
+	result, err := r.db.Exec(query, user.Name, user.Email, user.Password, user.ID, user.Version)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
 }
 
-func (r *UserRepository) Delete(id int) error {
-	query := `DELETE FROM users WHERE id = $1`
-	_, err := r.db.Exec(query, id)
-	return err
+// Delete deletes the user if it is still at version and reports whether it
+// did.
+func (r *UserRepository) Delete(id, version int) (bool, error) {
+	query := `DELETE FROM users WHERE id = $1 AND version = $2`
+	result, err := r.db.Exec(query, id, version)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
 }
\ No newline at end of file
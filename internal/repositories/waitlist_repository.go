@@ -0,0 +1,242 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"hotel-booking-service/internal/data"
+)
+
+type WaitlistRepository struct {
+	db *sql.DB
+}
+
+func NewWaitlistRepository(db *sql.DB) *WaitlistRepository {
+	return &WaitlistRepository{db: db}
+}
+
+const waitlistColumns = `
+	id, user_id, hotel_id, room_type_id, from_date, to_date, guests, status,
+	offered_room_id, offer_expires_at, booking_id, created_at`
+
+func scanWaitlistEntry(row rowScanner) (data.WaitlistEntry, error) {
+	var entry data.WaitlistEntry
+	var roomTypeID, offeredRoomID, bookingID sql.NullInt64
+	var offerExpiresAt sql.NullTime
+
+	err := row.Scan(
+		&entry.ID,
+		&entry.UserID,
+		&entry.HotelID,
+		&roomTypeID,
+		&entry.FromDate,
+		&entry.ToDate,
+		&entry.Guests,
+		&entry.Status,
+		&offeredRoomID,
+		&offerExpiresAt,
+		&bookingID,
+		&entry.CreatedAt,
+	)
+	if err != nil {
+		return entry, err
+	}
+
+	entry.RoomTypeID = nullInt(roomTypeID)
+	entry.OfferedRoomID = nullInt(offeredRoomID)
+	entry.BookingID = nullInt(bookingID)
+	if offerExpiresAt.Valid {
+		entry.OfferExpiresAt = &offerExpiresAt.Time
+	}
+	return entry, nil
+}
+
+func scanWaitlistEntries(rows *sql.Rows) ([]data.WaitlistEntry, error) {
+	defer rows.Close()
+
+	entries := []data.WaitlistEntry{}
+	for rows.Next() {
+		entry, err := scanWaitlistEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+func nullInt(v sql.NullInt64) *int {
+	if !v.Valid {
+		return nil
+	}
+	i := int(v.Int64)
+	return &i
+}
+
+func (r *WaitlistRepository) Create(entry *data.WaitlistEntry) (*data.WaitlistEntry, error) {
+	query := `
+		INSERT INTO waitlist_entries (user_id, hotel_id, room_type_id, from_date, to_date, guests)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + waitlistColumns
+
+	created, err := scanWaitlistEntry(r.db.QueryRow(
+		query,
+		entry.UserID,
+		entry.HotelID,
+		entry.RoomTypeID,
+		entry.FromDate,
+		entry.ToDate,
+		entry.Guests,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+func (r *WaitlistRepository) GetByID(id int) (*data.WaitlistEntry, error) {
+	query := `SELECT ` + waitlistColumns + ` FROM waitlist_entries WHERE id = $1`
+
+	entry, err := scanWaitlistEntry(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+func (r *WaitlistRepository) GetByUserID(userID int) ([]data.WaitlistEntry, error) {
+	query := `
+		SELECT ` + waitlistColumns + `
+		FROM waitlist_entries
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanWaitlistEntries(rows)
+}
+
+// FindCandidates lists waiting entries, oldest first, that a freed unit of
+// the room type could serve: they wait for the type (or, from before the
+// waitlist was kept per type, for any type of its hotel), the party fits,
+// and their dates overlap the freed ones.
+func (r *WaitlistRepository) FindCandidates(roomType *data.RoomType, fromDate, toDate time.Time) ([]data.WaitlistEntry, error) {
+	query := `
+		SELECT ` + waitlistColumns + `
+		FROM waitlist_entries
+		WHERE status = 'waiting'
+		AND (room_type_id = $1 OR (room_type_id IS NULL AND hotel_id = $2))
+		AND guests <= $3
+		AND from_date <= $5 AND to_date >= $4
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.db.Query(query, roomType.ID, roomType.HotelID, roomType.Capacity, fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanWaitlistEntries(rows)
+}
+
+// Offer holds a room for a waiting entry until expiresAt. It reports false
+// if the entry was no longer waiting, e.g. because another instance got to
+// it first.
+func (r *WaitlistRepository) Offer(id, roomID int, expiresAt time.Time) (bool, error) {
+	query := `
+		UPDATE waitlist_entries
+		SET status = 'offered', offered_room_id = $1, offer_expires_at = $2
+		WHERE id = $3 AND status = 'waiting'
+	`
+
+	result, err := r.db.Exec(query, roomID, expiresAt, id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// Claim accepts a live offer on behalf of its owner, which releases the
+// hold so the booking can be made. It returns nil when there is no live
+// offer to claim.
+func (r *WaitlistRepository) Claim(id, userID int) (*data.WaitlistEntry, error) {
+	query := `
+		UPDATE waitlist_entries
+		SET status = 'claimed'
+		WHERE id = $1 AND user_id = $2 AND status = 'offered' AND offer_expires_at > NOW()
+		RETURNING ` + waitlistColumns
+
+	entry, err := scanWaitlistEntry(r.db.QueryRow(query, id, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// RestoreOffer puts back an offer whose claim could not be turned into a
+// booking, keeping its original expiry.
+func (r *WaitlistRepository) RestoreOffer(id int) error {
+	_, err := r.db.Exec(`UPDATE waitlist_entries SET status = 'offered' WHERE id = $1 AND status = 'claimed'`, id)
+	return err
+}
+
+func (r *WaitlistRepository) SetBooking(id, bookingID int) error {
+	_, err := r.db.Exec(`UPDATE waitlist_entries SET booking_id = $1 WHERE id = $2`, bookingID, id)
+	return err
+}
+
+func (r *WaitlistRepository) Cancel(id, userID int) (bool, error) {
+	query := `
+		UPDATE waitlist_entries
+		SET status = 'cancelled'
+		WHERE id = $1 AND user_id = $2 AND status IN ('waiting', 'offered')
+	`
+
+	result, err := r.db.Exec(query, id, userID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// ExpireOffers closes offers whose time ran out and returns them so their
+// rooms can be offered to the next guest in line. The update is a single
+// statement, so concurrent instances never expire the same offer twice.
+func (r *WaitlistRepository) ExpireOffers() ([]data.WaitlistEntry, error) {
+	query := `
+		UPDATE waitlist_entries
+		SET status = 'expired'
+		WHERE status = 'offered' AND offer_expires_at <= NOW()
+		RETURNING ` + waitlistColumns
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanWaitlistEntries(rows)
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"hotel-booking-service/internal/data"
+	"hotel-booking-service/internal/pkg/money"
 	"hotel-booking-service/internal/repositories"
 )
 
@@ -37,6 +38,13 @@ func (s *BookingService) GetBookingByID(id int) (*data.Booking, error) {
 	return booking, nil
 }
 
-func (s *BookingService) CreateBooking(userID, roomID int, fromDate, toDate time.Time) (*data.Booking, error) {
-	return s.bookingRepo.CreateBooking(userID, roomID, fromDate, toDate)
+func (s *BookingService) CreateBooking(userID, roomID int, fromDate, toDate time.Time, nightlyRate, total money.Money) (*data.Booking, error) {
+	return s.bookingRepo.CreateBooking(&data.Booking{
+		UserID:      userID,
+		RoomID:      roomID,
+		FromDate:    fromDate,
+		ToDate:      toDate,
+		NightlyRate: nightlyRate,
+		TotalPrice:  total,
+	}, nil)
 }
\ No newline at end of file
@@ -19,18 +19,32 @@ func NewHotelService(hotelRepo *repositories.HotelRepository, roomRepo *reposito
 }
 
 func (s *HotelService) GetAllHotels(fromDate, toDate time.Time) ([]*data.Hotel, error) {
-	hotels, err := s.hotelRepo.GetAllHotels()
+	hotels, err := s.hotelRepo.GetAllHotels(data.HotelFilter{})
 	if err != nil {
 		return nil, err
 	}
 
-	var hotelPointers []*data.Hotel
+	hotelIDs := make([]int, len(hotels))
 	for i := range hotels {
-		availableRooms, err := s.roomRepo.GetAvailableRoomsByHotelID(hotels[i].ID, fromDate, toDate)
+		hotelIDs[i] = hotels[i].ID
+	}
+
+	var rooms []data.Room
+	if len(hotelIDs) > 0 {
+		rooms, err = s.roomRepo.GetAvailableRoomsByHotelIDs(hotelIDs, fromDate, toDate)
 		if err != nil {
 			return nil, err
 		}
-		hotels[i].Rooms = availableRooms
+	}
+
+	roomsByHotel := map[int][]data.Room{}
+	for _, room := range rooms {
+		roomsByHotel[room.HotelID] = append(roomsByHotel[room.HotelID], room)
+	}
+
+	var hotelPointers []*data.Hotel
+	for i := range hotels {
+		hotels[i].Rooms = roomsByHotel[hotels[i].ID]
 		hotelPointers = append(hotelPointers, &hotels[i])
 	}
 
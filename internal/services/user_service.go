@@ -36,7 +36,7 @@ func (s *UserService) GetUserByID(id int) (*data.User, error) {
 }
 
 func (s *UserService) CreateUser(user *data.User) (*data.User, error) {
-	createdUser, err := s.userRepo.Create(user.Email, user.Password)
+	createdUser, err := s.userRepo.Create(user.Email, user.Password, user.Name)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,252 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"hotel-booking-service/internal/data"
+	"hotel-booking-service/internal/pkg/apperror"
+	"hotel-booking-service/internal/repositories"
+)
+
+const maxAmenityFilters = 20
+
+// Amenity codes are what search URLs carry, so they stay short and plain,
+// e.g. "sea_view".
+var amenityCodePattern = regexp.MustCompile(`^[a-z0-9]+(_[a-z0-9]+)*$`)
+
+// AmenityUsecase manages the amenity catalog and which hotels, room types
+// and rooms have which amenities.
+type AmenityUsecase struct {
+	amenityRepo  *repositories.AmenityRepository
+	hotelRepo    *repositories.HotelRepository
+	roomTypeRepo *repositories.RoomTypeRepository
+	roomRepo     *repositories.RoomRepository
+}
+
+func NewAmenityUsecase(
+	amenityRepo *repositories.AmenityRepository,
+	hotelRepo *repositories.HotelRepository,
+	roomTypeRepo *repositories.RoomTypeRepository,
+	roomRepo *repositories.RoomRepository,
+) *AmenityUsecase {
+	return &AmenityUsecase{
+		amenityRepo:  amenityRepo,
+		hotelRepo:    hotelRepo,
+		roomTypeRepo: roomTypeRepo,
+		roomRepo:     roomRepo,
+	}
+}
+
+func (uc *AmenityUsecase) GetAmenities() ([]data.Amenity, error) {
+	return uc.amenityRepo.GetAll()
+}
+
+func (uc *AmenityUsecase) CreateAmenity(amenity data.Amenity) (*data.Amenity, error) {
+	if err := validateAmenity(&amenity); err != nil {
+		return nil, err
+	}
+	if amenity.Scope != data.AmenityScopeHotel && amenity.Scope != data.AmenityScopeRoom {
+		return nil, fmt.Errorf("%w: scope must be %q or %q", apperror.ErrInvalidRequest, data.AmenityScopeHotel, data.AmenityScopeRoom)
+	}
+
+	created, err := uc.amenityRepo.Create(&amenity)
+	if err != nil {
+		return nil, err
+	}
+	if created == nil {
+		return nil, fmt.Errorf("%w: there is already an amenity with code %q", apperror.ErrConflict, amenity.Code)
+	}
+	return created, nil
+}
+
+// UpdateAmenity changes an amenity's code and name. Its scope stays as it
+// was, since hotels, room types or rooms may have it.
+func (uc *AmenityUsecase) UpdateAmenity(amenity data.Amenity) (*data.Amenity, error) {
+	existing, err := uc.amenityRepo.GetByID(amenity.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, fmt.Errorf("%w: amenity not found", apperror.ErrNotFound)
+	}
+
+	if amenity.Scope != "" && amenity.Scope != existing.Scope {
+		return nil, fmt.Errorf("%w: an amenity's scope cannot be changed", apperror.ErrInvalidRequest)
+	}
+	amenity.Scope = existing.Scope
+
+	if err := validateAmenity(&amenity); err != nil {
+		return nil, err
+	}
+
+	updated, err := uc.amenityRepo.Update(&amenity)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, fmt.Errorf("%w: there is already an amenity with code %q", apperror.ErrConflict, amenity.Code)
+	}
+	return updated, nil
+}
+
+// DeleteAmenity removes the amenity from the catalog and from the hotels,
+// room types and rooms that had it.
+func (uc *AmenityUsecase) DeleteAmenity(id int) error {
+	deleted, err := uc.amenityRepo.Delete(id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return fmt.Errorf("%w: amenity not found", apperror.ErrNotFound)
+	}
+	return nil
+}
+
+// SetHotelAmenities replaces the hotel's amenities with the hotel amenities
+// whose codes are given, and returns them.
+func (uc *AmenityUsecase) SetHotelAmenities(hotelID int, codes []string) ([]data.Amenity, error) {
+	hotel, err := uc.hotelRepo.GetByID(hotelID)
+	if err != nil {
+		return nil, err
+	}
+	if hotel == nil {
+		return nil, errors.New("hotel not found")
+	}
+
+	amenities, err := uc.resolve(codes, data.AmenityScopeHotel)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.amenityRepo.SetForHotel(hotelID, amenityIDs(amenities)); err != nil {
+		return nil, err
+	}
+	return amenities, nil
+}
+
+// SetRoomTypeAmenities replaces the room type's amenities with the room
+// amenities whose codes are given, and returns them. Every room of the type
+// has them.
+func (uc *AmenityUsecase) SetRoomTypeAmenities(roomTypeID int, codes []string) ([]data.Amenity, error) {
+	roomType, err := uc.roomTypeRepo.GetByID(roomTypeID)
+	if err != nil {
+		return nil, err
+	}
+	if roomType == nil {
+		return nil, fmt.Errorf("%w: room type not found", apperror.ErrNotFound)
+	}
+
+	amenities, err := uc.resolve(codes, data.AmenityScopeRoom)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.amenityRepo.SetForRoomType(roomTypeID, amenityIDs(amenities)); err != nil {
+		return nil, err
+	}
+	return amenities, nil
+}
+
+// SetRoomAmenities replaces the room amenities the room has on top of its
+// type's, such as the one room of a type with a sea view, and returns them.
+func (uc *AmenityUsecase) SetRoomAmenities(roomID int, codes []string) ([]data.Amenity, error) {
+	room, err := uc.roomRepo.GetByID(roomID)
+	if err != nil {
+		return nil, err
+	}
+	if room == nil {
+		return nil, errors.New("room not found")
+	}
+
+	amenities, err := uc.resolve(codes, data.AmenityScopeRoom)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.amenityRepo.SetForRoom(roomID, amenityIDs(amenities)); err != nil {
+		return nil, err
+	}
+	return amenities, nil
+}
+
+// resolve looks up the amenities with the given codes, which must all be in
+// the catalog and of the scope.
+func (uc *AmenityUsecase) resolve(codes []string, scope string) ([]data.Amenity, error) {
+	codes = normalizeAmenityCodes(codes)
+	if len(codes) == 0 {
+		return []data.Amenity{}, nil
+	}
+
+	amenities, err := lookUpAmenities(uc.amenityRepo, codes)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, amenity := range amenities {
+		if amenity.Scope != scope {
+			return nil, fmt.Errorf("%w: %q is a %s amenity", apperror.ErrInvalidRequest, amenity.Code, amenity.Scope)
+		}
+	}
+	return amenities, nil
+}
+
+// lookUpAmenities returns the amenities with the given codes, or an error
+// naming a code that is not in the catalog.
+func lookUpAmenities(amenityRepo *repositories.AmenityRepository, codes []string) ([]data.Amenity, error) {
+	amenities, err := amenityRepo.GetByCodes(codes)
+	if err != nil {
+		return nil, err
+	}
+
+	known := map[string]bool{}
+	for _, amenity := range amenities {
+		known[amenity.Code] = true
+	}
+	for _, code := range codes {
+		if !known[code] {
+			return nil, fmt.Errorf("%w: unknown amenity %q", apperror.ErrInvalidRequest, code)
+		}
+	}
+	return amenities, nil
+}
+
+// normalizeAmenityCodes trims and lowercases codes and drops blanks and
+// repeats.
+func normalizeAmenityCodes(codes []string) []string {
+	seen := map[string]bool{}
+	var normalized []string
+	for _, code := range codes {
+		code = strings.ToLower(strings.TrimSpace(code))
+		if code == "" || seen[code] {
+			continue
+		}
+		seen[code] = true
+		normalized = append(normalized, code)
+	}
+	return normalized
+}
+
+func validateAmenity(amenity *data.Amenity) error {
+	amenity.Code = strings.ToLower(strings.TrimSpace(amenity.Code))
+	amenity.Name = strings.TrimSpace(amenity.Name)
+
+	if len(amenity.Code) > 50 || !amenityCodePattern.MatchString(amenity.Code) {
+		return fmt.Errorf("%w: code must be up to 50 lowercase letters, digits and single underscores, e.g. \"sea_view\"", apperror.ErrInvalidRequest)
+	}
+	if amenity.Name == "" || utf8.RuneCountInString(amenity.Name) > 100 {
+		return fmt.Errorf("%w: name is required and must be at most 100 characters", apperror.ErrInvalidRequest)
+	}
+	return nil
+}
+
+func amenityIDs(amenities []data.Amenity) []int {
+	ids := make([]int, len(amenities))
+	for i, amenity := range amenities {
+		ids[i] = amenity.ID
+	}
+	return ids
+}
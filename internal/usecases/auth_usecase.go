@@ -1,60 +1,136 @@
 package usecases
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"log"
+	"strings"
 	"time"
 	
 	"golang.org/x/crypto/bcrypt"
 	"github.com/golang-jwt/jwt/v4"
 	
 	"hotel-booking-service/internal/data"
+	"hotel-booking-service/internal/notifications"
+	"hotel-booking-service/internal/pkg/apperror"
+	"hotel-booking-service/internal/pkg/magiclink"
 	"hotel-booking-service/internal/repositories"
 )
 
+// claimLinkTTL is how long a guest has to follow the link that turns their
+// guest identity into an account.
+const claimLinkTTL = 24 * time.Hour
+
 type AuthUsecase struct {
 	userRepo *repositories.UserRepository
 	jwtSecret string
 	tokenExpiry time.Duration
+	notifier notifications.Notifier
+	publicURL string
 }
 
-func NewAuthUsecase(userRepo *repositories.UserRepository, jwtSecret string, tokenExpiry time.Duration) *AuthUsecase {
+func NewAuthUsecase(userRepo *repositories.UserRepository, jwtSecret string, tokenExpiry time.Duration, notifier notifications.Notifier, publicURL string) *AuthUsecase {
 	return &AuthUsecase{
 		userRepo: userRepo,
 		jwtSecret: jwtSecret,
 		tokenExpiry: tokenExpiry,
+		notifier: notifier,
+		publicURL: strings.TrimRight(publicURL, "/"),
 	}
 }
 
-func (uc *AuthUsecase) Register(email, password string) (*data.User, error) {
+// Register creates an account. An email already used for guest bookings is
+// not taken over here, since registering proves nothing about who reads its
+// mail: a link to ClaimGuest is sent to the address instead, and Register
+// returns nil with no error.
+func (uc *AuthUsecase) Register(ctx context.Context, email, password, name string) (*data.User, error) {
 	existingUser, err := uc.userRepo.FindByEmail(email)
 	if err != nil {
 		return nil, err
 	}
 	
-	if existingUser != nil {
+	if existingUser != nil && existingUser.Role != data.RoleGuest {
 		return nil, errors.New("user with this email already exists")
 	}
 	
+	if existingUser != nil {
+		return nil, uc.sendClaimLink(ctx, existingUser)
+	}
+	
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	
+	user, err := uc.userRepo.Create(email, string(hashedPassword), strings.TrimSpace(name))
+	if err != nil {
+		return nil, err
+	}
+	
+	return user, nil
+}
+
+// ClaimGuest turns a guest identity into an account with a password, for
+// the holder of a link sent by Register. The guest's bookings stay with it.
+// A link works once, since the guest is no longer a guest afterwards.
+func (uc *AuthUsecase) ClaimGuest(token, password, name string) (*data.User, error) {
+	guestID, err := magiclink.VerifyClaim(uc.jwtSecret, token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", apperror.ErrUnauthorized, err)
+	}
+	
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
 		return nil, err
 	}
 	
-	user, err := uc.userRepo.Create(email, string(hashedPassword))
+	user, err := uc.userRepo.ClaimGuest(guestID, string(hashedPassword), strings.TrimSpace(name))
 	if err != nil {
 		return nil, err
 	}
 	
+	if user == nil {
+		return nil, fmt.Errorf("%w: this email is already registered; log in instead", apperror.ErrConflict)
+	}
+	
 	return user, nil
 }
 
+// sendClaimLink emails the guest a link to register with their address.
+func (uc *AuthUsecase) sendClaimLink(ctx context.Context, guest *data.User) error {
+	token, err := magiclink.SignClaim(uc.jwtSecret, guest.ID, time.Now().Add(claimLinkTTL))
+	if err != nil {
+		return err
+	}
+	
+	msg := notifications.Message{
+		To:      guest.Email,
+		Subject: "Complete your registration",
+		Body: fmt.Sprintf(
+			"Someone asked to register an account with this email address, which you have booked with as a guest.\n\n"+
+				"To create the account and keep your bookings in it, follow %s/register/claim?token=%s within %d hours "+
+				"and choose a password.\n\n"+
+				"If this was not you, ignore this email; nothing changes until the link is used.",
+			uc.publicURL,
+			token,
+			int(claimLinkTTL.Hours()),
+		),
+	}
+	
+	if err := uc.notifier.Notify(ctx, msg); err != nil {
+		log.Printf("Failed to send registration link to guest %d: %v", guest.ID, err)
+	}
+	return nil
+}
+
 func (uc *AuthUsecase) Login(email, password string) (*data.User, string, error) {
 	user, err := uc.userRepo.FindByEmail(email)
 	if err != nil {
 		return nil, "", err
 	}
 	
-	if user == nil {
+	if user == nil || user.Role == data.RoleGuest {
 		return nil, "", errors.New("user not found")
 	}
 	
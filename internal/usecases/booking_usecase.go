@@ -1,93 +1,629 @@
 package usecases
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"log"
+	"strings"
 	"time"
 	
 	"hotel-booking-service/internal/data"
+	"hotel-booking-service/internal/events"
+	"hotel-booking-service/internal/pkg/apperror"
+	"hotel-booking-service/internal/pkg/confcode"
+	"hotel-booking-service/internal/pkg/money"
 	"hotel-booking-service/internal/repositories"
 )
 
 type BookingUsecase struct {
-	bookingRepo *repositories.BookingRepository
-	roomRepo    *repositories.RoomRepository
+	bookingRepo     *repositories.BookingRepository
+	roomRepo        *repositories.RoomRepository
+	roomTypeRepo    *repositories.RoomTypeRepository
+	policyRepo      *repositories.CancellationPolicyRepository
+	userRepo        *repositories.UserRepository
+	currencyUsecase *CurrencyUsecase
+	paymentUsecase  *PaymentUsecase
+	invoiceUsecase  *InvoiceUsecase
+	bus             *events.Bus
+	inventory       *InventoryUsecase
+	restrictions    *RestrictionUsecase
+	loyalty         *LoyaltyUsecase
+	extras          *ExtraUsecase
 }
 
 func NewBookingUsecase(
 	bookingRepo *repositories.BookingRepository,
 	roomRepo *repositories.RoomRepository,
+	roomTypeRepo *repositories.RoomTypeRepository,
+	policyRepo *repositories.CancellationPolicyRepository,
+	userRepo *repositories.UserRepository,
+	currencyUsecase *CurrencyUsecase,
+	paymentUsecase *PaymentUsecase,
+	invoiceUsecase *InvoiceUsecase,
+	bus *events.Bus,
+	inventory *InventoryUsecase,
+	restrictions *RestrictionUsecase,
+	loyalty *LoyaltyUsecase,
+	extras *ExtraUsecase,
 ) *BookingUsecase {
 	return &BookingUsecase{
-		bookingRepo: bookingRepo,
-		roomRepo:    roomRepo,
+		bookingRepo:     bookingRepo,
+		roomRepo:        roomRepo,
+		roomTypeRepo:    roomTypeRepo,
+		policyRepo:      policyRepo,
+		userRepo:        userRepo,
+		currencyUsecase: currencyUsecase,
+		paymentUsecase:  paymentUsecase,
+		invoiceUsecase:  invoiceUsecase,
+		bus:             bus,
+		inventory:       inventory,
+		restrictions:    restrictions,
+		loyalty:         loyalty,
+		extras:          extras,
 	}
 }
 
-func (uc *BookingUsecase) CreateBooking(userID, roomID int, fromDate, toDate time.Time) (*data.Booking, error) {
+// CreateBooking reserves a unit of a room type. When roomID is given the
+// guest gets that particular room, and its type is booked. Requested extras
+// are added to the price, then redeemPoints loyalty points, if any, are
+// taken off it.
+func (uc *BookingUsecase) CreateBooking(userID, roomTypeID, roomID int, fromDate, toDate time.Time, currency string, redeemPoints int, extras []data.ExtraRequest) (*data.Booking, error) {
+	currency, err := normalizeCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
 	
 	if fromDate.After(toDate) {
 		return nil, errors.New("from date must be before to date")
 	}
 	
+	if nightsBetween(fromDate, toDate) < 1 {
+		return nil, errors.New("stay must be at least one night")
+	}
+	
 	if fromDate.Before(time.Now()) {
 		return nil, errors.New("from date must be in the future")
 	}
 	
-	room, err := uc.roomRepo.GetByID(roomID)
+	var room *data.Room
+	if roomID != 0 {
+		room, err = uc.roomRepo.GetByID(roomID)
+		if err != nil {
+			return nil, err
+		}
+		
+		if room == nil {
+			return nil, errors.New("room not found")
+		}
+		
+		if roomTypeID != 0 && roomTypeID != room.RoomTypeID {
+			return nil, fmt.Errorf("%w: room %s is not of the requested room type", apperror.ErrInvalidRequest, room.Number)
+		}
+		roomTypeID = room.RoomTypeID
+	}
+	
+	roomType, err := uc.roomTypeRepo.GetByID(roomTypeID)
 	if err != nil {
 		return nil, err
 	}
 	
-	if room == nil {
-		return nil, errors.New("room not found")
+	if roomType == nil {
+		return nil, fmt.Errorf("%w: room type not found", apperror.ErrNotFound)
 	}
 	
-	available, err := uc.roomRepo.CheckRoomAvailability(roomID, fromDate, toDate)
+	violations, err := uc.restrictions.CheckStay(roomType, fromDate, toDate)
 	if err != nil {
 		return nil, err
 	}
 	
-	if !available {
+	if len(violations) > 0 {
+		return nil, restrictionError(violations)
+	}
+	
+	// Availability is checked while the booking is stored, with the room
+	// type locked, so concurrent bookings cannot oversell it.
+	available := func() (bool, error) {
+		// A room the guest asked for is never sold twice, whatever the
+		// allowance.
+		if room != nil {
+			free, err := uc.roomRepo.CheckRoomAvailability(room.ID, fromDate, toDate)
+			if err != nil || !free {
+				return false, err
+			}
+		}
+		
+		units, err := uc.roomTypeRepo.AvailableUnits(roomType.ID, fromDate, toDate)
+		if err != nil {
+			return false, err
+		}
+		
+		// A sold-out type can still be sold while it is within the hotel's
+		// overbooking allowance; the front desk sorts out the clash.
+		if units == 0 {
+			return uc.inventory.CanOversell(roomType, room, fromDate, toDate)
+		}
+		return true, nil
+	}
+	
+	nightly, total, _ := quoteStay(roomType, fromDate, toDate)
+	
+	policy, err := resolvePolicy(uc.policyRepo, roomType)
+	if err != nil {
+		return nil, err
+	}
+	
+	booking := &data.Booking{
+		UserID:             userID,
+		RoomTypeID:         roomType.ID,
+		RoomID:             roomID,
+		FromDate:           fromDate,
+		ToDate:             toDate,
+		NightlyRate:        nightly,
+		TotalPrice:         total,
+		CancellationPolicy: policy,
+	}
+	
+	// Extras and points are held before the booking is made and given back
+	// if it is not.
+	var held []data.BookingExtra
+	var redemption *data.LoyaltyEntry
+	release := func() {
+		if held != nil {
+			uc.extras.Release(held)
+		}
+		if redemption != nil {
+			uc.loyalty.ReleaseRedemption(redemption)
+		}
+	}
+	
+	if len(extras) > 0 {
+		lines, err := uc.extras.Price(roomType, fromDate, toDate, extras)
+		if err != nil {
+			return nil, err
+		}
+		
+		held, err = uc.extras.Hold(lines)
+		if err != nil {
+			return nil, err
+		}
+		
+		extrasTotal, err := sumExtras(held)
+		if err == nil {
+			total, err = total.Add(extrasTotal)
+		}
+		if err != nil {
+			release()
+			return nil, err
+		}
+		booking.TotalPrice = total
+		booking.ExtrasTotal = &extrasTotal
+	}
+	
+	if redeemPoints > 0 {
+		var discount money.Money
+		redemption, discount, err = uc.loyalty.Redeem(userID, redeemPoints, total)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		
+		booking.TotalPrice, err = total.Sub(discount)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		booking.PointsRedeemed = redeemPoints
+		booking.LoyaltyDiscount = &discount
+		total = booking.TotalPrice
+	}
+	
+	// The guest is charged in the hotel currency; the converted total and the
+	// rate behind it are kept so the booking shows what the guest was quoted.
+	if currency != "" && currency != total.Currency {
+		displayTotal, rate, err := uc.currencyUsecase.Convert(total, currency)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		booking.DisplayCurrency = currency
+		booking.ExchangeRate = rate
+		booking.DisplayTotal = &displayTotal
+	}
+	
+	created, err := uc.bookingRepo.CreateBooking(booking, available)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	
+	if created == nil {
+		release()
 		return nil, errors.New("room not available for the selected dates")
 	}
 	
-	booking, err := uc.bookingRepo.CreateBooking(userID, roomID, fromDate, toDate)
+	if held != nil {
+		uc.extras.Attach(held, created.ID)
+		created.Extras = held
+	}
+	if redemption != nil {
+		uc.loyalty.AttachRedemption(redemption, created.ID)
+	}
+	
+	return created, nil
+}
+
+// CancelBooking cancels the booking if it is still at version, the one the
+// guest last read.
+func (uc *BookingUsecase) CancelBooking(userID, bookingID, version int) (*data.CancellationResult, error) {
+	result, err := uc.PreviewCancellation(userID, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	
+	cancelled, err := uc.bookingRepo.CancelBooking(bookingID, version, result.Penalty, result.Refund)
+	if err != nil {
+		return nil, err
+	}
+	
+	if !cancelled {
+		booking, err := uc.bookingRepo.GetBooking(bookingID)
+		if err != nil {
+			return nil, err
+		}
+		if booking != nil && booking.Version != version {
+			return nil, errStale("booking")
+		}
+		if booking != nil && (booking.Status == data.BookingStatusCheckedIn || booking.Status == data.BookingStatusCheckedOut) {
+			return nil, errStayStarted
+		}
+		return nil, errors.New("booking is already cancelled")
+	}
+	
+	uc.afterRelease(events.BookingCancelled, bookingID, result.Penalty, result.Refund)
+	
+	return result, nil
+}
+
+// WalkGuest moves a guest the hotel cannot accommodate to a partner hotel.
+// The booking is released and the guest refunded in full.
+func (uc *BookingUsecase) WalkGuest(walk data.BookingWalk) (*data.BookingWalk, error) {
+	if walk.PartnerName == "" {
+		return nil, fmt.Errorf("%w: partner_name is required", apperror.ErrInvalidRequest)
+	}
+	
+	booking, err := uc.bookingRepo.GetBooking(walk.BookingID)
+	if err != nil {
+		return nil, err
+	}
+	
+	if booking == nil {
+		return nil, errors.New("booking not found")
+	}
+	
+	if booking.Status == data.BookingStatusCheckedIn || booking.Status == data.BookingStatusCheckedOut {
+		return nil, fmt.Errorf("%w: guest has already checked in", apperror.ErrConflict)
+	}
+	
+	walked, err := uc.bookingRepo.WalkBooking(&walk, booking.TotalPrice)
 	if err != nil {
 		return nil, err
 	}
 	
-	return booking, nil
+	if walked == nil {
+		return nil, fmt.Errorf("%w: booking is already %s", apperror.ErrConflict, booking.Status)
+	}
+	
+	uc.afterRelease(events.BookingCancelled, booking.ID, money.Zero(booking.TotalPrice.Currency), booking.TotalPrice)
+	
+	return walked, nil
 }
 
-func (uc *BookingUsecase) CancelBooking(userID, bookingID int) error {
+// ReassignRoom moves a booking to another room of the same hotel, e.g. to
+// clear a clash on an oversold room or to upgrade the guest. The price the
+// guest agreed stays.
+func (uc *BookingUsecase) ReassignRoom(bookingID, roomID int) (*data.Booking, error) {
 	booking, err := uc.bookingRepo.GetBooking(bookingID)
+	if err != nil {
+		return nil, err
+	}
+	
+	if booking == nil {
+		return nil, errors.New("booking not found")
+	}
+	
+	target, err := uc.roomForStay(booking, roomID)
+	if err != nil {
+		return nil, err
+	}
+	
+	moved, err := uc.bookingRepo.ReassignRoom(bookingID, roomID, target.RoomTypeID)
+	if err != nil {
+		return nil, err
+	}
+	
+	if !moved {
+		return nil, fmt.Errorf("%w: booking is already %s", apperror.ErrConflict, booking.Status)
+	}
+	
+	return uc.bookingRepo.GetBooking(bookingID)
+}
+
+// roomForStay checks that the booking can move to the room without moving
+// it: the room must be one of the booking's hotel's and free for the stay,
+// and moving to another type takes a unit of that type.
+func (uc *BookingUsecase) roomForStay(booking *data.Booking, roomID int) (*data.Room, error) {
+	target, err := uc.roomRepo.GetByID(roomID)
+	if err != nil {
+		return nil, err
+	}
+	
+	if target == nil || target.HotelID != booking.HotelID {
+		return nil, errors.New("room not found")
+	}
+	
+	available, err := uc.roomRepo.CheckRoomAvailability(roomID, booking.FromDate, booking.ToDate)
+	if err != nil {
+		return nil, err
+	}
+	
+	if available && target.RoomTypeID != booking.RoomTypeID {
+		units, err := uc.roomTypeRepo.AvailableUnits(target.RoomTypeID, booking.FromDate, booking.ToDate)
+		if err != nil {
+			return nil, err
+		}
+		available = units > 0
+	}
+	
+	if !available {
+		return nil, fmt.Errorf("%w: room %s is not free for this stay", apperror.ErrConflict, target.Number)
+	}
+	
+	return target, nil
+}
+
+// AssignRooms gives every booking arriving by the given day that has no
+// room yet the first room of its type that is free for the whole stay.
+// Bookings no room can take are left for the front desk, who see them as
+// unassigned in the calendar.
+func (uc *BookingUsecase) AssignRooms(ctx context.Context, arrivingBy time.Time) error {
+	bookings, err := uc.bookingRepo.GetUnassignedArrivals(arrivingBy)
+	if err != nil {
+		return err
+	}
+	
+	for i := range bookings {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if _, err := uc.AssignRoom(&bookings[i]); err != nil {
+			log.Printf("Failed to assign a room to booking %d: %v", bookings[i].ID, err)
+		}
+	}
+	return nil
+}
+
+// AssignRoom picks a room for a booking that has none. It returns the
+// booking unchanged if it already has a room.
+func (uc *BookingUsecase) AssignRoom(booking *data.Booking) (*data.Booking, error) {
+	if booking.RoomID != 0 {
+		return booking, nil
+	}
+	
+	rooms, err := uc.freeRooms(booking)
+	if err != nil {
+		return nil, err
+	}
+	
+	if len(rooms) == 0 {
+		return nil, fmt.Errorf("%w: no room of this type is free for the whole stay", apperror.ErrConflict)
+	}
+	
+	assigned, err := uc.bookingRepo.AssignRoom(booking.ID, rooms[0].ID)
+	if err != nil {
+		return nil, err
+	}
+	if !assigned {
+		return nil, fmt.Errorf("%w: booking %d was assigned or released meanwhile", apperror.ErrConflict, booking.ID)
+	}
+	return uc.bookingRepo.GetBooking(booking.ID)
+}
+
+// freeRooms lists the rooms of the booking's type that are free for its
+// whole stay.
+func (uc *BookingUsecase) freeRooms(booking *data.Booking) ([]data.Room, error) {
+	rooms, err := uc.roomRepo.GetRoomsByType(booking.RoomTypeID)
+	if err != nil {
+		return nil, err
+	}
+	
+	var free []data.Room
+	for _, room := range rooms {
+		available, err := uc.roomRepo.CheckRoomAvailability(room.ID, booking.FromDate, booking.ToDate)
+		if err != nil {
+			return nil, err
+		}
+		if available {
+			free = append(free, room)
+		}
+	}
+	return free, nil
+}
+
+// MarkNoShows releases confirmed bookings whose guest has not checked in by
+// the hotel's no-show cutoff, charging the cancellation policy's penalty as
+// for a cancellation on the arrival day. Each booking is claimed with a
+// conditional update, so reruns and other instances running the same job
+// never settle a no-show twice.
+func (uc *BookingUsecase) MarkNoShows(ctx context.Context) error {
+	bookings, err := uc.bookingRepo.GetNoShows(time.Now())
 	if err != nil {
 		return err
 	}
 	
+	for i := range bookings {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		
+		booking := &bookings[i]
+		result, err := cancellationOutcome(booking, booking.FromDate)
+		if err != nil {
+			log.Printf("Failed to price no-show for booking %d: %v", booking.ID, err)
+			continue
+		}
+		
+		marked, err := uc.bookingRepo.MarkNoShow(booking.ID, result.Penalty, result.Refund)
+		if err != nil {
+			return err
+		}
+		if !marked {
+			continue
+		}
+		
+		log.Printf("Marked booking %d as a no-show", booking.ID)
+		uc.afterRelease(events.BookingNoShow, booking.ID, result.Penalty, result.Refund)
+	}
+	return nil
+}
+
+// afterRelease settles money for a booking that no longer holds its room and
+// announces it. The booking is released first so a retry can never settle
+// twice; a failed settlement is left for an admin to capture or refund by
+// hand.
+func (uc *BookingUsecase) afterRelease(event string, bookingID int, penalty, refund money.Money) {
+	if err := uc.paymentUsecase.SettleCancellation(context.Background(), bookingID, penalty, refund); err != nil {
+		log.Printf("Failed to settle payment for booking %d: %v", bookingID, err)
+	}
+	
+	if err := uc.invoiceUsecase.CreditCancellation(bookingID, refund); err != nil {
+		log.Printf("Failed to issue credit note for booking %d: %v", bookingID, err)
+	}
+	
+	if booking, err := uc.bookingRepo.GetBooking(bookingID); err == nil && booking != nil {
+		uc.bus.Publish(context.Background(), event, *booking)
+	}
+}
+
+// errStayStarted refuses to cancel a booking once the guest has checked in;
+// the front desk settles early departures.
+var errStayStarted = fmt.Errorf("%w: guest has checked in and the booking can no longer be cancelled", apperror.ErrConflict)
+
+// PreviewCancellation shows the penalty and refund a cancellation would
+// produce today, using the policy snapshotted when the booking was made.
+// Bookings whose guest has checked in cannot be cancelled.
+func (uc *BookingUsecase) PreviewCancellation(userID, bookingID int) (*data.CancellationResult, error) {
+	booking, err := uc.bookingRepo.GetBooking(bookingID)
+	if err != nil {
+		return nil, err
+	}
+	
 	if booking == nil {
-		return errors.New("booking not found")
+		return nil, errors.New("booking not found")
 	}
 	
 	if booking.UserID != userID {
-		return errors.New("booking does not belong to this user")
+		return nil, errors.New("booking does not belong to this user")
+	}
+	
+	if booking.Status == data.BookingStatusCancelled {
+		return nil, errors.New("booking is already cancelled")
+	}
+	
+	if booking.Status == data.BookingStatusWalked {
+		return nil, fmt.Errorf("%w: booking was moved to a partner hotel", apperror.ErrConflict)
 	}
 	
-	if booking.Status == "cancelled" {
-		return errors.New("booking is already cancelled")
+	if booking.Status == data.BookingStatusNoShow {
+		return nil, fmt.Errorf("%w: guest did not arrive and the booking was released", apperror.ErrConflict)
 	}
 	
-	return uc.bookingRepo.UpdateBookingStatus(bookingID, "cancelled")
+	if booking.Status == data.BookingStatusCheckedIn || booking.Status == data.BookingStatusCheckedOut {
+		return nil, errStayStarted
+	}
+	
+	return cancellationOutcome(booking, time.Now())
 }
 
-func (uc *BookingUsecase) GetUserBookings(userID int) ([]data.Booking, error) {
-	return uc.bookingRepo.GetUserBookings(userID)
+// LookupBooking finds a booking for a guest who is not logged in, by its
+// confirmation code and the guest's last name or email. A wrong code and a
+// wrong name give the same answer, so the lookup reveals nothing about which
+// codes exist.
+func (uc *BookingUsecase) LookupBooking(req data.BookingLookupRequest) (*data.Booking, error) {
+	code := confcode.Normalize(req.ConfirmationCode)
+	lastName := strings.TrimSpace(req.LastName)
+	email := strings.TrimSpace(req.Email)
+	if code == "" {
+		return nil, fmt.Errorf("%w: confirmation_code must look like %sXXXXXX", apperror.ErrInvalidRequest, confcode.Prefix)
+	}
+	if lastName == "" && email == "" {
+		return nil, fmt.Errorf("%w: last_name or email is required", apperror.ErrInvalidRequest)
+	}
+	
+	notFound := fmt.Errorf("%w: no booking matches these details", apperror.ErrNotFound)
+	
+	booking, err := uc.bookingRepo.GetByConfirmationCode(code)
+	if err != nil {
+		return nil, err
+	}
+	if booking == nil {
+		return nil, notFound
+	}
+	
+	guest, err := uc.userRepo.GetByID(booking.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if guest == nil {
+		return nil, notFound
+	}
+	
+	matches := email != "" && strings.EqualFold(email, guest.Email)
+	if lastName != "" {
+		names := strings.Fields(guest.Name)
+		matches = matches || (len(names) > 0 && strings.EqualFold(lastName, names[len(names)-1]))
+	}
+	if !matches {
+		return nil, notFound
+	}
+	
+	// The identity document is for the front desk only.
+	booking.GuestIDDocument = ""
+	return booking, uc.extras.FillBooking(booking)
 }
 
-func (uc *BookingUsecase) GetBookingByID(bookingID int) (*data.Booking, error) {
-	return uc.bookingRepo.GetBooking(bookingID)
+func (uc *BookingUsecase) GetUserBookings(userID int) ([]data.Booking, error) {
+	bookings, err := uc.bookingRepo.GetUserBookings(userID)
+	if err != nil {
+		return nil, err
+	}
+	
+	return bookings, uc.extras.Fill(bookings)
 }
 
-func (uc *BookingUsecase) UpdateBooking(bookingID int, status string) error {
-	return uc.bookingRepo.UpdateBookingStatus(bookingID, status)
-}
\ No newline at end of file
+// GetBookingByID returns one of the user's bookings. Staff may read their
+// hotel's bookings, and only they see the guest's identity document.
+func (uc *BookingUsecase) GetBookingByID(userID, bookingID int) (*data.Booking, error) {
+	booking, err := uc.bookingRepo.GetBooking(bookingID)
+	if err != nil {
+		return nil, err
+	}
+	
+	if booking == nil {
+		return nil, errors.New("booking not found")
+	}
+	
+	user, err := uc.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	
+	if !worksAt(user, booking.HotelID) {
+		if booking.UserID != userID {
+			return nil, errors.New("booking does not belong to this user")
+		}
+		booking.GuestIDDocument = ""
+	}
+	
+	return booking, uc.extras.FillBooking(booking)
+}
@@ -0,0 +1,170 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"hotel-booking-service/internal/data"
+	"hotel-booking-service/internal/pkg/apperror"
+	"hotel-booking-service/internal/pkg/money"
+	"hotel-booking-service/internal/repositories"
+)
+
+// defaultCancellationPolicy applies to hotels that have not configured one:
+// free cancellation up to the arrival date.
+var defaultCancellationPolicy = data.CancellationPolicy{
+	Name:        "Flexible",
+	PenaltyType: data.PenaltyNone,
+}
+
+type CancellationPolicyUsecase struct {
+	policyRepo   *repositories.CancellationPolicyRepository
+	hotelRepo    *repositories.HotelRepository
+	roomTypeRepo *repositories.RoomTypeRepository
+}
+
+func NewCancellationPolicyUsecase(
+	policyRepo *repositories.CancellationPolicyRepository,
+	hotelRepo *repositories.HotelRepository,
+	roomTypeRepo *repositories.RoomTypeRepository,
+) *CancellationPolicyUsecase {
+	return &CancellationPolicyUsecase{
+		policyRepo:   policyRepo,
+		hotelRepo:    hotelRepo,
+		roomTypeRepo: roomTypeRepo,
+	}
+}
+
+func (uc *CancellationPolicyUsecase) GetHotelPolicies(hotelID int) ([]data.CancellationPolicy, error) {
+	return uc.policyRepo.GetByHotelID(hotelID)
+}
+
+func (uc *CancellationPolicyUsecase) CreatePolicy(policy data.CancellationPolicy) (*data.CancellationPolicy, error) {
+	if err := validateCancellationPolicy(policy); err != nil {
+		return nil, err
+	}
+
+	hotel, err := uc.hotelRepo.GetByID(policy.HotelID)
+	if err != nil {
+		return nil, err
+	}
+	if hotel == nil {
+		return nil, errors.New("hotel not found")
+	}
+
+	if policy.RoomTypeID != nil {
+		roomType, err := uc.roomTypeRepo.GetByID(*policy.RoomTypeID)
+		if err != nil {
+			return nil, err
+		}
+		if roomType == nil || roomType.HotelID != policy.HotelID {
+			return nil, fmt.Errorf("%w: room type not found", apperror.ErrNotFound)
+		}
+	}
+
+	return uc.policyRepo.Create(&policy)
+}
+
+func (uc *CancellationPolicyUsecase) UpdatePolicy(policy data.CancellationPolicy) (*data.CancellationPolicy, error) {
+	existing, err := uc.policyRepo.GetByID(policy.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, errors.New("cancellation policy not found")
+	}
+
+	policy.HotelID = existing.HotelID
+	policy.RoomTypeID = existing.RoomTypeID
+	policy.CreatedAt = existing.CreatedAt
+	if err := validateCancellationPolicy(policy); err != nil {
+		return nil, err
+	}
+
+	return uc.policyRepo.Update(&policy)
+}
+
+func (uc *CancellationPolicyUsecase) DeletePolicy(id int) error {
+	return uc.policyRepo.Delete(id)
+}
+
+func validateCancellationPolicy(policy data.CancellationPolicy) error {
+	if policy.Name == "" {
+		return fmt.Errorf("%w: policy name is required", apperror.ErrInvalidRequest)
+	}
+	if policy.FreeCancellationDays < 0 {
+		return fmt.Errorf("%w: free_cancellation_days cannot be negative", apperror.ErrInvalidRequest)
+	}
+
+	switch policy.PenaltyType {
+	case data.PenaltyNone, data.PenaltyFirstNight, data.PenaltyFull:
+	case data.PenaltyPercent:
+		if policy.PenaltyPercent <= 0 || policy.PenaltyPercent > 100 {
+			return fmt.Errorf("%w: penalty_percent must be between 1 and 100", apperror.ErrInvalidRequest)
+		}
+	default:
+		return fmt.Errorf("%w: penalty_type must be one of none, percent, first_night, full", apperror.ErrInvalidRequest)
+	}
+	return nil
+}
+
+// resolvePolicy picks the policy to snapshot onto a new booking: the room
+// type's own policy, then the hotel default, then the built-in flexible
+// policy.
+func resolvePolicy(policyRepo *repositories.CancellationPolicyRepository, roomType *data.RoomType) (*data.CancellationPolicy, error) {
+	policy, err := policyRepo.FindForRoomType(roomType.HotelID, roomType.ID)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		fallback := defaultCancellationPolicy
+		fallback.HotelID = roomType.HotelID
+		return &fallback, nil
+	}
+	return policy, nil
+}
+
+// cancellationOutcome computes what a guest forfeits when cancelling on the
+// given day. Penalties never exceed the booking total, and the refund is
+// whatever remains of it.
+func cancellationOutcome(booking *data.Booking, today time.Time) (*data.CancellationResult, error) {
+	policy := defaultCancellationPolicy
+	if booking.CancellationPolicy != nil {
+		policy = *booking.CancellationPolicy
+	}
+
+	daysBefore := nightsBetween(today, booking.FromDate)
+	if daysBefore < 0 {
+		return nil, errors.New("stays that have already started cannot be cancelled")
+	}
+
+	total := booking.TotalPrice
+	penalty := money.Zero(total.Currency)
+
+	switch {
+	case policy.NonRefundable:
+		penalty = total
+	case daysBefore >= policy.FreeCancellationDays:
+		// Still inside the free cancellation window.
+	case policy.PenaltyType == data.PenaltyPercent:
+		penalty = total.Percent(int64(policy.PenaltyPercent) * 100)
+	case policy.PenaltyType == data.PenaltyFirstNight:
+		penalty = booking.NightlyRate.Min(total)
+	case policy.PenaltyType == data.PenaltyFull:
+		penalty = total
+	}
+
+	refund, err := total.Sub(penalty)
+	if err != nil {
+		return nil, err
+	}
+
+	return &data.CancellationResult{
+		BookingID:         booking.ID,
+		DaysBeforeArrival: daysBefore,
+		Penalty:           penalty,
+		Refund:            refund,
+		Policy:            policy,
+	}, nil
+}
@@ -0,0 +1,185 @@
+package usecases
+
+import (
+	"fmt"
+	"strings"
+
+	"hotel-booking-service/internal/data"
+	"hotel-booking-service/internal/pkg/apperror"
+	"hotel-booking-service/internal/pkg/money"
+	"hotel-booking-service/internal/repositories"
+)
+
+type CurrencyUsecase struct {
+	rateRepo *repositories.ExchangeRateRepository
+}
+
+func NewCurrencyUsecase(rateRepo *repositories.ExchangeRateRepository) *CurrencyUsecase {
+	return &CurrencyUsecase{rateRepo: rateRepo}
+}
+
+func (uc *CurrencyUsecase) GetRates() ([]data.ExchangeRate, error) {
+	return uc.rateRepo.GetAll()
+}
+
+func (uc *CurrencyUsecase) UpdateRates(rates []data.ExchangeRate) error {
+	if len(rates) == 0 {
+		return fmt.Errorf("%w: at least one exchange rate is required", apperror.ErrInvalidRequest)
+	}
+
+	for i := range rates {
+		rates[i].BaseCurrency = strings.ToUpper(rates[i].BaseCurrency)
+		rates[i].QuoteCurrency = strings.ToUpper(rates[i].QuoteCurrency)
+
+		if !money.ValidCurrency(rates[i].BaseCurrency) || !money.ValidCurrency(rates[i].QuoteCurrency) {
+			return fmt.Errorf("%w: invalid currency pair %s/%s", apperror.ErrInvalidRequest, rates[i].BaseCurrency, rates[i].QuoteCurrency)
+		}
+		if rates[i].BaseCurrency == rates[i].QuoteCurrency {
+			return fmt.Errorf("%w: currency pair %s/%s must use two different currencies", apperror.ErrInvalidRequest, rates[i].BaseCurrency, rates[i].QuoteCurrency)
+		}
+		if !money.ValidRate(rates[i].Rate) {
+			return fmt.Errorf("%w: invalid rate %q for %s/%s", apperror.ErrInvalidRequest, rates[i].Rate, rates[i].BaseCurrency, rates[i].QuoteCurrency)
+		}
+	}
+
+	return uc.rateRepo.Upsert(rates)
+}
+
+func (uc *CurrencyUsecase) DeleteRate(base, quote string) error {
+	return uc.rateRepo.Delete(strings.ToUpper(base), strings.ToUpper(quote))
+}
+
+// Rate returns how many units of `to` one unit of `from` buys. A stored
+// inverse pair is used when the direct pair has not been loaded.
+func (uc *CurrencyUsecase) Rate(from, to string) (string, error) {
+	rate, ok, err := uc.findRate(from, to)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", fmt.Errorf("%w: exchange rate %s/%s not available", apperror.ErrInvalidRequest, strings.ToUpper(from), strings.ToUpper(to))
+	}
+	return rate, nil
+}
+
+// findRate is Rate, reporting false when neither pair has been loaded.
+func (uc *CurrencyUsecase) findRate(from, to string) (string, bool, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	if from == to {
+		return "1", true, nil
+	}
+
+	rate, err := uc.rateRepo.Get(from, to)
+	if err != nil {
+		return "", false, err
+	}
+	if rate != nil {
+		return rate.Rate, true, nil
+	}
+
+	inverse, err := uc.rateRepo.Get(to, from)
+	if err != nil {
+		return "", false, err
+	}
+	if inverse != nil {
+		rate, err := money.InvertRate(inverse.Rate)
+		return rate, err == nil, err
+	}
+
+	return "", false, nil
+}
+
+func (uc *CurrencyUsecase) Convert(amount money.Money, to string) (money.Money, string, error) {
+	rate, err := uc.Rate(amount.Currency, to)
+	if err != nil {
+		return money.Money{}, "", err
+	}
+
+	converted, err := money.Convert(amount, rate, to)
+	if err != nil {
+		return money.Money{}, "", err
+	}
+
+	return converted, rate, nil
+}
+
+// ConvertRooms fills in ConvertedPrice for display. Rates are looked up once
+// per source currency.
+func (uc *CurrencyUsecase) ConvertRooms(rooms []data.Room, to string) error {
+	rates := map[string]string{}
+	for i := range rooms {
+		from := rooms[i].Price.Currency
+		rate, ok := rates[from]
+		if !ok {
+			var err error
+			rate, err = uc.Rate(from, to)
+			if err != nil {
+				return err
+			}
+			rates[from] = rate
+		}
+
+		converted, err := money.Convert(rooms[i].Price, rate, to)
+		if err != nil {
+			return err
+		}
+		rooms[i].ConvertedPrice = &converted
+	}
+	return nil
+}
+
+// ConvertRoomTypes fills in ConvertedPrice for display, like ConvertRooms.
+func (uc *CurrencyUsecase) ConvertRoomTypes(roomTypes []data.RoomType, to string) error {
+	rates := map[string]string{}
+	for i := range roomTypes {
+		from := roomTypes[i].Price.Currency
+		rate, ok := rates[from]
+		if !ok {
+			var err error
+			rate, err = uc.Rate(from, to)
+			if err != nil {
+				return err
+			}
+			rates[from] = rate
+		}
+
+		converted, err := money.Convert(roomTypes[i].Price, rate, to)
+		if err != nil {
+			return err
+		}
+		roomTypes[i].ConvertedPrice = &converted
+	}
+	return nil
+}
+
+// ConversionFactors returns, for each currency, what its minor units are
+// multiplied by to give minor units of `to`, so that prices in several
+// currencies can be compared in the database. Currencies without an
+// exchange rate to `to` are left out.
+func (uc *CurrencyUsecase) ConversionFactors(currencies []string, to string) (map[string]string, error) {
+	factors := make(map[string]string, len(currencies))
+	for _, from := range currencies {
+		rate, ok, err := uc.findRate(from, to)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		factor, err := money.ConversionFactor(rate, from, to)
+		if err != nil {
+			return nil, err
+		}
+		factors[from] = factor
+	}
+	return factors, nil
+}
+
+func normalizeCurrency(currency string) (string, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency != "" && !money.ValidCurrency(currency) {
+		return "", fmt.Errorf("%w: invalid currency %q", apperror.ErrInvalidRequest, currency)
+	}
+	return currency, nil
+}
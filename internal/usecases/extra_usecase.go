@@ -0,0 +1,377 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"hotel-booking-service/internal/data"
+	"hotel-booking-service/internal/pkg/apperror"
+	"hotel-booking-service/internal/pkg/money"
+	"hotel-booking-service/internal/repositories"
+)
+
+const maxExtraQuantity = 99
+
+// ExtraUsecase sells hotel extras such as breakfast or parking with a
+// booking, or later while the booking is live. An extra is priced when it is
+// added; later price changes leave existing bookings alone.
+type ExtraUsecase struct {
+	extraRepo    *repositories.ExtraRepository
+	bookingRepo  *repositories.BookingRepository
+	roomTypeRepo *repositories.RoomTypeRepository
+	hotelRepo    *repositories.HotelRepository
+	paymentRepo  *repositories.PaymentRepository
+}
+
+func NewExtraUsecase(
+	extraRepo *repositories.ExtraRepository,
+	bookingRepo *repositories.BookingRepository,
+	roomTypeRepo *repositories.RoomTypeRepository,
+	hotelRepo *repositories.HotelRepository,
+	paymentRepo *repositories.PaymentRepository,
+) *ExtraUsecase {
+	return &ExtraUsecase{
+		extraRepo:    extraRepo,
+		bookingRepo:  bookingRepo,
+		roomTypeRepo: roomTypeRepo,
+		hotelRepo:    hotelRepo,
+		paymentRepo:  paymentRepo,
+	}
+}
+
+// GetHotelExtras lists the extras a hotel sells. With a stay, extras with
+// limited inventory show how many are left on every night of it.
+func (uc *ExtraUsecase) GetHotelExtras(hotelID int, fromDate, toDate time.Time) ([]data.Extra, error) {
+	extras, err := uc.extraRepo.GetByHotelID(hotelID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if nightsBetween(fromDate, toDate) < 1 {
+		return extras, nil
+	}
+
+	remaining, err := uc.extraRepo.Remaining(hotelID, fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range extras {
+		units, limited := remaining[extras[i].ID]
+		if !limited {
+			continue
+		}
+		if units < 0 {
+			units = 0
+		}
+		extras[i].Available = &units
+	}
+	return extras, nil
+}
+
+// GetAllHotelExtras lists every extra of the hotel, including those no
+// longer sold.
+func (uc *ExtraUsecase) GetAllHotelExtras(hotelID int) ([]data.Extra, error) {
+	return uc.extraRepo.GetByHotelID(hotelID, true)
+}
+
+// CreateExtra adds an extra to the hotel's offer. New extras are on sale
+// straight away.
+func (uc *ExtraUsecase) CreateExtra(extra data.Extra) (*data.Extra, error) {
+	extra.Active = true
+	if err := uc.validateExtra(&extra); err != nil {
+		return nil, err
+	}
+	return uc.extraRepo.Create(&extra)
+}
+
+// UpdateExtra replaces the extra's terms; active false stops selling it.
+func (uc *ExtraUsecase) UpdateExtra(extra data.Extra) (*data.Extra, error) {
+	existing, err := uc.extraRepo.GetByID(extra.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, fmt.Errorf("%w: extra not found", apperror.ErrNotFound)
+	}
+
+	extra.HotelID = existing.HotelID
+	if err := uc.validateExtra(&extra); err != nil {
+		return nil, err
+	}
+
+	updated, err := uc.extraRepo.Update(&extra)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, fmt.Errorf("%w: extra not found", apperror.ErrNotFound)
+	}
+	return updated, nil
+}
+
+// DeleteExtra removes an extra that was never booked. One that was can
+// only be deactivated, so the bookings that have it keep it.
+func (uc *ExtraUsecase) DeleteExtra(id int) error {
+	existing, err := uc.extraRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return fmt.Errorf("%w: extra not found", apperror.ErrNotFound)
+	}
+
+	deleted, err := uc.extraRepo.Delete(id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return fmt.Errorf("%w: extra has been booked; set active to false to stop selling it", apperror.ErrConflict)
+	}
+	return nil
+}
+
+// AddToBooking adds extras to the guest's booking and raises its total.
+// Only unpaid bookings take extras: the payment is for the total when it
+// is opened, and nothing would collect or invoice extras added later.
+func (uc *ExtraUsecase) AddToBooking(userID, bookingID int, requests []data.ExtraRequest) (*data.Booking, error) {
+	booking, err := uc.bookingRepo.GetBooking(bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if booking == nil {
+		return nil, errors.New("booking not found")
+	}
+
+	if booking.UserID != userID {
+		return nil, errors.New("booking does not belong to this user")
+	}
+
+	switch booking.Status {
+	case data.BookingStatusPending:
+		// A payment already opened is for the old total.
+		payment, err := uc.paymentRepo.GetOpenForBooking(booking.ID)
+		if err != nil {
+			return nil, err
+		}
+		if payment != nil {
+			return nil, fmt.Errorf("%w: booking has a payment in progress; add extras once it completes", apperror.ErrConflict)
+		}
+	case data.BookingStatusConfirmed, data.BookingStatusCheckedIn:
+		return nil, fmt.Errorf("%w: booking is paid; extras can only be added before paying, or arranged with the hotel", apperror.ErrConflict)
+	default:
+		return nil, fmt.Errorf("%w: booking is %s and cannot have extras added", apperror.ErrConflict, booking.Status)
+	}
+
+	roomType, err := uc.roomTypeRepo.GetByID(booking.RoomTypeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if roomType == nil {
+		return nil, fmt.Errorf("%w: room type not found", apperror.ErrNotFound)
+	}
+
+	lines, err := uc.Price(roomType, booking.FromDate, booking.ToDate, requests)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := uc.hold(booking.ID, lines); err != nil {
+		return nil, err
+	}
+
+	updated, err := uc.bookingRepo.GetBooking(booking.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.FillBooking(updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// Price turns requests for the room type's hotel's extras into booking
+// lines for a stay from fromDate to toDate.
+func (uc *ExtraUsecase) Price(roomType *data.RoomType, fromDate, toDate time.Time, requests []data.ExtraRequest) ([]data.BookingExtra, error) {
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("%w: no extras requested", apperror.ErrInvalidRequest)
+	}
+
+	nights := nightsBetween(fromDate, toDate)
+	seen := make(map[int]bool)
+	lines := make([]data.BookingExtra, 0, len(requests))
+	for _, req := range requests {
+		if seen[req.ExtraID] {
+			return nil, fmt.Errorf("%w: extra %d is requested twice", apperror.ErrInvalidRequest, req.ExtraID)
+		}
+		seen[req.ExtraID] = true
+
+		extra, err := uc.extraRepo.GetByID(req.ExtraID)
+		if err != nil {
+			return nil, err
+		}
+		if extra == nil || extra.HotelID != roomType.HotelID || !extra.Active {
+			return nil, fmt.Errorf("%w: extra %d is not sold by this hotel", apperror.ErrInvalidRequest, req.ExtraID)
+		}
+
+		quantity := req.Quantity
+		if quantity == 0 {
+			quantity = 1
+		}
+		if quantity < 0 || quantity > maxExtraQuantity {
+			return nil, fmt.Errorf("%w: quantity of %s must be between 1 and %d", apperror.ErrInvalidRequest, extra.Name, maxExtraQuantity)
+		}
+
+		units := int64(quantity)
+		switch extra.Pricing {
+		case data.ExtraPerNight:
+			units *= int64(nights)
+		case data.ExtraPerGuest:
+			if quantity > roomType.Capacity {
+				return nil, fmt.Errorf("%w: %s is for at most %d guest(s), the capacity of %s", apperror.ErrInvalidRequest, extra.Name, roomType.Capacity, roomType.Name)
+			}
+			units *= int64(nights)
+		}
+
+		lines = append(lines, data.BookingExtra{
+			ExtraID:   extra.ID,
+			Name:      extra.Name,
+			Pricing:   extra.Pricing,
+			Quantity:  quantity,
+			FromDate:  fromDate,
+			ToDate:    toDate,
+			UnitPrice: extra.Price,
+			Total:     extra.Price.Times(units),
+		})
+	}
+	return lines, nil
+}
+
+// Hold reserves priced lines for a booking still being made. They must be
+// attached to the booking once it is made, or released if it is not.
+func (uc *ExtraUsecase) Hold(lines []data.BookingExtra) ([]data.BookingExtra, error) {
+	return uc.hold(0, lines)
+}
+
+func (uc *ExtraUsecase) hold(bookingID int, lines []data.BookingExtra) ([]data.BookingExtra, error) {
+	held, err := uc.extraRepo.Hold(bookingID, lines)
+	if err != nil {
+		return nil, err
+	}
+
+	if held == nil {
+		names := make([]string, len(lines))
+		for i, line := range lines {
+			names[i] = line.Name
+		}
+		return nil, fmt.Errorf("%w: not enough left of %s for these dates", apperror.ErrConflict, strings.Join(names, ", "))
+	}
+	return held, nil
+}
+
+func (uc *ExtraUsecase) Attach(lines []data.BookingExtra, bookingID int) {
+	if err := uc.extraRepo.Attach(lineIDs(lines), bookingID); err != nil {
+		log.Printf("Failed to link extras to booking %d: %v", bookingID, err)
+	}
+}
+
+func (uc *ExtraUsecase) Release(lines []data.BookingExtra) {
+	if err := uc.extraRepo.Release(lineIDs(lines)); err != nil {
+		log.Printf("Failed to release held extras: %v", err)
+	}
+}
+
+// Fill loads the extras of each booking.
+func (uc *ExtraUsecase) Fill(bookings []data.Booking) error {
+	ids := make([]int, 0, len(bookings))
+	for _, booking := range bookings {
+		if booking.ExtrasTotal != nil {
+			ids = append(ids, booking.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	lines, err := uc.extraRepo.GetForBookings(ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range bookings {
+		bookings[i].Extras = lines[bookings[i].ID]
+	}
+	return nil
+}
+
+func (uc *ExtraUsecase) FillBooking(booking *data.Booking) error {
+	if booking == nil || booking.ExtrasTotal == nil {
+		return nil
+	}
+
+	lines, err := uc.extraRepo.GetForBookings([]int{booking.ID})
+	if err != nil {
+		return err
+	}
+
+	booking.Extras = lines[booking.ID]
+	return nil
+}
+
+func (uc *ExtraUsecase) validateExtra(extra *data.Extra) error {
+	extra.Name = strings.TrimSpace(extra.Name)
+	if extra.Name == "" {
+		return fmt.Errorf("%w: extra name is required", apperror.ErrInvalidRequest)
+	}
+	switch extra.Pricing {
+	case data.ExtraPerStay, data.ExtraPerNight, data.ExtraPerGuest:
+	default:
+		return fmt.Errorf("%w: pricing must be %q, %q or %q", apperror.ErrInvalidRequest, data.ExtraPerStay, data.ExtraPerNight, data.ExtraPerGuest)
+	}
+	if extra.Price.Currency == "" || extra.Price.IsNegative() {
+		return fmt.Errorf("%w: extra price must be an amount of zero or more", apperror.ErrInvalidRequest)
+	}
+	if extra.DailyInventory != nil && *extra.DailyInventory < 0 {
+		return fmt.Errorf("%w: daily_inventory cannot be negative", apperror.ErrInvalidRequest)
+	}
+
+	hotel, err := uc.hotelRepo.GetByID(extra.HotelID)
+	if err != nil {
+		return err
+	}
+	if hotel == nil {
+		return errors.New("hotel not found")
+	}
+
+	if extra.Price.Currency != hotel.Currency {
+		return fmt.Errorf("%w: extra price must be in the hotel currency %s", apperror.ErrInvalidRequest, hotel.Currency)
+	}
+	return nil
+}
+
+// sumExtras totals booking lines, all in one currency.
+func sumExtras(lines []data.BookingExtra) (money.Money, error) {
+	total := money.Zero(lines[0].Total.Currency)
+	for _, line := range lines {
+		var err error
+		total, err = total.Add(line.Total)
+		if err != nil {
+			return money.Money{}, err
+		}
+	}
+	return total, nil
+}
+
+func lineIDs(lines []data.BookingExtra) []int {
+	ids := make([]int, len(lines))
+	for i, line := range lines {
+		ids[i] = line.ID
+	}
+	return ids
+}
@@ -0,0 +1,342 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"hotel-booking-service/internal/data"
+	"hotel-booking-service/internal/events"
+	"hotel-booking-service/internal/pkg/apperror"
+	"hotel-booking-service/internal/pkg/money"
+	"hotel-booking-service/internal/repositories"
+)
+
+// Rooms are handed over from standardCheckInHour on the arrival day and
+// must be left by standardCheckOutHour on the departure day, in server time.
+// Outside those hours the desk has to confirm an early check-in or a late
+// check-out.
+const (
+	standardCheckInHour  = 15
+	standardCheckOutHour = 11
+)
+
+// defaultNoShowCutoffHour marks guests who have not arrived by midnight at
+// the end of the arrival day as no-shows.
+const defaultNoShowCutoffHour = 24
+
+// FrontDeskUsecase runs the front desk. Staff work only on their own
+// hotel's bookings and rooms.
+type FrontDeskUsecase struct {
+	bookingRepo    *repositories.BookingRepository
+	roomRepo       *repositories.RoomRepository
+	userRepo       *repositories.UserRepository
+	bookingUsecase *BookingUsecase
+	paymentUsecase *PaymentUsecase
+	invoiceUsecase *InvoiceUsecase
+	extras         *ExtraUsecase
+	bus            *events.Bus
+}
+
+func NewFrontDeskUsecase(
+	bookingRepo *repositories.BookingRepository,
+	roomRepo *repositories.RoomRepository,
+	userRepo *repositories.UserRepository,
+	bookingUsecase *BookingUsecase,
+	paymentUsecase *PaymentUsecase,
+	invoiceUsecase *InvoiceUsecase,
+	extras *ExtraUsecase,
+	bus *events.Bus,
+) *FrontDeskUsecase {
+	return &FrontDeskUsecase{
+		bookingRepo:    bookingRepo,
+		roomRepo:       roomRepo,
+		userRepo:       userRepo,
+		bookingUsecase: bookingUsecase,
+		paymentUsecase: paymentUsecase,
+		invoiceUsecase: invoiceUsecase,
+		extras:         extras,
+		bus:            bus,
+	}
+}
+
+func (uc *FrontDeskUsecase) Arrivals(staffID, hotelID int, date time.Time) (*data.FrontDeskList, error) {
+	return uc.list(staffID, hotelID, date, uc.bookingRepo.GetArrivals)
+}
+
+func (uc *FrontDeskUsecase) InHouse(staffID, hotelID int, date time.Time) (*data.FrontDeskList, error) {
+	return uc.list(staffID, hotelID, date, uc.bookingRepo.GetInHouse)
+}
+
+func (uc *FrontDeskUsecase) Departures(staffID, hotelID int, date time.Time) (*data.FrontDeskList, error) {
+	return uc.list(staffID, hotelID, date, uc.bookingRepo.GetDepartures)
+}
+
+// list shows each booking with its extras, so the desk can hand out what
+// the guest ordered.
+func (uc *FrontDeskUsecase) list(staffID, hotelID int, date time.Time, get func(int, time.Time) ([]data.Booking, error)) (*data.FrontDeskList, error) {
+	if err := checkWorksAt(uc.userRepo, staffID, hotelID); err != nil {
+		return nil, err
+	}
+
+	bookings, err := get(hotelID, dateOnly(date))
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.extras.Fill(bookings); err != nil {
+		return nil, err
+	}
+	return &data.FrontDeskList{HotelID: hotelID, Date: dateOnly(date), Bookings: bookings}, nil
+}
+
+// CheckIn records that the guest arrived and showed the given identity
+// document. The booking keeps its assigned room unless another is asked for;
+// a booking without one gets the first free room of its type that is ready.
+// The room must be clean and no longer occupied by the previous guest; a
+// check-in refused for its room leaves the booking where it was.
+func (uc *FrontDeskUsecase) CheckIn(staffID, bookingID int, req data.CheckInRequest) (*data.Booking, error) {
+	idDocument := strings.TrimSpace(req.IDDocument)
+	if idDocument == "" {
+		return nil, fmt.Errorf("%w: id_document is required to verify the guest", apperror.ErrInvalidRequest)
+	}
+
+	booking, err := uc.bookingRepo.GetBooking(bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if booking == nil {
+		return nil, errors.New("booking not found")
+	}
+
+	if err := checkWorksAt(uc.userRepo, staffID, booking.HotelID); err != nil {
+		return nil, err
+	}
+
+	if booking.Status != data.BookingStatusConfirmed {
+		return nil, fmt.Errorf("%w: booking is %s and cannot be checked in", apperror.ErrConflict, booking.Status)
+	}
+
+	now := time.Now()
+	today := dateOnly(now)
+	if today.Before(dateOnly(booking.FromDate)) {
+		return nil, fmt.Errorf("%w: guest arrives on %s", apperror.ErrConflict, booking.FromDate.Format("2006-01-02"))
+	}
+	if !today.Before(dateOnly(booking.ToDate)) {
+		return nil, fmt.Errorf("%w: stay ended on %s", apperror.ErrConflict, booking.ToDate.Format("2006-01-02"))
+	}
+	if today.Equal(dateOnly(booking.FromDate)) && now.Hour() < standardCheckInHour && !req.Early {
+		return nil, fmt.Errorf("%w: check-in before %d:00 is an early check-in; confirm it with early set", apperror.ErrConflict, standardCheckInHour)
+	}
+
+	// The room is picked and checked before anything is changed, so that a
+	// refused check-in leaves the booking in the room it had.
+	room, err := uc.checkInRoom(booking, req.RoomID)
+	if err != nil {
+		return nil, err
+	}
+
+	checkedIn, err := uc.bookingRepo.CheckIn(booking.ID, room.ID, room.RoomTypeID, idDocument, staffID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !checkedIn {
+		return nil, fmt.Errorf("%w: booking changed while checking in", apperror.ErrConflict)
+	}
+
+	return uc.bookingRepo.GetBooking(booking.ID)
+}
+
+// checkInRoom picks the room the guest checks in to: the one asked for, else
+// the booking's, else the first room of its type that is free for the stay
+// and ready. It changes nothing.
+func (uc *FrontDeskUsecase) checkInRoom(booking *data.Booking, roomID int) (*data.Room, error) {
+	if roomID == 0 && booking.RoomID == 0 {
+		rooms, err := uc.bookingUsecase.freeRooms(booking)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range rooms {
+			err := uc.checkRoomReady(&rooms[i], booking.ID)
+			if err == nil {
+				return &rooms[i], nil
+			}
+			if !errors.Is(err, apperror.ErrConflict) {
+				return nil, err
+			}
+		}
+		return nil, fmt.Errorf("%w: no room of this type is free for the whole stay, clean and empty", apperror.ErrConflict)
+	}
+
+	var room *data.Room
+	var err error
+	if roomID != 0 && roomID != booking.RoomID {
+		room, err = uc.bookingUsecase.roomForStay(booking, roomID)
+	} else {
+		room, err = uc.roomRepo.GetByID(booking.RoomID)
+		if err == nil && room == nil {
+			err = errors.New("room not found")
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.checkRoomReady(room, booking.ID); err != nil {
+		return nil, err
+	}
+	return room, nil
+}
+
+// checkRoomReady refuses a room that has not been cleaned or whose previous
+// guest has not checked out.
+func (uc *FrontDeskUsecase) checkRoomReady(room *data.Room, bookingID int) error {
+	if room.HousekeepingStatus != data.HousekeepingClean {
+		return fmt.Errorf("%w: room %s has not been cleaned yet", apperror.ErrConflict, room.Number)
+	}
+
+	occupied, err := uc.bookingRepo.RoomOccupied(room.ID, bookingID)
+	if err != nil {
+		return err
+	}
+
+	if occupied {
+		return fmt.Errorf("%w: the previous guest has not checked out of room %s", apperror.ErrConflict, room.Number)
+	}
+	return nil
+}
+
+// CheckOut settles the bill, records that the guest left and sends the
+// room to housekeeping. A guest leaving early pays only for the nights
+// stayed; the rest is refunded and released for sale.
+func (uc *FrontDeskUsecase) CheckOut(ctx context.Context, staffID, bookingID int, req data.CheckOutRequest) (*data.CheckOutResult, error) {
+	booking, err := uc.bookingRepo.GetBooking(bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if booking == nil {
+		return nil, errors.New("booking not found")
+	}
+
+	if err := checkWorksAt(uc.userRepo, staffID, booking.HotelID); err != nil {
+		return nil, err
+	}
+
+	if booking.Status != data.BookingStatusCheckedIn {
+		return nil, fmt.Errorf("%w: booking is %s, not checked in", apperror.ErrConflict, booking.Status)
+	}
+
+	now := time.Now()
+	today := dateOnly(now)
+	departure := dateOnly(booking.ToDate)
+	toDate := booking.ToDate
+	switch {
+	case today.Before(departure):
+		if !req.Early {
+			return nil, fmt.Errorf("%w: guest is due to leave on %s; confirm an early check-out with early set", apperror.ErrConflict, departure.Format("2006-01-02"))
+		}
+		// Nights up to today are charged; leaving on the arrival day
+		// still costs the first night.
+		toDate = today
+		if !toDate.After(dateOnly(booking.FromDate)) {
+			toDate = dateOnly(booking.FromDate).AddDate(0, 0, 1)
+		}
+	case today.After(departure) || now.Hour() >= standardCheckOutHour:
+		if !req.Late {
+			return nil, fmt.Errorf("%w: check-out after %d:00 on the departure day is a late check-out; confirm it with late set", apperror.ErrConflict, standardCheckOutHour)
+		}
+	}
+
+	// Extras are charged as booked, even when the guest leaves early.
+	due := booking.NightlyRate.Times(int64(nightsBetween(booking.FromDate, toDate)))
+	if booking.ExtrasTotal != nil {
+		due, err = due.Add(*booking.ExtrasTotal)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if booking.LoyaltyDiscount != nil {
+		due, err = due.Sub(*booking.LoyaltyDiscount)
+		if err != nil {
+			return nil, err
+		}
+		if due.IsNegative() {
+			due = money.Zero(due.Currency)
+		}
+	}
+	if due.Amount > booking.TotalPrice.Amount {
+		due = booking.TotalPrice
+	}
+
+	// The booking is claimed before any money moves, so that a check-out
+	// that loses a race never charges the guest.
+	checkedOut, err := uc.bookingRepo.CheckOut(booking.ID, toDate, due, staffID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !checkedOut {
+		return nil, fmt.Errorf("%w: booking changed while checking out", apperror.ErrConflict)
+	}
+
+	if err := uc.paymentUsecase.SettleStay(ctx, booking.ID, booking.TotalPrice, due); err != nil {
+		log.Printf("Failed to settle payment for booking %d: %v", booking.ID, err)
+	}
+
+	excess, err := booking.TotalPrice.Sub(due)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.invoiceUsecase.CreditEarlyDeparture(booking.ID, excess); err != nil {
+		log.Printf("Failed to issue credit note for booking %d: %v", booking.ID, err)
+	}
+
+	updated, err := uc.bookingRepo.GetBooking(booking.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.bus.Publish(ctx, events.BookingCheckedOut, *updated)
+
+	result := &data.CheckOutResult{Booking: updated}
+	invoice, err := uc.invoiceUsecase.IssueInvoice(updated)
+	if err != nil {
+		log.Printf("Failed to issue invoice for booking %d: %v", booking.ID, err)
+	} else {
+		result.Invoice = invoice
+	}
+
+	return result, nil
+}
+
+// SetHousekeepingStatus records that a room was cleaned, or needs to be.
+func (uc *FrontDeskUsecase) SetHousekeepingStatus(staffID, roomID int, status string) (*data.Room, error) {
+	if status != data.HousekeepingClean && status != data.HousekeepingDirty {
+		return nil, fmt.Errorf("%w: status must be %q or %q", apperror.ErrInvalidRequest, data.HousekeepingClean, data.HousekeepingDirty)
+	}
+
+	room, err := uc.roomRepo.GetByID(roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	if room == nil {
+		return nil, errors.New("room not found")
+	}
+
+	if err := checkWorksAt(uc.userRepo, staffID, room.HotelID); err != nil {
+		return nil, err
+	}
+
+	if err := uc.roomRepo.SetHousekeepingStatus(roomID, status); err != nil {
+		return nil, err
+	}
+
+	return uc.roomRepo.GetByID(roomID)
+}
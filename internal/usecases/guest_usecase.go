@@ -0,0 +1,132 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/mail"
+	"strings"
+
+	"hotel-booking-service/internal/data"
+	"hotel-booking-service/internal/notifications"
+	"hotel-booking-service/internal/pkg/apperror"
+	"hotel-booking-service/internal/pkg/magiclink"
+	"hotel-booking-service/internal/repositories"
+)
+
+// GuestUsecase books for guests without an account. Each guest gets a
+// passwordless user, found again by email on later bookings, and every
+// booking an emailed link that stands in for logging in.
+type GuestUsecase struct {
+	userRepo       *repositories.UserRepository
+	bookingUsecase *BookingUsecase
+	notifier       notifications.Notifier
+	secret         string
+	publicURL      string
+}
+
+func NewGuestUsecase(
+	userRepo *repositories.UserRepository,
+	bookingUsecase *BookingUsecase,
+	notifier notifications.Notifier,
+	secret string,
+	publicURL string,
+) *GuestUsecase {
+	return &GuestUsecase{
+		userRepo:       userRepo,
+		bookingUsecase: bookingUsecase,
+		notifier:       notifier,
+		secret:         secret,
+		publicURL:      strings.TrimRight(publicURL, "/"),
+	}
+}
+
+// CreateBooking books as CreateBooking does for a logged-in user and emails
+// the guest a link to the booking, which is not returned: anyone can book
+// with a guest's email address, but only the guest reads their mail. The link stays valid until the day after
+// departure.
+func (uc *GuestUsecase) CreateBooking(ctx context.Context, req data.GuestBookingRequest) (*data.GuestBooking, error) {
+	guestDetails, err := validateGuestDetails(req.Guest)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.RedeemPoints > 0 {
+		return nil, fmt.Errorf("%w: log in to redeem loyalty points", apperror.ErrInvalidRequest)
+	}
+
+	guest, err := uc.userRepo.UpsertGuest(guestDetails.Email, guestDetails.Name, guestDetails.Phone)
+	if err != nil {
+		return nil, err
+	}
+
+	if guest == nil {
+		return nil, fmt.Errorf("%w: an account exists for this email; log in to book", apperror.ErrConflict)
+	}
+
+	booking, err := uc.bookingUsecase.CreateBooking(guest.ID, req.RoomTypeID, req.RoomID, req.FromDate, req.ToDate, req.Currency, 0, req.Extras)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := magiclink.Sign(uc.secret, booking.ID, guest.ID, dateOnly(booking.ToDate).AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	link := fmt.Sprintf("%s/guest/bookings/%d?token=%s", uc.publicURL, booking.ID, token)
+	uc.sendLink(ctx, guest, booking, link)
+
+	return &data.GuestBooking{Booking: booking}, nil
+}
+
+// UpdateContact changes the name and phone number the hotel has for a
+// guest. The email identifies the guest and cannot be changed here.
+func (uc *GuestUsecase) UpdateContact(guestID int, details data.GuestDetails) (*data.User, error) {
+	name := strings.TrimSpace(details.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", apperror.ErrInvalidRequest)
+	}
+
+	if err := uc.userRepo.UpdateContact(guestID, name, strings.TrimSpace(details.Phone)); err != nil {
+		return nil, err
+	}
+
+	return uc.userRepo.GetByID(guestID)
+}
+
+func (uc *GuestUsecase) sendLink(ctx context.Context, guest *data.User, booking *data.Booking, link string) {
+	msg := notifications.Message{
+		To:      guest.Email,
+		Subject: fmt.Sprintf("Your booking %s", booking.ConfirmationCode),
+		Body: fmt.Sprintf(
+			"Thank you for booking with us. Your confirmation code is %s for %s to %s.\n\n"+
+				"View, change or cancel your booking at %s\n\n"+
+				"Register with this email address to keep your bookings in an account.",
+			booking.ConfirmationCode,
+			booking.FromDate.Format("2006-01-02"),
+			booking.ToDate.Format("2006-01-02"),
+			link,
+		),
+	}
+
+	if err := uc.notifier.Notify(ctx, msg); err != nil {
+		log.Printf("Failed to send booking link for booking %d: %v", booking.ID, err)
+	}
+}
+
+func validateGuestDetails(details data.GuestDetails) (data.GuestDetails, error) {
+	details.Name = strings.TrimSpace(details.Name)
+	details.Email = strings.TrimSpace(details.Email)
+	details.Phone = strings.TrimSpace(details.Phone)
+
+	if details.Name == "" {
+		return details, fmt.Errorf("%w: guest name is required", apperror.ErrInvalidRequest)
+	}
+
+	if address, err := mail.ParseAddress(details.Email); err != nil || address.Address != details.Email {
+		return details, fmt.Errorf("%w: guest email is not a valid address", apperror.ErrInvalidRequest)
+	}
+
+	return details, nil
+}
@@ -1,93 +1,1021 @@
 package usecases
 
 import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"time"
-	
+	"unicode"
+	"unicode/utf8"
+
 	"hotel-booking-service/internal/data"
+	"hotel-booking-service/internal/pkg/apperror"
+	"hotel-booking-service/internal/pkg/geo"
+	"hotel-booking-service/internal/pkg/money"
 	"hotel-booking-service/internal/repositories"
 )
 
+// Limits on hotel searches, which check availability night by night.
+const (
+	maxSearchNights    = 30
+	maxSearchGuests    = 20
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+	maxSearchTerms     = 10
+
+	defaultSearchRadiusKm = 10
+	maxSearchRadiusKm     = 500
+
+	maxHotelDescriptionLen = 5000
+)
+
 type HotelUsecase struct {
-	hotelRepo *repositories.HotelRepository
-	roomRepo  *repositories.RoomRepository
+	hotelRepo       *repositories.HotelRepository
+	roomRepo        *repositories.RoomRepository
+	roomTypeRepo    *repositories.RoomTypeRepository
+	amenityRepo     *repositories.AmenityRepository
+	currencyUsecase *CurrencyUsecase
+	restrictions    *RestrictionUsecase
 }
 
 func NewHotelUsecase(
 	hotelRepo *repositories.HotelRepository,
 	roomRepo *repositories.RoomRepository,
+	roomTypeRepo *repositories.RoomTypeRepository,
+	amenityRepo *repositories.AmenityRepository,
+	currencyUsecase *CurrencyUsecase,
+	restrictions *RestrictionUsecase,
 ) *HotelUsecase {
 	return &HotelUsecase{
-		hotelRepo: hotelRepo,
-		roomRepo:  roomRepo,
+		hotelRepo:       hotelRepo,
+		roomRepo:        roomRepo,
+		roomTypeRepo:    roomTypeRepo,
+		amenityRepo:     amenityRepo,
+		currencyUsecase: currencyUsecase,
+		restrictions:    restrictions,
 	}
 }
 
-func (uc *HotelUsecase) GetAllHotels(fromDate, toDate time.Time) ([]data.Hotel, error) {
-	hotels, err := uc.hotelRepo.GetAllHotels()
+func (uc *HotelUsecase) GetAllHotels(fromDate, toDate time.Time, currency string, filter data.HotelFilter) ([]data.Hotel, error) {
+	currency, err := normalizeCurrency(currency)
 	if err != nil {
 		return nil, err
 	}
-	
-	for i := range hotels {
-		availableRooms, err := uc.roomRepo.GetAvailableRoomsByHotelID(hotels[i].ID, fromDate, toDate)
+
+	if filter.Sort != "" && filter.Sort != "rating" {
+		return nil, fmt.Errorf("%w: sort must be \"rating\" or left out", apperror.ErrInvalidRequest)
+	}
+	if filter.MinRating < 0 || filter.MinRating > 5 {
+		return nil, fmt.Errorf("%w: min_rating must be between 0 and 5", apperror.ErrInvalidRequest)
+	}
+
+	hotels, err := uc.hotelRepo.GetAllHotels(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.fillAvailability(hotels, fromDate, toDate, currency); err != nil {
+		return nil, err
+	}
+
+	return hotels, nil
+}
+
+// SearchHotels returns a page of the hotels that can take the party for the
+// whole stay. Prices are filtered and sorted in the search currency, US
+// dollars when none is given.
+func (uc *HotelUsecase) SearchHotels(search data.HotelSearch) (*data.HotelSearchResult, error) {
+	currency, err := normalizeCurrency(search.Currency)
+	if err != nil {
+		return nil, err
+	}
+	search.Currency = currency
+
+	if err := validateSearch(&search); err != nil {
+		return nil, err
+	}
+
+	search.Amenities = normalizeAmenityCodes(search.Amenities)
+	if len(search.Amenities) > maxAmenityFilters {
+		return nil, fmt.Errorf("%w: at most %d amenities can be required", apperror.ErrInvalidRequest, maxAmenityFilters)
+	}
+	if len(search.Amenities) > 0 {
+		if _, err := lookUpAmenities(uc.amenityRepo, search.Amenities); err != nil {
+			return nil, err
+		}
+	}
+
+	var factors map[string]string
+	if search.Sort == data.SearchSortPrice || search.MinPrice != nil || search.MaxPrice != nil {
+		priceCurrency := currency
+		if priceCurrency == "" {
+			priceCurrency = money.DefaultCurrency
+		}
+
+		currencies, err := uc.hotelRepo.Currencies()
+		if err != nil {
+			return nil, err
+		}
+
+		factors, err = uc.currencyUsecase.ConversionFactors(currencies, priceCurrency)
 		if err != nil {
 			return nil, err
 		}
-		
-		hotels[i].Rooms = availableRooms
 	}
-	
-	return hotels, nil
+
+	hotels, total, err := uc.hotelRepo.Search(search, factors)
+	if err != nil {
+		return nil, err
+	}
+
+	if currency != "" {
+		if err := uc.convertSearchPrices(hotels, currency); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := uc.fillAmenities(hotels); err != nil {
+		return nil, err
+	}
+
+	return &data.HotelSearchResult{
+		Hotels: hotels,
+		Total:  total,
+		Limit:  search.Limit,
+		Offset: search.Offset,
+	}, nil
+}
+
+// convertSearchPrices converts the room types of all the hotels at once, so
+// that each rate is looked up only once. A hotel's lowest price is that of
+// its first, cheapest, room type.
+func (uc *HotelUsecase) convertSearchPrices(hotels []data.Hotel, currency string) error {
+	var roomTypes []data.RoomType
+	for _, hotel := range hotels {
+		roomTypes = append(roomTypes, hotel.RoomTypes...)
+	}
+
+	if err := uc.currencyUsecase.ConvertRoomTypes(roomTypes, currency); err != nil {
+		return err
+	}
+
+	for i := range hotels {
+		n := len(hotels[i].RoomTypes)
+		hotels[i].RoomTypes, roomTypes = roomTypes[:n], roomTypes[n:]
+		if n > 0 && hotels[i].LowestPrice != nil && hotels[i].RoomTypes[0].Price == *hotels[i].LowestPrice {
+			hotels[i].ConvertedLowestPrice = hotels[i].RoomTypes[0].ConvertedPrice
+		}
+	}
+	return nil
+}
+
+// validateSearch checks the search and fills in its defaults: one guest,
+// the first page of 20, a 10 km radius, relevance order for text searches
+// and distance order for searches around a point, and the natural order of
+// the sort.
+func validateSearch(search *data.HotelSearch) error {
+	today := dateOnly(time.Now())
+	search.FromDate = dateOnly(search.FromDate)
+	search.ToDate = dateOnly(search.ToDate)
+	if search.FromDate.Before(today) {
+		return fmt.Errorf("%w: from_date cannot be in the past", apperror.ErrInvalidRequest)
+	}
+
+	nights := nightsBetween(search.FromDate, search.ToDate)
+	if nights < 1 {
+		return fmt.Errorf("%w: to_date must be after from_date", apperror.ErrInvalidRequest)
+	}
+	if nights > maxSearchNights {
+		return fmt.Errorf("%w: stays can be searched for at most %d nights", apperror.ErrInvalidRequest, maxSearchNights)
+	}
+
+	if search.Guests == 0 {
+		search.Guests = 1
+	}
+	if search.Guests < 1 || search.Guests > maxSearchGuests {
+		return fmt.Errorf("%w: guests must be between 1 and %d", apperror.ErrInvalidRequest, maxSearchGuests)
+	}
+
+	for _, price := range []*money.Money{search.MinPrice, search.MaxPrice} {
+		if price != nil && price.IsNegative() {
+			return fmt.Errorf("%w: prices cannot be negative", apperror.ErrInvalidRequest)
+		}
+	}
+	if search.MinPrice != nil && search.MaxPrice != nil && search.MinPrice.Amount > search.MaxPrice.Amount {
+		return fmt.Errorf("%w: min_price cannot be more than max_price", apperror.ErrInvalidRequest)
+	}
+
+	if search.MinRating < 0 || search.MinRating > 5 {
+		return fmt.Errorf("%w: min_rating must be between 0 and 5", apperror.ErrInvalidRequest)
+	}
+
+	if search.Text != "" {
+		terms := searchTerms(search.Text)
+		if len(terms) == 0 {
+			return fmt.Errorf("%w: q must contain a letter or digit", apperror.ErrInvalidRequest)
+		}
+		if len(terms) > maxSearchTerms {
+			return fmt.Errorf("%w: q can have at most %d words", apperror.ErrInvalidRequest, maxSearchTerms)
+		}
+		search.Text = strings.Join(terms, " ")
+		if search.Sort == "" {
+			search.Sort = data.SearchSortRelevance
+		}
+	}
+
+	if search.Near != nil {
+		if err := search.Near.Validate(); err != nil {
+			return fmt.Errorf("%w: %v", apperror.ErrInvalidRequest, err)
+		}
+		if search.RadiusKm == 0 {
+			search.RadiusKm = defaultSearchRadiusKm
+		}
+		if !(search.RadiusKm > 0 && search.RadiusKm <= maxSearchRadiusKm) {
+			return fmt.Errorf("%w: radius_km must be more than 0 and at most %d", apperror.ErrInvalidRequest, maxSearchRadiusKm)
+		}
+		if search.Sort == "" {
+			search.Sort = data.SearchSortDistance
+		}
+	} else if search.RadiusKm != 0 {
+		return fmt.Errorf("%w: radius_km needs lat and lng", apperror.ErrInvalidRequest)
+	}
+
+	switch search.Sort {
+	case "", data.SearchSortPrice, data.SearchSortName:
+		if search.Order == "" {
+			search.Order = "asc"
+		}
+	case data.SearchSortRating:
+		if search.Order == "" {
+			search.Order = "desc"
+		}
+	case data.SearchSortRelevance:
+		if search.Text == "" {
+			return fmt.Errorf("%w: sorting by relevance needs q", apperror.ErrInvalidRequest)
+		}
+		if search.Order == "" {
+			search.Order = "desc"
+		}
+	case data.SearchSortDistance:
+		if search.Near == nil {
+			return fmt.Errorf("%w: sorting by distance needs lat and lng", apperror.ErrInvalidRequest)
+		}
+		if search.Order == "" {
+			search.Order = "asc"
+		}
+	default:
+		return fmt.Errorf("%w: sort must be %q, %q, %q, %q or %q", apperror.ErrInvalidRequest, data.SearchSortPrice, data.SearchSortRating, data.SearchSortName, data.SearchSortRelevance, data.SearchSortDistance)
+	}
+	if search.Order != "asc" && search.Order != "desc" {
+		return fmt.Errorf("%w: order must be \"asc\" or \"desc\"", apperror.ErrInvalidRequest)
+	}
+
+	if search.Limit == 0 {
+		search.Limit = defaultSearchLimit
+	}
+	if search.Limit < 1 || search.Limit > maxSearchLimit {
+		return fmt.Errorf("%w: limit must be between 1 and %d", apperror.ErrInvalidRequest, maxSearchLimit)
+	}
+	if search.Offset < 0 {
+		return fmt.Errorf("%w: offset cannot be negative", apperror.ErrInvalidRequest)
+	}
+
+	return nil
+}
+
+// searchTerms splits search text into lowercase words of letters and
+// digits, dropping punctuation, so that the words are safe to use as
+// full-text query terms.
+func searchTerms(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
 }
 
-func (uc *HotelUsecase) GetHotelByID(id int, fromDate, toDate time.Time) (*data.Hotel, error) {
+func (uc *HotelUsecase) GetHotelByID(id int, fromDate, toDate time.Time, currency string) (*data.Hotel, error) {
+	currency, err := normalizeCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+
 	hotel, err := uc.hotelRepo.GetByID(id)
 	if err != nil {
 		return nil, err
 	}
-	
+
 	if hotel == nil {
 		return nil, nil
 	}
-	
-	availableRooms, err := uc.roomRepo.GetAvailableRoomsByHotelID(id, fromDate, toDate)
-	if err != nil {
+
+	hotels := []data.Hotel{*hotel}
+	if err := uc.fillAvailability(hotels, fromDate, toDate, currency); err != nil {
 		return nil, err
 	}
-	
-	hotel.Rooms = availableRooms
-	
-	return hotel, nil
+
+	return &hotels[0], nil
 }
 
-func (uc *HotelUsecase) GetRoomsByHotelID(hotelID int, fromDate, toDate time.Time) ([]data.Room, error) {
-	rooms, err := uc.roomRepo.GetAvailableRoomsByHotelID(hotelID, fromDate, toDate)
+func (uc *HotelUsecase) GetRoomsByHotelID(hotelID int, fromDate, toDate time.Time, currency string) ([]data.Room, error) {
+	currency, err := normalizeCurrency(currency)
 	if err != nil {
 		return nil, err
 	}
+
+	hotelIDs := []int{hotelID}
+	roomTypes, err := uc.availableRoomTypes(hotelIDs, fromDate, toDate, "")
+	if err != nil {
+		return nil, err
+	}
+
+	rooms, err := uc.availableRooms(hotelIDs, roomTypes, fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.restrictions.AnnotateRooms(rooms, fromDate, toDate); err != nil {
+		return nil, err
+	}
+
+	if currency != "" {
+		if err := uc.currencyUsecase.ConvertRooms(rooms, currency); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := uc.fillRoomAmenities(nil, rooms); err != nil {
+		return nil, err
+	}
 	return rooms, nil
 }
 
+// GetRoomTypes lists what the hotel sells, with the units left for the stay.
+func (uc *HotelUsecase) GetRoomTypes(hotelID int, fromDate, toDate time.Time, currency string) ([]data.RoomType, error) {
+	currency, err := normalizeCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+
+	hotel, err := uc.hotelRepo.GetByID(hotelID)
+	if err != nil {
+		return nil, err
+	}
+	if hotel == nil {
+		return nil, errors.New("hotel not found")
+	}
+
+	roomTypes, err := uc.availableRoomTypes([]int{hotelID}, fromDate, toDate, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.fillRoomAmenities(roomTypes, nil); err != nil {
+		return nil, err
+	}
+	return roomTypes, nil
+}
+
+// fillAvailability sets the room types and available rooms of each hotel.
+// The reads are made for all the hotels together, so listing any number of
+// hotels takes the same few queries.
+func (uc *HotelUsecase) fillAvailability(hotels []data.Hotel, fromDate, toDate time.Time, currency string) error {
+	if len(hotels) == 0 {
+		return nil
+	}
+
+	hotelIDs := make([]int, len(hotels))
+	for i, hotel := range hotels {
+		hotelIDs[i] = hotel.ID
+	}
+
+	roomTypes, err := uc.availableRoomTypes(hotelIDs, fromDate, toDate, currency)
+	if err != nil {
+		return err
+	}
+
+	rooms, err := uc.availableRooms(hotelIDs, roomTypes, fromDate, toDate)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.restrictions.AnnotateRooms(rooms, fromDate, toDate); err != nil {
+		return err
+	}
+
+	if currency != "" {
+		if err := uc.currencyUsecase.ConvertRooms(rooms, currency); err != nil {
+			return err
+		}
+	}
+
+	if err := uc.fillRoomAmenities(roomTypes, rooms); err != nil {
+		return err
+	}
+
+	typesByHotel := map[int][]data.RoomType{}
+	for _, roomType := range roomTypes {
+		typesByHotel[roomType.HotelID] = append(typesByHotel[roomType.HotelID], roomType)
+	}
+	roomsByHotel := map[int][]data.Room{}
+	for _, room := range rooms {
+		roomsByHotel[room.HotelID] = append(roomsByHotel[room.HotelID], room)
+	}
+
+	for i := range hotels {
+		hotels[i].RoomTypes = typesByHotel[hotels[i].ID]
+		hotels[i].Rooms = roomsByHotel[hotels[i].ID]
+	}
+
+	hotelAmenities, err := uc.amenityRepo.ForHotels(hotelIDs)
+	if err != nil {
+		return err
+	}
+	for i := range hotels {
+		hotels[i].Amenities = hotelAmenities[hotels[i].ID]
+	}
+	return nil
+}
+
+// fillAmenities sets the amenities of hotels found by a search and of their
+// room types.
+func (uc *HotelUsecase) fillAmenities(hotels []data.Hotel) error {
+	if len(hotels) == 0 {
+		return nil
+	}
+
+	hotelIDs := make([]int, len(hotels))
+	var roomTypeIDs []int
+	for i, hotel := range hotels {
+		hotelIDs[i] = hotel.ID
+		for _, roomType := range hotel.RoomTypes {
+			roomTypeIDs = append(roomTypeIDs, roomType.ID)
+		}
+	}
+
+	hotelAmenities, err := uc.amenityRepo.ForHotels(hotelIDs)
+	if err != nil {
+		return err
+	}
+	typeAmenities, err := uc.amenityRepo.ForRoomTypes(roomTypeIDs)
+	if err != nil {
+		return err
+	}
+
+	for i := range hotels {
+		hotels[i].Amenities = hotelAmenities[hotels[i].ID]
+		for j := range hotels[i].RoomTypes {
+			hotels[i].RoomTypes[j].Amenities = typeAmenities[hotels[i].RoomTypes[j].ID]
+		}
+	}
+	return nil
+}
+
+// fillRoomAmenities sets the amenities of room types, and of rooms those
+// of their types together with their own.
+func (uc *HotelUsecase) fillRoomAmenities(roomTypes []data.RoomType, rooms []data.Room) error {
+	var ids, roomIDs []int
+	for _, roomType := range roomTypes {
+		ids = append(ids, roomType.ID)
+	}
+	for _, room := range rooms {
+		ids = append(ids, room.RoomTypeID)
+		roomIDs = append(roomIDs, room.ID)
+	}
+
+	amenities, err := uc.amenityRepo.ForRoomTypes(ids)
+	if err != nil {
+		return err
+	}
+	own, err := uc.amenityRepo.ForRooms(roomIDs)
+	if err != nil {
+		return err
+	}
+
+	for i := range roomTypes {
+		roomTypes[i].Amenities = amenities[roomTypes[i].ID]
+	}
+	for i := range rooms {
+		rooms[i].Amenities = mergeAmenities(amenities[rooms[i].RoomTypeID], own[rooms[i].ID])
+	}
+	return nil
+}
+
+// mergeAmenities returns the amenities in either list, by name.
+func mergeAmenities(a, b []data.Amenity) []data.Amenity {
+	if len(b) == 0 {
+		return a
+	}
+
+	merged := append([]data.Amenity{}, a...)
+	for _, amenity := range b {
+		found := false
+		for _, have := range a {
+			if have.ID == amenity.ID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, amenity)
+		}
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		if merged[i].Name != merged[j].Name {
+			return merged[i].Name < merged[j].Name
+		}
+		return merged[i].ID < merged[j].ID
+	})
+	return merged
+}
+
+// availableRoomTypes loads the hotels' types with the units left on every
+// night of the stay.
+func (uc *HotelUsecase) availableRoomTypes(hotelIDs []int, fromDate, toDate time.Time, currency string) ([]data.RoomType, error) {
+	roomTypes, err := uc.roomTypeRepo.GetByHotelIDs(hotelIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	availability := map[int]int{}
+	if nightsBetween(fromDate, toDate) >= 1 {
+		availability, err = uc.roomTypeRepo.GetAvailabilityForHotels(hotelIDs, fromDate, toDate)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range roomTypes {
+		units := availability[roomTypes[i].ID]
+		if units < 0 {
+			units = 0
+		}
+		roomTypes[i].Available = &units
+	}
+
+	if currency != "" {
+		if err := uc.currencyUsecase.ConvertRoomTypes(roomTypes, currency); err != nil {
+			return nil, err
+		}
+	}
+	return roomTypes, nil
+}
+
+// availableRooms lists the rooms that are free for the stay and whose type
+// still has units to sell. Unassigned bookings use up their type without
+// holding a particular room, so a free room alone is not enough.
+func (uc *HotelUsecase) availableRooms(hotelIDs []int, roomTypes []data.RoomType, fromDate, toDate time.Time) ([]data.Room, error) {
+	rooms, err := uc.roomRepo.GetAvailableRoomsByHotelIDs(hotelIDs, fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+
+	sellable := map[int]bool{}
+	for _, roomType := range roomTypes {
+		sellable[roomType.ID] = roomType.Available != nil && *roomType.Available > 0
+	}
+
+	var available []data.Room
+	for _, room := range rooms {
+		if sellable[room.RoomTypeID] {
+			available = append(available, room)
+		}
+	}
+	return available, nil
+}
+
+// QuoteRoom prices a stay in the hotel currency and, when asked, shows the
+// same quote converted into the guest's currency.
+func (uc *HotelUsecase) QuoteRoom(roomID int, fromDate, toDate time.Time, currency string) (*data.Quote, error) {
+	room, err := uc.roomRepo.GetByID(roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	if room == nil {
+		return nil, errors.New("room not found")
+	}
+
+	return uc.quote(room.RoomTypeID, room, fromDate, toDate, currency)
+}
+
+// QuoteRoomType prices a stay in any room of the type.
+func (uc *HotelUsecase) QuoteRoomType(roomTypeID int, fromDate, toDate time.Time, currency string) (*data.Quote, error) {
+	return uc.quote(roomTypeID, nil, fromDate, toDate, currency)
+}
+
+func (uc *HotelUsecase) quote(roomTypeID int, room *data.Room, fromDate, toDate time.Time, currency string) (*data.Quote, error) {
+	currency, err := normalizeCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+
+	if nightsBetween(fromDate, toDate) < 1 {
+		return nil, errors.New("stay must be at least one night")
+	}
+
+	roomType, err := uc.roomTypeRepo.GetByID(roomTypeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if roomType == nil {
+		return nil, fmt.Errorf("%w: room type not found", apperror.ErrNotFound)
+	}
+
+	nightly, total, nights := quoteStay(roomType, fromDate, toDate)
+	quote := &data.Quote{
+		RoomTypeID:  roomType.ID,
+		HotelID:     roomType.HotelID,
+		FromDate:    fromDate,
+		ToDate:      toDate,
+		Nights:      nights,
+		NightlyRate: nightly,
+		Total:       total,
+	}
+
+	if room != nil {
+		quote.RoomID = room.ID
+	}
+
+	quote.Restrictions, err = uc.restrictions.CheckStay(roomType, fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+
+	if currency != "" {
+		convertedTotal, rate, err := uc.currencyUsecase.Convert(total, currency)
+		if err != nil {
+			return nil, err
+		}
+		convertedNightly, err := money.Convert(nightly, rate, currency)
+		if err != nil {
+			return nil, err
+		}
+		quote.Converted = &data.ConvertedQuote{
+			Currency:     currency,
+			ExchangeRate: rate,
+			NightlyRate:  convertedNightly,
+			Total:        convertedTotal,
+		}
+	}
+
+	return quote, nil
+}
+
 func (uc *HotelUsecase) CreateHotel(hotel data.Hotel) (*data.Hotel, error) {
+	currency, err := normalizeCurrency(hotel.Currency)
+	if err != nil {
+		return nil, err
+	}
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+	hotel.Currency = currency
+
+	if err := validateTaxRate(hotel.TaxRateBP); err != nil {
+		return nil, err
+	}
+
+	hotel.Description = strings.TrimSpace(hotel.Description)
+	if err := validateHotelDescription(hotel.Description); err != nil {
+		return nil, err
+	}
+
+	if err := validateHotelLocation(hotel.Latitude, hotel.Longitude); err != nil {
+		return nil, err
+	}
+
+	if hotel.NoShowCutoffHour == 0 {
+		hotel.NoShowCutoffHour = defaultNoShowCutoffHour
+	}
+	if err := validateNoShowCutoff(hotel.NoShowCutoffHour); err != nil {
+		return nil, err
+	}
+
 	return uc.hotelRepo.CreateHotel(hotel)
 }
 
 func (uc *HotelUsecase) UpdateHotel(hotel data.Hotel) (*data.Hotel, error) {
-	return uc.hotelRepo.UpdateHotel(hotel)
+	existing, err := uc.hotelRepo.GetByID(hotel.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, errors.New("hotel not found")
+	}
+	if existing.Version != hotel.Version {
+		return nil, errStale("hotel")
+	}
+
+	currency, err := normalizeCurrency(hotel.Currency)
+	if err != nil {
+		return nil, err
+	}
+	if currency == "" {
+		currency = existing.Currency
+	}
+
+	if currency != existing.Currency {
+		roomTypes, err := uc.hotelRepo.CountRoomTypes(hotel.ID)
+		if err != nil {
+			return nil, err
+		}
+		if roomTypes > 0 {
+			return nil, fmt.Errorf("%w: hotel currency cannot be changed while the hotel has room types", apperror.ErrInvalidRequest)
+		}
+	}
+	hotel.Currency = currency
+
+	if err := validateTaxRate(hotel.TaxRateBP); err != nil {
+		return nil, err
+	}
+
+	hotel.Description = strings.TrimSpace(hotel.Description)
+	if err := validateHotelDescription(hotel.Description); err != nil {
+		return nil, err
+	}
+
+	if hotel.Latitude == nil && hotel.Longitude == nil {
+		hotel.Latitude, hotel.Longitude = existing.Latitude, existing.Longitude
+	}
+	if err := validateHotelLocation(hotel.Latitude, hotel.Longitude); err != nil {
+		return nil, err
+	}
+
+	if hotel.NoShowCutoffHour == 0 {
+		hotel.NoShowCutoffHour = existing.NoShowCutoffHour
+	}
+	if err := validateNoShowCutoff(hotel.NoShowCutoffHour); err != nil {
+		return nil, err
+	}
+
+	updated, err := uc.hotelRepo.UpdateHotel(hotel)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, errStale("hotel")
+	}
+	return updated, nil
+}
+
+func (uc *HotelUsecase) DeleteHotel(hotelID, version int) error {
+	deleted, err := uc.hotelRepo.DeleteHotel(hotelID, version)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return uc.missingOrStaleHotel(hotelID)
+	}
+	return nil
+}
+
+// missingOrStaleHotel explains why a conditional write to a hotel changed
+// nothing.
+func (uc *HotelUsecase) missingOrStaleHotel(hotelID int) error {
+	hotel, err := uc.hotelRepo.GetByID(hotelID)
+	if err != nil {
+		return err
+	}
+	if hotel == nil {
+		return errors.New("hotel not found")
+	}
+	return errStale("hotel")
+}
+
+func (uc *HotelUsecase) CreateRoomType(roomType data.RoomType) (*data.RoomType, error) {
+	if err := uc.validateRoomType(roomType); err != nil {
+		return nil, err
+	}
+	return uc.roomTypeRepo.Create(&roomType)
+}
+
+func (uc *HotelUsecase) GetRoomType(roomTypeID int) (*data.RoomType, error) {
+	roomType, err := uc.roomTypeRepo.GetByID(roomTypeID)
+	if err != nil {
+		return nil, err
+	}
+	if roomType == nil {
+		return nil, fmt.Errorf("%w: room type not found", apperror.ErrNotFound)
+	}
+
+	roomTypes := []data.RoomType{*roomType}
+	if err := uc.fillRoomAmenities(roomTypes, nil); err != nil {
+		return nil, err
+	}
+	return &roomTypes[0], nil
+}
+
+// UpdateRoomType saves the type if it is still at roomType.Version, so that
+// two managers changing its price or capacity at once cannot overwrite each
+// other.
+func (uc *HotelUsecase) UpdateRoomType(roomType data.RoomType) (*data.RoomType, error) {
+	existing, err := uc.roomTypeRepo.GetByID(roomType.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, fmt.Errorf("%w: room type not found", apperror.ErrNotFound)
+	}
+
+	roomType.HotelID = existing.HotelID
+	if err := uc.validateRoomType(roomType); err != nil {
+		return nil, err
+	}
+
+	updated, err := uc.roomTypeRepo.Update(&roomType)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, uc.missingOrStaleRoomType(roomType.ID)
+	}
+	return updated, nil
+}
+
+// DeleteRoomType removes a type nobody can be booked into any more: it has
+// no rooms and no live bookings. It must still be at version.
+func (uc *HotelUsecase) DeleteRoomType(roomTypeID, version int) error {
+	rooms, err := uc.roomTypeRepo.CountRooms(roomTypeID)
+	if err != nil {
+		return err
+	}
+	if rooms > 0 {
+		return fmt.Errorf("%w: room type still has rooms", apperror.ErrConflict)
+	}
+
+	bookings, err := uc.roomTypeRepo.CountLiveBookings(roomTypeID)
+	if err != nil {
+		return err
+	}
+	if bookings > 0 {
+		return fmt.Errorf("%w: room type still has bookings", apperror.ErrConflict)
+	}
+
+	deleted, err := uc.roomTypeRepo.Delete(roomTypeID, version)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return uc.missingOrStaleRoomType(roomTypeID)
+	}
+	return nil
 }
 
-func (uc *HotelUsecase) DeleteHotel(hotelID int) error {
-	return uc.hotelRepo.DeleteHotel(hotelID)
+// missingOrStaleRoomType explains why a conditional write to a room type
+// changed nothing.
+func (uc *HotelUsecase) missingOrStaleRoomType(roomTypeID int) error {
+	roomType, err := uc.roomTypeRepo.GetByID(roomTypeID)
+	if err != nil {
+		return err
+	}
+	if roomType == nil {
+		return fmt.Errorf("%w: room type not found", apperror.ErrNotFound)
+	}
+	return errStale("room type")
 }
 
 func (uc *HotelUsecase) CreateRoom(room data.Room) (*data.Room, error) {
+	if err := uc.validateRoom(room); err != nil {
+		return nil, err
+	}
 	return uc.roomRepo.CreateRoom(&room)
 }
 
+func (uc *HotelUsecase) GetRoom(roomID int) (*data.Room, error) {
+	room, err := uc.roomRepo.GetByID(roomID)
+	if err != nil {
+		return nil, err
+	}
+	if room == nil {
+		return nil, errors.New("room not found")
+	}
+
+	rooms := []data.Room{*room}
+	if err := uc.fillRoomAmenities(nil, rooms); err != nil {
+		return nil, err
+	}
+	return &rooms[0], nil
+}
+
+// UpdateRoom saves the room if it is still at room.Version, so that two
+// managers editing it at once cannot overwrite each other.
 func (uc *HotelUsecase) UpdateRoom(room data.Room) (*data.Room, error) {
-	return uc.roomRepo.UpdateRoom(&room)
+	if err := uc.validateRoom(room); err != nil {
+		return nil, err
+	}
+
+	updated, err := uc.roomRepo.UpdateRoom(&room)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, uc.missingOrStaleRoom(room.ID)
+	}
+	return updated, nil
+}
+
+func (uc *HotelUsecase) DeleteRoom(roomID, version int) error {
+	deleted, err := uc.roomRepo.DeleteRoom(roomID, version)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return uc.missingOrStaleRoom(roomID)
+	}
+	return nil
+}
+
+// missingOrStaleRoom explains why a conditional write to a room changed
+// nothing.
+func (uc *HotelUsecase) missingOrStaleRoom(roomID int) error {
+	room, err := uc.roomRepo.GetByID(roomID)
+	if err != nil {
+		return err
+	}
+	if room == nil {
+		return errors.New("room not found")
+	}
+	return errStale("room")
 }
 
-func (uc *HotelUsecase) DeleteRoom(roomID int) error {
-	return uc.roomRepo.DeleteRoom(roomID)
-}
\ No newline at end of file
+func validateTaxRate(rateBP int) error {
+	if rateBP < 0 || rateBP > 10000 {
+		return fmt.Errorf("%w: tax_rate_bp must be between 0 and 10000", apperror.ErrInvalidRequest)
+	}
+	return nil
+}
+
+func validateHotelDescription(description string) error {
+	if utf8.RuneCountInString(description) > maxHotelDescriptionLen {
+		return fmt.Errorf("%w: description must be at most %d characters", apperror.ErrInvalidRequest, maxHotelDescriptionLen)
+	}
+	return nil
+}
+
+// validateHotelLocation accepts a hotel with no position, or with both a
+// latitude and a longitude that are on the map.
+func validateHotelLocation(latitude, longitude *float64) error {
+	if latitude == nil && longitude == nil {
+		return nil
+	}
+	if latitude == nil || longitude == nil {
+		return fmt.Errorf("%w: latitude and longitude must be given together", apperror.ErrInvalidRequest)
+	}
+	if err := (geo.Point{Latitude: *latitude, Longitude: *longitude}).Validate(); err != nil {
+		return fmt.Errorf("%w: %v", apperror.ErrInvalidRequest, err)
+	}
+	return nil
+}
+
+// validateNoShowCutoff keeps the cutoff after the standard check-in time
+// and no later than the end of the day after arrival.
+func validateNoShowCutoff(hour int) error {
+	if hour <= standardCheckInHour || hour > 48 {
+		return fmt.Errorf("%w: no_show_cutoff_hour must be between %d and 48", apperror.ErrInvalidRequest, standardCheckInHour+1)
+	}
+	return nil
+}
+
+// validateRoom checks that a room belongs to one of its hotel's types.
+func (uc *HotelUsecase) validateRoom(room data.Room) error {
+	if room.Number == "" {
+		return fmt.Errorf("%w: room number is required", apperror.ErrInvalidRequest)
+	}
+
+	roomType, err := uc.roomTypeRepo.GetByID(room.RoomTypeID)
+	if err != nil {
+		return err
+	}
+	if roomType == nil || roomType.HotelID != room.HotelID {
+		return fmt.Errorf("%w: room_type_id must be a room type of the hotel", apperror.ErrInvalidRequest)
+	}
+	return nil
+}
+
+// validateRoomType checks that a type is priced in its hotel's base
+// currency, which is the currency guests are charged in.
+func (uc *HotelUsecase) validateRoomType(roomType data.RoomType) error {
+	if roomType.Name == "" {
+		return fmt.Errorf("%w: room type name is required", apperror.ErrInvalidRequest)
+	}
+	if roomType.Capacity < 1 {
+		return fmt.Errorf("%w: capacity must be at least 1", apperror.ErrInvalidRequest)
+	}
+	if roomType.Price.Currency == "" || roomType.Price.Amount <= 0 {
+		return fmt.Errorf("%w: room type price must be a positive amount", apperror.ErrInvalidRequest)
+	}
+
+	hotel, err := uc.hotelRepo.GetByID(roomType.HotelID)
+	if err != nil {
+		return err
+	}
+	if hotel == nil {
+		return errors.New("hotel not found")
+	}
+
+	if roomType.Price.Currency != hotel.Currency {
+		return fmt.Errorf("%w: room type price must be in the hotel currency %s", apperror.ErrInvalidRequest, hotel.Currency)
+	}
+	return nil
+}
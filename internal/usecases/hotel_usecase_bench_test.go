@@ -0,0 +1,101 @@
+package usecases_test
+
+import (
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"hotel-booking-service/internal/app/benchseed"
+	"hotel-booking-service/internal/app/connections"
+	"hotel-booking-service/internal/app/store"
+	"hotel-booking-service/internal/data"
+	"hotel-booking-service/internal/services"
+	"hotel-booking-service/internal/usecases"
+)
+
+// BenchmarkHotelAvailability times the availability reads that
+// cmd/benchavailability compares. It only runs when BENCH_DB_NAME names a
+// scratch database that has been migrated and is empty; it seeds 2,000
+// hotels there and empties it again afterwards, so every run times the
+// same data. The set-based reads make the same few queries however many
+// hotels there are, so a read that starts querying per hotel shows up as a
+// jump in its ns/op towards QueryPerHotel's. The other BENCH_DB_ variables
+// default to a local Postgres.
+//
+//	BENCH_DB_NAME=hotel_booking_bench go test -run '^$' -bench HotelAvailability ./internal/usecases
+func BenchmarkHotelAvailability(b *testing.B) {
+	name := os.Getenv("BENCH_DB_NAME")
+	if name == "" {
+		b.Skip("BENCH_DB_NAME not set; point it at a migrated, empty scratch database to run")
+	}
+
+	port, _ := strconv.Atoi(benchEnv("BENCH_DB_PORT", "5432"))
+	db, err := connections.NewPostgresConnection(connections.PostgresConfig{
+		Host:     benchEnv("BENCH_DB_HOST", "localhost"),
+		Port:     port,
+		User:     benchEnv("BENCH_DB_USER", "postgres"),
+		Password: benchEnv("BENCH_DB_PASSWORD", "postgres"),
+		DBName:   name,
+		SSLMode:  benchEnv("BENCH_DB_SSLMODE", "disable"),
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer db.Close()
+
+	if err := benchseed.Seed(db, 2000, 3, 10); err != nil {
+		b.Fatal(err)
+	}
+	defer func() {
+		if err := benchseed.Clear(db); err != nil {
+			b.Errorf("emptying the benchmark database: %v", err)
+		}
+	}()
+
+	appStore := store.NewStore(db)
+	currencyUsecase := usecases.NewCurrencyUsecase(appStore.RateRepo)
+	restrictionUsecase := usecases.NewRestrictionUsecase(appStore.RestrictRepo, appStore.HotelRepo, appStore.RoomTypeRepo)
+	hotelUsecase := usecases.NewHotelUsecase(appStore.HotelRepo, appStore.RoomRepo, appStore.RoomTypeRepo, appStore.AmenityRepo, currencyUsecase, restrictionUsecase)
+	hotelService := services.NewHotelService(appStore.HotelRepo, appStore.RoomRepo)
+
+	fromDate := time.Now().AddDate(0, 0, 1)
+	toDate := fromDate.AddDate(0, 0, 3)
+
+	b.Run("QueryPerHotel", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			listed, err := appStore.HotelRepo.GetAllHotels(data.HotelFilter{})
+			if err != nil {
+				b.Fatal(err)
+			}
+			for _, hotel := range listed {
+				if _, err := appStore.RoomRepo.GetAvailableRoomsByHotelID(hotel.ID, fromDate, toDate); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+
+	b.Run("HotelService", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := hotelService.GetAllHotels(fromDate, toDate); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("HotelUsecase", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := hotelUsecase.GetAllHotels(fromDate, toDate, "", data.HotelFilter{}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func benchEnv(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,388 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"hotel-booking-service/internal/data"
+	"hotel-booking-service/internal/pkg/apperror"
+	"hotel-booking-service/internal/repositories"
+)
+
+// InventoryUsecase counts units per room type and night. A booking uses up
+// one unit of its type on each night of the stay, whether or not it has a
+// room yet, and a blocked room takes one unit out of its type.
+type InventoryUsecase struct {
+	overbookingRepo *repositories.OverbookingRepository
+	blockRepo       *repositories.RoomBlockRepository
+	hotelRepo       *repositories.HotelRepository
+	roomRepo        *repositories.RoomRepository
+	roomTypeRepo    *repositories.RoomTypeRepository
+	bookingRepo     *repositories.BookingRepository
+}
+
+func NewInventoryUsecase(
+	overbookingRepo *repositories.OverbookingRepository,
+	blockRepo *repositories.RoomBlockRepository,
+	hotelRepo *repositories.HotelRepository,
+	roomRepo *repositories.RoomRepository,
+	roomTypeRepo *repositories.RoomTypeRepository,
+	bookingRepo *repositories.BookingRepository,
+) *InventoryUsecase {
+	return &InventoryUsecase{
+		overbookingRepo: overbookingRepo,
+		blockRepo:       blockRepo,
+		hotelRepo:       hotelRepo,
+		roomRepo:        roomRepo,
+		roomTypeRepo:    roomTypeRepo,
+		bookingRepo:     bookingRepo,
+	}
+}
+
+func (uc *InventoryUsecase) GetAllowances(hotelID int) ([]data.OverbookingAllowance, error) {
+	return uc.overbookingRepo.GetByHotelID(hotelID)
+}
+
+func (uc *InventoryUsecase) CreateAllowance(allowance data.OverbookingAllowance) (*data.OverbookingAllowance, error) {
+	if allowance.ToDate.Before(allowance.FromDate) {
+		return nil, fmt.Errorf("%w: to_date must not be before from_date", apperror.ErrInvalidRequest)
+	}
+	if allowance.Percent < 0 || allowance.Percent > 100 {
+		return nil, fmt.Errorf("%w: percent must be between 0 and 100", apperror.ErrInvalidRequest)
+	}
+
+	hotel, err := uc.hotelRepo.GetByID(allowance.HotelID)
+	if err != nil {
+		return nil, err
+	}
+	if hotel == nil {
+		return nil, errors.New("hotel not found")
+	}
+
+	return uc.overbookingRepo.Create(&allowance)
+}
+
+func (uc *InventoryUsecase) DeleteAllowance(id int) error {
+	return uc.overbookingRepo.Delete(id)
+}
+
+func (uc *InventoryUsecase) GetHotelBlocks(hotelID int, fromDate, toDate time.Time) ([]data.RoomBlock, error) {
+	return uc.blockRepo.GetForHotel(hotelID, fromDate, toDate)
+}
+
+// CreateBlock takes a room out of service. The room must be free for the
+// whole period; guests already booked are moved or walked first.
+func (uc *InventoryUsecase) CreateBlock(block data.RoomBlock) (*data.RoomBlock, error) {
+	if block.Reason == "" {
+		return nil, fmt.Errorf("%w: reason is required", apperror.ErrInvalidRequest)
+	}
+	if nightsBetween(block.FromDate, block.ToDate) < 1 {
+		return nil, fmt.Errorf("%w: a block must cover at least one night", apperror.ErrInvalidRequest)
+	}
+
+	room, err := uc.roomRepo.GetByID(block.RoomID)
+	if err != nil {
+		return nil, err
+	}
+	if room == nil {
+		return nil, errors.New("room not found")
+	}
+
+	available, err := uc.roomRepo.CheckRoomAvailability(room.ID, block.FromDate, block.ToDate)
+	if err != nil {
+		return nil, err
+	}
+	if !available {
+		return nil, fmt.Errorf("%w: room %s is booked, held or already blocked on these dates", apperror.ErrConflict, room.Number)
+	}
+
+	units, err := uc.roomTypeRepo.AvailableUnits(room.RoomTypeID, block.FromDate, block.ToDate)
+	if err != nil {
+		return nil, err
+	}
+	if units < 1 {
+		return nil, fmt.Errorf("%w: every %s is sold on some of these dates", apperror.ErrConflict, room.RoomType)
+	}
+
+	return uc.blockRepo.Create(&block)
+}
+
+func (uc *InventoryUsecase) DeleteBlock(id int) error {
+	return uc.blockRepo.Delete(id)
+}
+
+// CanOversell reports whether the room type still has unsold units,
+// counting the hotel's overbooking allowance, on every night of the stay.
+// It is consulted only once the type has no free unit left, and never for
+// a requested room that is already taken.
+// A blocked room is never sold, whatever the allowance.
+func (uc *InventoryUsecase) CanOversell(roomType *data.RoomType, room *data.Room, fromDate, toDate time.Time) (bool, error) {
+	if room != nil {
+		blocks, err := uc.blockRepo.GetForHotel(room.HotelID, fromDate, toDate)
+		if err != nil {
+			return false, err
+		}
+		for _, block := range blocks {
+			if block.RoomID == room.ID {
+				return false, nil
+			}
+		}
+	}
+
+	nights, err := uc.categoryNights(roomType.HotelID, fromDate, toDate)
+	if err != nil {
+		return false, err
+	}
+
+	for _, night := range nights {
+		if night.RoomTypeID == roomType.ID && night.Sold >= night.Limit {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// OversoldReport shows, for the front desk, the nights on which a room type
+// has more bookings than rooms and the rooms that were sold twice.
+func (uc *InventoryUsecase) OversoldReport(hotelID int, fromDate, toDate time.Time) (*data.OversoldReport, error) {
+	if nightsBetween(fromDate, toDate) < 1 {
+		return nil, fmt.Errorf("%w: to_date must be after from_date", apperror.ErrInvalidRequest)
+	}
+
+	hotel, err := uc.hotelRepo.GetByID(hotelID)
+	if err != nil {
+		return nil, err
+	}
+	if hotel == nil {
+		return nil, errors.New("hotel not found")
+	}
+
+	nights, err := uc.categoryNights(hotelID, fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &data.OversoldReport{
+		HotelID:   hotelID,
+		FromDate:  fromDate,
+		ToDate:    toDate,
+		Nights:    []data.CategoryNight{},
+		Conflicts: []data.RoomConflict{},
+	}
+	for _, night := range nights {
+		if night.Oversold > 0 {
+			report.Nights = append(report.Nights, night)
+		}
+	}
+
+	rooms, err := uc.hotelRepo.GetRoomsByHotelID(hotelID)
+	if err != nil {
+		return nil, err
+	}
+	bookings, err := uc.bookingRepo.GetHotelBookings(hotelID, fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+	report.Conflicts = roomConflicts(rooms, bookings)
+
+	return report, nil
+}
+
+// categoryNights builds the inventory of every room type for each night
+// from fromDate up to, but not including, toDate.
+func (uc *InventoryUsecase) categoryNights(hotelID int, fromDate, toDate time.Time) ([]data.CategoryNight, error) {
+	roomTypes, err := uc.roomTypeRepo.GetByHotelID(hotelID)
+	if err != nil {
+		return nil, err
+	}
+	rooms, err := uc.hotelRepo.GetRoomsByHotelID(hotelID)
+	if err != nil {
+		return nil, err
+	}
+	bookings, err := uc.bookingRepo.GetHotelBookings(hotelID, fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+	allowances, err := uc.overbookingRepo.GetForRange(hotelID, fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := uc.blockRepo.GetForHotel(hotelID, fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+
+	roomsByType := map[int]int{}
+	typeOf := map[int]int{}
+	for _, room := range rooms {
+		roomsByType[room.RoomTypeID]++
+		typeOf[room.ID] = room.RoomTypeID
+	}
+
+	var nights []data.CategoryNight
+	for night := dateOnly(fromDate); night.Before(dateOnly(toDate)); night = night.AddDate(0, 0, 1) {
+		percent := 0
+		for _, allowance := range allowances {
+			if !night.Before(dateOnly(allowance.FromDate)) && !night.After(dateOnly(allowance.ToDate)) && allowance.Percent > percent {
+				percent = allowance.Percent
+			}
+		}
+
+		sold := map[int]int{}
+		for _, booking := range bookings {
+			if coversNight(booking.FromDate, booking.ToDate, night) {
+				sold[booking.RoomTypeID]++
+			}
+		}
+
+		blocked := map[int]int{}
+		for _, block := range blocks {
+			if coversNight(block.FromDate, block.ToDate, night) {
+				blocked[typeOf[block.RoomID]]++
+			}
+		}
+
+		for _, roomType := range roomTypes {
+			count := roomsByType[roomType.ID] - blocked[roomType.ID]
+			entry := data.CategoryNight{
+				Night:      night,
+				RoomTypeID: roomType.ID,
+				RoomType:   roomType.Name,
+				Rooms:      count,
+				Sold:       sold[roomType.ID],
+				Limit:      count + count*percent/100,
+			}
+			if entry.Sold > entry.Rooms {
+				entry.Oversold = entry.Sold - entry.Rooms
+			}
+			nights = append(nights, entry)
+		}
+	}
+
+	return nights, nil
+}
+
+// roomConflicts groups bookings that share a room on overlapping nights.
+func roomConflicts(rooms []data.Room, bookings []data.Booking) []data.RoomConflict {
+	byRoom := map[int][]data.Booking{}
+	for _, booking := range bookings {
+		byRoom[booking.RoomID] = append(byRoom[booking.RoomID], booking)
+	}
+
+	conflicts := []data.RoomConflict{}
+	for _, room := range rooms {
+		roomBookings := byRoom[room.ID]
+		var clashing []data.Booking
+		for i, a := range roomBookings {
+			for j, b := range roomBookings {
+				if i != j && a.FromDate.Before(b.ToDate) && b.FromDate.Before(a.ToDate) {
+					clashing = append(clashing, a)
+					break
+				}
+			}
+		}
+		if len(clashing) > 0 {
+			conflicts = append(conflicts, data.RoomConflict{
+				RoomID:     room.ID,
+				RoomNumber: room.Number,
+				Bookings:   clashing,
+			})
+		}
+	}
+	return conflicts
+}
+
+// HotelCalendar lays out every room of the hotel night by night, with the
+// hotel's occupancy for each night.
+func (uc *InventoryUsecase) HotelCalendar(hotelID int, fromDate, toDate time.Time) (*data.HotelCalendar, error) {
+	if nightsBetween(fromDate, toDate) < 1 {
+		return nil, fmt.Errorf("%w: to_date must be after from_date", apperror.ErrInvalidRequest)
+	}
+
+	hotel, err := uc.hotelRepo.GetByID(hotelID)
+	if err != nil {
+		return nil, err
+	}
+	if hotel == nil {
+		return nil, errors.New("hotel not found")
+	}
+
+	rooms, err := uc.hotelRepo.GetRoomsByHotelID(hotelID)
+	if err != nil {
+		return nil, err
+	}
+	bookings, err := uc.bookingRepo.GetHotelBookings(hotelID, fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := uc.blockRepo.GetForHotel(hotelID, fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+
+	calendar := &data.HotelCalendar{
+		HotelID:   hotelID,
+		FromDate:  fromDate,
+		ToDate:    toDate,
+		Rooms:     []data.RoomCalendar{},
+		Occupancy: []data.OccupancyNight{},
+	}
+
+	for _, room := range rooms {
+		roomCalendar := data.RoomCalendar{RoomID: room.ID, RoomNumber: room.Number}
+		for night := dateOnly(fromDate); night.Before(dateOnly(toDate)); night = night.AddDate(0, 0, 1) {
+			entry := data.CalendarNight{Night: night, Status: data.CalendarFree}
+			for _, block := range blocks {
+				if block.RoomID == room.ID && coversNight(block.FromDate, block.ToDate, night) {
+					entry.Status = data.CalendarBlocked
+					entry.BlockID = block.ID
+					entry.Reason = block.Reason
+				}
+			}
+			for _, booking := range bookings {
+				if booking.RoomID == room.ID && coversNight(booking.FromDate, booking.ToDate, night) {
+					entry.Status = data.CalendarBooked
+					entry.BookingIDs = append(entry.BookingIDs, booking.ID)
+				}
+			}
+			roomCalendar.Nights = append(roomCalendar.Nights, entry)
+		}
+		calendar.Rooms = append(calendar.Rooms, roomCalendar)
+	}
+
+	for i := 0; i < nightsBetween(fromDate, toDate); i++ {
+		occupancy := data.OccupancyNight{Night: dateOnly(fromDate).AddDate(0, 0, i)}
+		for _, room := range calendar.Rooms {
+			if room.Nights[i].Status == data.CalendarBlocked {
+				occupancy.Blocked++
+			}
+		}
+		for _, booking := range bookings {
+			if coversNight(booking.FromDate, booking.ToDate, occupancy.Night) {
+				occupancy.Sold++
+				if booking.RoomID == 0 {
+					occupancy.Unassigned++
+				}
+			}
+		}
+		occupancy.Rooms = len(rooms) - occupancy.Blocked
+		if occupancy.Rooms > 0 {
+			occupancy.OccupancyPercent = math.Round(float64(occupancy.Sold)*10000/float64(occupancy.Rooms)) / 100
+		}
+		calendar.Occupancy = append(calendar.Occupancy, occupancy)
+	}
+
+	return calendar, nil
+}
+
+// coversNight reports whether a stay or block from fromDate to toDate
+// includes the given night.
+func coversNight(fromDate, toDate, night time.Time) bool {
+	return !night.Before(dateOnly(fromDate)) && night.Before(dateOnly(toDate))
+}
+
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
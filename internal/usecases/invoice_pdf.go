@@ -0,0 +1,102 @@
+package usecases
+
+import (
+	"fmt"
+
+	"hotel-booking-service/internal/data"
+	"hotel-booking-service/internal/pkg/pdf"
+)
+
+const (
+	pdfMargin   = 50.0
+	pdfRight    = pdf.PageWidth - pdfMargin
+	pdfQtyCol   = 340.0
+	pdfPriceCol = 440.0
+)
+
+// RenderPDF lays out an invoice and each of its credit notes on their own
+// page of a single document.
+func (uc *InvoiceUsecase) RenderPDF(invoices *data.BookingInvoices) []byte {
+	doc := pdf.New()
+	renderInvoicePage(doc.AddPage(), invoices.Invoice)
+	for i := range invoices.CreditNotes {
+		renderInvoicePage(doc.AddPage(), &invoices.CreditNotes[i])
+	}
+	return doc.Bytes()
+}
+
+func renderInvoicePage(page *pdf.Page, invoice *data.Invoice) {
+	title := "Invoice"
+	if invoice.Kind == data.InvoiceKindCreditNote {
+		title = "Credit note"
+	}
+
+	y := pdf.PageHeight - pdfMargin - 10
+	page.Text(pdfMargin, y, pdf.Bold, 20, title)
+	page.TextRight(pdfRight, y, pdf.Bold, 12, invoice.Number)
+
+	y -= 18
+	page.TextRight(pdfRight, y, pdf.Regular, 10, "Issued "+invoice.IssuedAt.Format("2 January 2006"))
+	if invoice.CreditedNumber != "" {
+		y -= 14
+		page.TextRight(pdfRight, y, pdf.Regular, 10, "Credits invoice "+invoice.CreditedNumber)
+	}
+
+	y -= 30
+	page.Text(pdfMargin, y, pdf.Bold, 10, "From")
+	page.Text(300, y, pdf.Bold, 10, "Bill to")
+	y -= 14
+	page.Text(pdfMargin, y, pdf.Regular, 10, invoice.Seller.Name)
+	page.Text(300, y, pdf.Regular, 10, invoice.Buyer.Name)
+	y -= 14
+	page.Text(pdfMargin, y, pdf.Regular, 10, invoice.Seller.City)
+	if invoice.Buyer.Email != invoice.Buyer.Name {
+		page.Text(300, y, pdf.Regular, 10, invoice.Buyer.Email)
+	}
+
+	y -= 28
+	page.Text(pdfMargin, y, pdf.Regular, 10, fmt.Sprintf("Booking #%d, stay %s to %s",
+		invoice.BookingID, invoice.StayFrom.Format("2006-01-02"), invoice.StayTo.Format("2006-01-02")))
+	if invoice.Reason != "" {
+		y -= 14
+		page.Text(pdfMargin, y, pdf.Regular, 10, "Reason: "+invoice.Reason)
+	}
+
+	y -= 30
+	page.Text(pdfMargin, y, pdf.Bold, 10, "Description")
+	page.TextRight(pdfQtyCol, y, pdf.Bold, 10, "Qty")
+	page.TextRight(pdfPriceCol, y, pdf.Bold, 10, "Unit price")
+	page.TextRight(pdfRight, y, pdf.Bold, 10, "Amount")
+	y -= 6
+	page.Line(pdfMargin, y, pdfRight, y, 0.75)
+
+	for _, line := range invoice.Lines {
+		y -= 16
+		page.Text(pdfMargin, y, pdf.Regular, 10, line.Description)
+		page.TextRight(pdfQtyCol, y, pdf.Regular, 10, fmt.Sprint(line.Quantity))
+		page.TextRight(pdfPriceCol, y, pdf.Regular, 10, line.UnitPrice.String())
+		page.TextRight(pdfRight, y, pdf.Regular, 10, line.Amount.String())
+	}
+
+	y -= 10
+	page.Line(pdfMargin, y, pdfRight, y, 0.75)
+
+	y -= 18
+	page.Text(pdfPriceCol-80, y, pdf.Regular, 10, "Net")
+	page.TextRight(pdfRight, y, pdf.Regular, 10, invoice.Net.String())
+	for _, tax := range invoice.Taxes {
+		y -= 14
+		page.Text(pdfPriceCol-80, y, pdf.Regular, 10, tax.Description)
+		page.TextRight(pdfRight, y, pdf.Regular, 10, tax.Amount.String())
+	}
+
+	totalLabel := "Total"
+	if invoice.Kind == data.InvoiceKindCreditNote {
+		totalLabel = "Total credited"
+	}
+	y -= 18
+	page.Text(pdfPriceCol-80, y, pdf.Bold, 11, totalLabel)
+	page.TextRight(pdfRight, y, pdf.Bold, 11, invoice.Total.String())
+
+	page.Text(pdfMargin, pdfMargin, pdf.Regular, 8, "Prices include tax. This document was issued electronically and is valid without a signature.")
+}
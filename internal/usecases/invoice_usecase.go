@@ -0,0 +1,360 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"hotel-booking-service/internal/data"
+	"hotel-booking-service/internal/pkg/apperror"
+	"hotel-booking-service/internal/pkg/money"
+	"hotel-booking-service/internal/repositories"
+)
+
+type InvoiceUsecase struct {
+	invoiceRepo  *repositories.InvoiceRepository
+	bookingRepo  *repositories.BookingRepository
+	roomRepo     *repositories.RoomRepository
+	roomTypeRepo *repositories.RoomTypeRepository
+	hotelRepo    *repositories.HotelRepository
+	userRepo     *repositories.UserRepository
+	extraRepo    *repositories.ExtraRepository
+}
+
+func NewInvoiceUsecase(
+	invoiceRepo *repositories.InvoiceRepository,
+	bookingRepo *repositories.BookingRepository,
+	roomRepo *repositories.RoomRepository,
+	roomTypeRepo *repositories.RoomTypeRepository,
+	hotelRepo *repositories.HotelRepository,
+	userRepo *repositories.UserRepository,
+	extraRepo *repositories.ExtraRepository,
+) *InvoiceUsecase {
+	return &InvoiceUsecase{
+		invoiceRepo:  invoiceRepo,
+		bookingRepo:  bookingRepo,
+		roomRepo:     roomRepo,
+		roomTypeRepo: roomTypeRepo,
+		hotelRepo:    hotelRepo,
+		userRepo:     userRepo,
+		extraRepo:    extraRepo,
+	}
+}
+
+// GetBookingInvoices returns the booking's invoice and its credit notes. The
+// invoice is issued on first request once the booking has been paid; after
+// that the same document is returned every time.
+func (uc *InvoiceUsecase) GetBookingInvoices(userID, bookingID int) (*data.BookingInvoices, error) {
+	booking, err := uc.bookingRepo.GetBooking(bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if booking == nil {
+		return nil, errors.New("booking not found")
+	}
+
+	if booking.UserID != userID {
+		return nil, errors.New("booking does not belong to this user")
+	}
+
+	invoice, err := uc.IssueInvoice(booking)
+	if err != nil {
+		return nil, err
+	}
+
+	creditNotes, err := uc.invoiceRepo.GetCreditNotes(invoice.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &data.BookingInvoices{Invoice: invoice, CreditNotes: creditNotes}, nil
+}
+
+// IssueInvoice returns the booking's invoice, issuing it if this is the first
+// time it is asked for. Only paid bookings are invoiced.
+func (uc *InvoiceUsecase) IssueInvoice(booking *data.Booking) (*data.Invoice, error) {
+	invoice, err := uc.invoiceRepo.GetForBooking(booking.ID)
+	if err != nil || invoice != nil {
+		return invoice, err
+	}
+
+	switch booking.Status {
+	case data.BookingStatusConfirmed, data.BookingStatusCheckedIn, data.BookingStatusCheckedOut:
+		return uc.issueInvoice(booking)
+	default:
+		return nil, fmt.Errorf("%w: invoices are only issued for paid bookings", apperror.ErrConflict)
+	}
+}
+
+// IssueCreditNote corrects an issued invoice. Without an amount it credits
+// everything not yet credited.
+func (uc *InvoiceUsecase) IssueCreditNote(invoiceID int, amount *money.Money, reason string) (*data.Invoice, error) {
+	invoice, err := uc.invoiceRepo.GetByID(invoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if invoice == nil || invoice.Kind != data.InvoiceKindInvoice {
+		return nil, fmt.Errorf("%w: invoice not found", apperror.ErrNotFound)
+	}
+
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, fmt.Errorf("%w: a reason is required", apperror.ErrInvalidRequest)
+	}
+
+	remaining, err := uc.uncredited(invoice)
+	if err != nil {
+		return nil, err
+	}
+
+	credit := remaining
+	if amount != nil {
+		credit = *amount
+	}
+
+	if credit.Currency != invoice.Total.Currency {
+		return nil, fmt.Errorf("%w: credit must be in the invoice currency %s", apperror.ErrInvalidRequest, invoice.Total.Currency)
+	}
+	if credit.Amount <= 0 || credit.Amount > remaining.Amount {
+		return nil, fmt.Errorf("%w: credit must be positive and at most %s", apperror.ErrInvalidRequest, remaining)
+	}
+
+	return uc.invoiceRepo.Issue(creditNoteFor(invoice, credit, reason))
+}
+
+// CreditCancellation issues a credit note for the refund due on a cancelled
+// booking that was already invoiced. Bookings never invoiced need nothing.
+func (uc *InvoiceUsecase) CreditCancellation(bookingID int, refund money.Money) error {
+	return uc.creditRefund(bookingID, refund, "Booking cancelled")
+}
+
+// CreditEarlyDeparture credits the nights a guest did not stay when the
+// booking was invoiced before they left early.
+func (uc *InvoiceUsecase) CreditEarlyDeparture(bookingID int, refund money.Money) error {
+	return uc.creditRefund(bookingID, refund, "Early departure")
+}
+
+func (uc *InvoiceUsecase) creditRefund(bookingID int, refund money.Money, reason string) error {
+	if refund.Amount <= 0 {
+		return nil
+	}
+
+	invoice, err := uc.invoiceRepo.GetForBooking(bookingID)
+	if err != nil || invoice == nil {
+		return err
+	}
+
+	remaining, err := uc.uncredited(invoice)
+	if err != nil {
+		return err
+	}
+
+	credit := refund.Min(remaining)
+	if credit.Amount <= 0 {
+		return nil
+	}
+
+	_, err = uc.invoiceRepo.Issue(creditNoteFor(invoice, credit, reason))
+	return err
+}
+
+func (uc *InvoiceUsecase) issueInvoice(booking *data.Booking) (*data.Invoice, error) {
+	roomType, err := uc.roomTypeRepo.GetByID(booking.RoomTypeID)
+	if err != nil {
+		return nil, err
+	}
+	if roomType == nil {
+		return nil, errors.New("room not found")
+	}
+
+	description := roomType.Name
+	if booking.RoomID != 0 {
+		room, err := uc.roomRepo.GetByID(booking.RoomID)
+		if err != nil {
+			return nil, err
+		}
+		if room != nil {
+			description = fmt.Sprintf("%s, room %s", roomType.Name, room.Number)
+		}
+	}
+
+	hotel, err := uc.hotelRepo.GetByID(roomType.HotelID)
+	if err != nil {
+		return nil, err
+	}
+	if hotel == nil {
+		return nil, errors.New("hotel not found")
+	}
+
+	guest, err := uc.userRepo.GetByID(booking.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if guest == nil {
+		return nil, errors.New("user not found")
+	}
+
+	nights := nightsBetween(booking.FromDate, booking.ToDate)
+	invoice := &data.Invoice{
+		Kind:      data.InvoiceKindInvoice,
+		HotelID:   hotel.ID,
+		BookingID: booking.ID,
+		IssuedAt:  time.Now().UTC().Truncate(time.Second),
+		Seller:    data.InvoiceParty{Name: hotel.Name, City: hotel.City},
+		Buyer:     data.InvoiceParty{Name: guest.Name, Email: guest.Email},
+		StayFrom:  booking.FromDate,
+		StayTo:    booking.ToDate,
+		Lines: []data.InvoiceLine{{
+			Description: fmt.Sprintf("%s, %d night(s)", description, nights),
+			Quantity:    nights,
+			UnitPrice:   booking.NightlyRate,
+			Amount:      booking.TotalPrice,
+		}},
+	}
+	// Extras get a line each, taken out of the room line, which holds the
+	// rest of the total.
+	if booking.ExtrasTotal != nil {
+		extras, err := uc.extraRepo.GetForBookings([]int{booking.ID})
+		if err != nil {
+			return nil, err
+		}
+		for _, extra := range extras[booking.ID] {
+			invoice.Lines[0].Amount, err = invoice.Lines[0].Amount.Sub(extra.Total)
+			if err != nil {
+				return nil, err
+			}
+			invoice.Lines = append(invoice.Lines, data.InvoiceLine{
+				Description: extraDescription(extra),
+				Quantity:    extra.Quantity,
+				UnitPrice:   extra.UnitPrice,
+				Amount:      extra.Total,
+			})
+		}
+	}
+	// Points paid for part of the stay: the room is invoiced at its price
+	// and the points as a discount against it.
+	if booking.LoyaltyDiscount != nil && !booking.LoyaltyDiscount.IsZero() {
+		invoice.Lines[0].Amount, err = invoice.Lines[0].Amount.Add(*booking.LoyaltyDiscount)
+		if err != nil {
+			return nil, err
+		}
+		invoice.Lines = append(invoice.Lines, data.InvoiceLine{
+			Description: fmt.Sprintf("Loyalty points redeemed (%d)", booking.PointsRedeemed),
+			Quantity:    1,
+			UnitPrice:   booking.LoyaltyDiscount.Neg(),
+			Amount:      booking.LoyaltyDiscount.Neg(),
+		})
+	}
+	if invoice.Buyer.Name == "" {
+		invoice.Buyer.Name = guest.Email
+	}
+	applyTax(invoice, hotel.TaxRateBP)
+
+	issued, err := uc.invoiceRepo.Issue(invoice)
+	if err != nil {
+		// A concurrent request may have issued it first; that one wins.
+		existing, getErr := uc.invoiceRepo.GetForBooking(booking.ID)
+		if getErr == nil && existing != nil {
+			return existing, nil
+		}
+		return nil, err
+	}
+
+	log.Printf("Issued invoice %s for booking %d", issued.Number, booking.ID)
+	return issued, nil
+}
+
+func (uc *InvoiceUsecase) uncredited(invoice *data.Invoice) (money.Money, error) {
+	notes, err := uc.invoiceRepo.GetCreditNotes(invoice.ID)
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	remaining := invoice.Total
+	for _, note := range notes {
+		remaining, err = remaining.Sub(note.Total)
+		if err != nil {
+			return money.Money{}, err
+		}
+	}
+	return remaining, nil
+}
+
+func creditNoteFor(invoice *data.Invoice, amount money.Money, reason string) *data.Invoice {
+	invoiceID := invoice.ID
+	note := &data.Invoice{
+		Kind:              data.InvoiceKindCreditNote,
+		HotelID:           invoice.HotelID,
+		BookingID:         invoice.BookingID,
+		CreditedInvoiceID: &invoiceID,
+		CreditedNumber:    invoice.Number,
+		Reason:            reason,
+		IssuedAt:          time.Now().UTC().Truncate(time.Second),
+		Seller:            invoice.Seller,
+		Buyer:             invoice.Buyer,
+		StayFrom:          invoice.StayFrom,
+		StayTo:            invoice.StayTo,
+		Lines: []data.InvoiceLine{{
+			Description: fmt.Sprintf("Credit against invoice %s: %s", invoice.Number, reason),
+			Quantity:    1,
+			UnitPrice:   amount,
+			Amount:      amount,
+		}},
+	}
+
+	rateBP := 0
+	if len(invoice.Taxes) > 0 {
+		rateBP = invoice.Taxes[0].RateBP
+	}
+	applyTax(note, rateBP)
+	return note
+}
+
+// applyTax totals the lines and splits out the tax they include. Prices are
+// tax-inclusive, so tax = gross * rate / (1 + rate).
+func applyTax(invoice *data.Invoice, rateBP int) {
+	total := money.Zero(invoice.Lines[0].Amount.Currency)
+	for _, line := range invoice.Lines {
+		total, _ = total.Add(line.Amount)
+	}
+
+	tax := total.MulRatio(int64(rateBP), 10000+int64(rateBP))
+	net, _ := total.Sub(tax)
+
+	invoice.Net = net
+	invoice.Tax = tax
+	invoice.Total = total
+	invoice.Taxes = []data.InvoiceTax{}
+	if rateBP > 0 {
+		invoice.Taxes = append(invoice.Taxes, data.InvoiceTax{
+			Description: "Tax " + formatPercent(rateBP),
+			RateBP:      rateBP,
+			Taxable:     net,
+			Amount:      tax,
+		})
+	}
+}
+
+// extraDescription names an extra's invoice line. Its unit price is per
+// item, night or guest night as the extra is priced.
+func extraDescription(extra data.BookingExtra) string {
+	nights := nightsBetween(extra.FromDate, extra.ToDate)
+	switch extra.Pricing {
+	case data.ExtraPerNight:
+		return fmt.Sprintf("%s, %d night(s)", extra.Name, nights)
+	case data.ExtraPerGuest:
+		return fmt.Sprintf("%s, %d guest(s) x %d night(s)", extra.Name, extra.Quantity, nights)
+	}
+	return extra.Name
+}
+
+// formatPercent renders basis points as a percentage, e.g. 1250 as "12.5%".
+func formatPercent(basisPoints int) string {
+	s := strconv.FormatFloat(float64(basisPoints)/100, 'f', -1, 64)
+	return s + "%"
+}
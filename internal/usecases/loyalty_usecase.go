@@ -0,0 +1,225 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"hotel-booking-service/internal/data"
+	"hotel-booking-service/internal/events"
+	"hotel-booking-service/internal/pkg/apperror"
+	"hotel-booking-service/internal/pkg/money"
+	"hotel-booking-service/internal/repositories"
+)
+
+// Members earn one point per whole unit of the program currency paid for a
+// stay, plus their tier's bonus, and redeem points in blocks of
+// pointsPerUnit for one unit of it off a booking. Prices in other currencies
+// are converted, so a point is worth the same at every hotel.
+const (
+	pointsPerUnit        = 100
+	tierQualifyingPeriod = 12 // months
+)
+
+// loyaltyTiers is ordered from the entry tier up. A member's tier is the
+// highest one whose qualifying points they earned over the last
+// tierQualifyingPeriod months.
+var loyaltyTiers = []data.LoyaltyTier{
+	{
+		Name:     "member",
+		Benefits: []string{"1 point per unit spent on stays"},
+	},
+	{
+		Name:             "silver",
+		QualifyingPoints: 1000,
+		EarnBonusPercent: 25,
+		Benefits:         []string{"25% bonus points on stays"},
+	},
+	{
+		Name:             "gold",
+		QualifyingPoints: 5000,
+		EarnBonusPercent: 50,
+		Benefits:         []string{"50% bonus points on stays"},
+	},
+}
+
+type LoyaltyUsecase struct {
+	loyaltyRepo     *repositories.LoyaltyRepository
+	currencyUsecase *CurrencyUsecase
+	currency        string
+	expiryMonths    int
+}
+
+// NewLoyaltyUsecase sets up the program, with points valued in currency.
+// Points expire once a member has had no loyalty activity for expiryMonths.
+func NewLoyaltyUsecase(loyaltyRepo *repositories.LoyaltyRepository, currencyUsecase *CurrencyUsecase, currency string, expiryMonths int) *LoyaltyUsecase {
+	return &LoyaltyUsecase{
+		loyaltyRepo:     loyaltyRepo,
+		currencyUsecase: currencyUsecase,
+		currency:        currency,
+		expiryMonths:    expiryMonths,
+	}
+}
+
+func (uc *LoyaltyUsecase) GetAccount(userID int) (*data.LoyaltyAccount, error) {
+	balance, err := uc.loyaltyRepo.Balance(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	qualifying, tier, err := uc.tier(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := uc.loyaltyRepo.GetForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	account := &data.LoyaltyAccount{
+		Balance:          balance,
+		Tier:             loyaltyTiers[tier],
+		QualifyingPoints: qualifying,
+		History:          history,
+	}
+	if tier+1 < len(loyaltyTiers) {
+		next := loyaltyTiers[tier+1]
+		account.NextTier = &next
+	}
+
+	if balance > 0 {
+		last, err := uc.loyaltyRepo.LastActivity(userID)
+		if err != nil {
+			return nil, err
+		}
+		if last != nil {
+			expires := last.AddDate(0, uc.expiryMonths, 0)
+			account.PointsExpireAt = &expires
+		}
+	}
+
+	return account, nil
+}
+
+// Redeem spends points towards a booking priced at total and returns the
+// redemption with the discount it buys. The redemption must be attached to
+// the booking once it is made, or released if it is not.
+func (uc *LoyaltyUsecase) Redeem(userID, points int, total money.Money) (*data.LoyaltyEntry, money.Money, error) {
+	if points <= 0 || points%pointsPerUnit != 0 {
+		return nil, money.Money{}, fmt.Errorf("%w: redeem_points must be a positive multiple of %d", apperror.ErrInvalidRequest, pointsPerUnit)
+	}
+
+	value := money.New(int64(points/pointsPerUnit)*unitSize(uc.currency), uc.currency)
+	discount, _, err := uc.currencyUsecase.Convert(value, total.Currency)
+	if err != nil {
+		return nil, money.Money{}, err
+	}
+	if discount.Amount > total.Amount {
+		return nil, money.Money{}, fmt.Errorf("%w: %d points are worth %s, more than the price of %s", apperror.ErrInvalidRequest, points, discount, total)
+	}
+
+	entry, err := uc.loyaltyRepo.Redeem(userID, points, fmt.Sprintf("Redeemed for %s off a booking", discount))
+	if err != nil {
+		return nil, money.Money{}, err
+	}
+
+	if entry == nil {
+		return nil, money.Money{}, fmt.Errorf("%w: not enough loyalty points", apperror.ErrConflict)
+	}
+
+	return entry, discount, nil
+}
+
+func (uc *LoyaltyUsecase) AttachRedemption(entry *data.LoyaltyEntry, bookingID int) {
+	if err := uc.loyaltyRepo.AttachBooking(entry.ID, bookingID); err != nil {
+		log.Printf("Failed to link loyalty redemption %d to booking %d: %v", entry.ID, bookingID, err)
+	}
+}
+
+func (uc *LoyaltyUsecase) ReleaseRedemption(entry *data.LoyaltyEntry) {
+	if err := uc.loyaltyRepo.Release(entry.ID); err != nil {
+		log.Printf("Failed to release loyalty redemption %d: %v", entry.ID, err)
+	}
+}
+
+// HandleBookingCheckedOut credits the points for a finished stay, on what
+// the guest paid after any early departure.
+func (uc *LoyaltyUsecase) HandleBookingCheckedOut(ctx context.Context, event events.Event) error {
+	booking, ok := event.Payload.(data.Booking)
+	if !ok {
+		return fmt.Errorf("unexpected payload %T for %s", event.Payload, event.Name)
+	}
+
+	_, tier, err := uc.tier(booking.UserID)
+	if err != nil {
+		return err
+	}
+
+	paid, _, err := uc.currencyUsecase.Convert(booking.TotalPrice, uc.currency)
+	if err != nil {
+		return err
+	}
+
+	points := int(paid.Amount / unitSize(uc.currency))
+	points += points * loyaltyTiers[tier].EarnBonusPercent / 100
+	if points <= 0 {
+		return nil
+	}
+
+	description := fmt.Sprintf("Stay %s, %d night(s)", booking.ConfirmationCode, nightsBetween(booking.FromDate, booking.ToDate))
+	_, err = uc.loyaltyRepo.Earn(booking.UserID, booking.ID, points, description)
+	return err
+}
+
+// HandleBookingCancelled gives back the points redeemed for a released
+// booking and takes back any it earned.
+func (uc *LoyaltyUsecase) HandleBookingCancelled(ctx context.Context, event events.Event) error {
+	booking, ok := event.Payload.(data.Booking)
+	if !ok {
+		return fmt.Errorf("unexpected payload %T for %s", event.Payload, event.Name)
+	}
+
+	_, err := uc.loyaltyRepo.ReverseBooking(booking.ID, fmt.Sprintf("Booking %s %s", booking.ConfirmationCode, booking.Status))
+	return err
+}
+
+// ExpirePoints clears the balances of members inactive for the expiry
+// period.
+func (uc *LoyaltyUsecase) ExpirePoints(ctx context.Context) error {
+	cutoff := time.Now().AddDate(0, -uc.expiryMonths, 0)
+	expired, err := uc.loyaltyRepo.ExpireInactive(cutoff, fmt.Sprintf("Expired after %d months without activity", uc.expiryMonths))
+	if err != nil {
+		return err
+	}
+	if expired > 0 {
+		log.Printf("Expired loyalty points of %d inactive member(s)", expired)
+	}
+	return nil
+}
+
+// tier returns the member's qualifying points and the index of their tier.
+func (uc *LoyaltyUsecase) tier(userID int) (int, int, error) {
+	qualifying, err := uc.loyaltyRepo.QualifyingPoints(userID, time.Now().AddDate(0, -tierQualifyingPeriod, 0))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	tier := 0
+	for i, t := range loyaltyTiers {
+		if qualifying >= t.QualifyingPoints {
+			tier = i
+		}
+	}
+	return qualifying, tier, nil
+}
+
+// unitSize is the number of minor units in one unit of the currency.
+func unitSize(currency string) int64 {
+	size := int64(1)
+	for i := 0; i < money.Exponent(currency); i++ {
+		size *= 10
+	}
+	return size
+}
@@ -0,0 +1,355 @@
+package usecases
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"hotel-booking-service/internal/data"
+	"hotel-booking-service/internal/events"
+	"hotel-booking-service/internal/notifications"
+	"hotel-booking-service/internal/pkg/apperror"
+	"hotel-booking-service/internal/pkg/filestore"
+	"hotel-booking-service/internal/repositories"
+)
+
+const (
+	maxMessageBodyLen     = 4000
+	maxMessageAttachments = 5
+)
+
+// Attachments are limited to what guests and staff need to send: photos,
+// PDFs and plain text. The type is taken from the content, not the name.
+var allowedAttachmentTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+	"text/plain":      true,
+}
+
+// MessageUsecase runs the message thread on each booking between the guest
+// and the hotel. Guests see only their own bookings' threads; staff see
+// those of their hotel's bookings.
+// Reading a thread marks the other side's messages as read.
+type MessageUsecase struct {
+	messageRepo   *repositories.MessageRepository
+	bookingRepo   *repositories.BookingRepository
+	userRepo      *repositories.UserRepository
+	files         *filestore.Local
+	maxAttachment int64
+	notifier      notifications.Notifier
+	staffEmail    string
+	bus           *events.Bus
+}
+
+func NewMessageUsecase(
+	messageRepo *repositories.MessageRepository,
+	bookingRepo *repositories.BookingRepository,
+	userRepo *repositories.UserRepository,
+	files *filestore.Local,
+	maxAttachment int64,
+	notifier notifications.Notifier,
+	staffEmail string,
+	bus *events.Bus,
+) *MessageUsecase {
+	return &MessageUsecase{
+		messageRepo:   messageRepo,
+		bookingRepo:   bookingRepo,
+		userRepo:      userRepo,
+		files:         files,
+		maxAttachment: maxAttachment,
+		notifier:      notifier,
+		staffEmail:    staffEmail,
+		bus:           bus,
+	}
+}
+
+// MaxUploadBytes is the largest a posted message can be with all its
+// attachments, allowing for the form around them.
+func (uc *MessageUsecase) MaxUploadBytes() int64 {
+	return maxMessageAttachments*uc.maxAttachment + 1<<20
+}
+
+// GuestThread returns the thread on one of the user's bookings.
+func (uc *MessageUsecase) GuestThread(userID, bookingID int) (*data.MessageThread, error) {
+	if _, err := uc.getOwnBooking(userID, bookingID); err != nil {
+		return nil, err
+	}
+	return uc.thread(bookingID, data.MessageFromHotel)
+}
+
+// HotelThread returns a booking's thread to the hotel's staff.
+func (uc *MessageUsecase) HotelThread(staffID, bookingID int) (*data.MessageThread, error) {
+	if _, err := uc.getHotelBooking(staffID, bookingID); err != nil {
+		return nil, err
+	}
+	return uc.thread(bookingID, data.MessageFromGuest)
+}
+
+func (uc *MessageUsecase) thread(bookingID int, unreadFrom string) (*data.MessageThread, error) {
+	unread, err := uc.messageRepo.MarkRead(bookingID, unreadFrom)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := uc.messageRepo.GetForBooking(bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &data.MessageThread{
+		BookingID: bookingID,
+		Unread:    unread,
+		Messages:  messages,
+	}, nil
+}
+
+// PostAsGuest adds the guest's message to one of their bookings' threads.
+func (uc *MessageUsecase) PostAsGuest(ctx context.Context, userID, bookingID int, body string, uploads []data.MessageUpload) (*data.BookingMessage, error) {
+	if _, err := uc.getOwnBooking(userID, bookingID); err != nil {
+		return nil, err
+	}
+	return uc.post(ctx, userID, bookingID, data.MessageFromGuest, body, uploads)
+}
+
+// PostAsHotel adds a staff member's message to the thread of one of their
+// hotel's bookings.
+func (uc *MessageUsecase) PostAsHotel(ctx context.Context, staffID, bookingID int, body string, uploads []data.MessageUpload) (*data.BookingMessage, error) {
+	if _, err := uc.getHotelBooking(staffID, bookingID); err != nil {
+		return nil, err
+	}
+	return uc.post(ctx, staffID, bookingID, data.MessageFromHotel, body, uploads)
+}
+
+func (uc *MessageUsecase) post(ctx context.Context, senderID, bookingID int, from, body string, uploads []data.MessageUpload) (*data.BookingMessage, error) {
+	body = strings.TrimSpace(body)
+	if body == "" && len(uploads) == 0 {
+		return nil, fmt.Errorf("%w: a message needs a body or an attachment", apperror.ErrInvalidRequest)
+	}
+
+	if utf8.RuneCountInString(body) > maxMessageBodyLen {
+		return nil, fmt.Errorf("%w: body must be at most %d characters", apperror.ErrInvalidRequest, maxMessageBodyLen)
+	}
+
+	if len(uploads) > maxMessageAttachments {
+		return nil, fmt.Errorf("%w: at most %d attachments can be sent with a message", apperror.ErrInvalidRequest, maxMessageAttachments)
+	}
+
+	attachments := make([]data.MessageAttachment, 0, len(uploads))
+	discard := func() {
+		for _, attachment := range attachments {
+			if err := uc.files.Delete(attachment.StorageKey); err != nil {
+				log.Printf("Failed to delete attachment file %s: %v", attachment.StorageKey, err)
+			}
+		}
+	}
+
+	for _, upload := range uploads {
+		attachment, err := uc.saveAttachment(upload)
+		if err != nil {
+			discard()
+			return nil, err
+		}
+		attachments = append(attachments, *attachment)
+	}
+
+	message, err := uc.messageRepo.Create(&data.BookingMessage{
+		BookingID: bookingID,
+		SenderID:  senderID,
+		From:      from,
+		Body:      body,
+	}, attachments)
+	if err != nil {
+		discard()
+		return nil, err
+	}
+
+	uc.bus.Publish(ctx, events.BookingMessagePosted, *message)
+	return message, nil
+}
+
+// saveAttachment puts an upload in the file store once its content is known
+// to be of an allowed type.
+func (uc *MessageUsecase) saveAttachment(upload data.MessageUpload) (*data.MessageAttachment, error) {
+	filename := strings.TrimSpace(filepath.Base(strings.ReplaceAll(upload.Filename, `\`, "/")))
+	if filename == "" || filename == "." || filename == "/" {
+		filename = "attachment"
+	}
+	if utf8.RuneCountInString(filename) > 255 {
+		return nil, fmt.Errorf("%w: attachment names must be at most 255 characters", apperror.ErrInvalidRequest)
+	}
+
+	content := bufio.NewReaderSize(upload.Content, 512)
+	head, err := content.Peek(512)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if len(head) == 0 {
+		return nil, fmt.Errorf("%w: attachment %q is empty", apperror.ErrInvalidRequest, filename)
+	}
+
+	contentType := http.DetectContentType(head)
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if !allowedAttachmentTypes[mediaType] {
+		return nil, fmt.Errorf("%w: attachment %q must be an image, a PDF or plain text", apperror.ErrInvalidRequest, filename)
+	}
+
+	key, size, err := uc.files.Save(content, uc.maxAttachment)
+	if errors.Is(err, filestore.ErrTooLarge) {
+		return nil, fmt.Errorf("%w: attachment %q is larger than %d MB", apperror.ErrInvalidRequest, filename, uc.maxAttachment>>20)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &data.MessageAttachment{
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        size,
+		StorageKey:  key,
+	}, nil
+}
+
+// GuestAttachment opens a file sent on one of the user's bookings. The
+// caller closes it.
+func (uc *MessageUsecase) GuestAttachment(userID, bookingID, attachmentID int) (*data.MessageAttachment, *os.File, error) {
+	if _, err := uc.getOwnBooking(userID, bookingID); err != nil {
+		return nil, nil, err
+	}
+	return uc.attachment(bookingID, attachmentID)
+}
+
+// HotelAttachment opens a file sent on a booking for the hotel's staff. The
+// caller closes it.
+func (uc *MessageUsecase) HotelAttachment(staffID, bookingID, attachmentID int) (*data.MessageAttachment, *os.File, error) {
+	if _, err := uc.getHotelBooking(staffID, bookingID); err != nil {
+		return nil, nil, err
+	}
+	return uc.attachment(bookingID, attachmentID)
+}
+
+func (uc *MessageUsecase) attachment(bookingID, attachmentID int) (*data.MessageAttachment, *os.File, error) {
+	attachment, err := uc.messageRepo.GetAttachment(bookingID, attachmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if attachment == nil {
+		return nil, nil, fmt.Errorf("%w: attachment not found", apperror.ErrNotFound)
+	}
+
+	file, err := uc.files.Open(attachment.StorageKey)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, fmt.Errorf("%w: attachment file is missing", apperror.ErrNotFound)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return attachment, file, nil
+}
+
+// Inbox lists a hotel's bookings with guest messages waiting for a reply.
+func (uc *MessageUsecase) Inbox(staffID, hotelID int) ([]data.UnreadThread, error) {
+	if err := checkWorksAt(uc.userRepo, staffID, hotelID); err != nil {
+		return nil, err
+	}
+	return uc.messageRepo.UnreadForHotel(hotelID)
+}
+
+// HandleMessagePosted tells the other side of the thread about a new
+// message: the guest by email, or the hotel's staff address if one is set.
+func (uc *MessageUsecase) HandleMessagePosted(ctx context.Context, event events.Event) error {
+	message, ok := event.Payload.(data.BookingMessage)
+	if !ok {
+		return fmt.Errorf("unexpected payload %T for %s", event.Payload, event.Name)
+	}
+
+	booking, err := uc.bookingRepo.GetBooking(message.BookingID)
+	if err != nil {
+		return err
+	}
+	if booking == nil {
+		return nil
+	}
+
+	var to, subject string
+	if message.From == data.MessageFromHotel {
+		user, err := uc.userRepo.GetByID(booking.UserID)
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			return nil
+		}
+		to = user.Email
+		subject = fmt.Sprintf("New message about your booking %s", booking.ConfirmationCode)
+	} else {
+		if uc.staffEmail == "" {
+			return nil
+		}
+		to = uc.staffEmail
+		subject = fmt.Sprintf("Guest message on booking %s", booking.ConfirmationCode)
+	}
+
+	body := message.Body
+	if n := len(message.Attachments); n > 0 {
+		body += fmt.Sprintf("\n\n(%d attachment(s))", n)
+	}
+
+	return uc.notifier.Notify(ctx, notifications.Message{
+		To:      to,
+		Subject: subject,
+		Body:    fmt.Sprintf("%s wrote:\n\n%s", message.SenderName, strings.TrimSpace(body)),
+	})
+}
+
+func (uc *MessageUsecase) getBooking(bookingID int) (*data.Booking, error) {
+	booking, err := uc.bookingRepo.GetBooking(bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if booking == nil {
+		return nil, errors.New("booking not found")
+	}
+
+	return booking, nil
+}
+
+func (uc *MessageUsecase) getHotelBooking(staffID, bookingID int) (*data.Booking, error) {
+	booking, err := uc.getBooking(bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkWorksAt(uc.userRepo, staffID, booking.HotelID); err != nil {
+		return nil, err
+	}
+
+	return booking, nil
+}
+
+func (uc *MessageUsecase) getOwnBooking(userID, bookingID int) (*data.Booking, error) {
+	booking, err := uc.getBooking(bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if booking.UserID != userID {
+		return nil, errors.New("booking does not belong to this user")
+	}
+
+	return booking, nil
+}
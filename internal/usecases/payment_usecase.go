@@ -0,0 +1,368 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"hotel-booking-service/internal/data"
+	"hotel-booking-service/internal/events"
+	"hotel-booking-service/internal/payments"
+	"hotel-booking-service/internal/pkg/apperror"
+	"hotel-booking-service/internal/pkg/money"
+	"hotel-booking-service/internal/repositories"
+)
+
+// webhookTolerance bounds how old a signed webhook may be before it is
+// rejected as a possible replay.
+const webhookTolerance = 5 * time.Minute
+
+type PaymentUsecase struct {
+	paymentRepo   *repositories.PaymentRepository
+	bookingRepo   *repositories.BookingRepository
+	provider      payments.Provider
+	webhookSecret string
+	bus           *events.Bus
+}
+
+func NewPaymentUsecase(
+	paymentRepo *repositories.PaymentRepository,
+	bookingRepo *repositories.BookingRepository,
+	provider payments.Provider,
+	webhookSecret string,
+	bus *events.Bus,
+) *PaymentUsecase {
+	return &PaymentUsecase{
+		paymentRepo:   paymentRepo,
+		bookingRepo:   bookingRepo,
+		provider:      provider,
+		webhookSecret: webhookSecret,
+		bus:           bus,
+	}
+}
+
+// CreatePayment opens a payment intent for the full booking total. The
+// booking is confirmed when the provider reports the payment authorized.
+func (uc *PaymentUsecase) CreatePayment(ctx context.Context, userID, bookingID int) (*data.Payment, error) {
+	booking, err := uc.bookingRepo.GetBooking(bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if booking == nil {
+		return nil, errors.New("booking not found")
+	}
+
+	if booking.UserID != userID {
+		return nil, errors.New("booking does not belong to this user")
+	}
+
+	if booking.Status != data.BookingStatusPending {
+		return nil, fmt.Errorf("%w: booking is %s and cannot be paid", apperror.ErrConflict, booking.Status)
+	}
+
+	open, err := uc.paymentRepo.GetOpenForBooking(bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if open != nil && open.Status != payments.StatusRequiresConfirmation {
+		return nil, fmt.Errorf("%w: booking already has a %s payment", apperror.ErrConflict, open.Status)
+	}
+	if open != nil {
+		return open, nil
+	}
+
+	intent, err := uc.provider.CreateIntent(ctx, booking.TotalPrice, "booking-"+strconv.Itoa(booking.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.paymentRepo.Create(&data.Payment{
+		BookingID:   booking.ID,
+		Provider:    uc.provider.Name(),
+		ProviderRef: intent.ID,
+		Amount:      booking.TotalPrice,
+		Status:      intent.Status,
+		ConfirmURL:  intent.ConfirmURL,
+	})
+}
+
+func (uc *PaymentUsecase) GetBookingPayments(userID, bookingID int) ([]data.Payment, error) {
+	booking, err := uc.bookingRepo.GetBooking(bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if booking == nil {
+		return nil, errors.New("booking not found")
+	}
+
+	if booking.UserID != userID {
+		return nil, errors.New("booking does not belong to this user")
+	}
+
+	return uc.paymentRepo.GetByBookingID(bookingID)
+}
+
+// Capture takes an authorized payment, in full unless amount is given.
+func (uc *PaymentUsecase) Capture(ctx context.Context, paymentID int, amount *money.Money) (*data.Payment, error) {
+	payment, err := uc.getPayment(paymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	captureAmount := payment.Amount
+	if amount != nil {
+		captureAmount = *amount
+	}
+
+	intent, err := uc.provider.Capture(ctx, payment.ProviderRef, captureAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	return payment, uc.applyIntent(ctx, payment, intent)
+}
+
+// Refund returns captured money, everything not yet refunded unless amount
+// is given.
+func (uc *PaymentUsecase) Refund(ctx context.Context, paymentID int, amount *money.Money) (*data.Payment, error) {
+	payment, err := uc.getPayment(paymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	refundAmount, err := payment.Captured.Sub(payment.Refunded)
+	if err != nil {
+		return nil, err
+	}
+	if amount != nil {
+		refundAmount = *amount
+	}
+
+	intent, err := uc.provider.Refund(ctx, payment.ProviderRef, refundAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	return payment, uc.applyIntent(ctx, payment, intent)
+}
+
+// Void releases an authorization that has not been captured.
+func (uc *PaymentUsecase) Void(ctx context.Context, paymentID int) (*data.Payment, error) {
+	payment, err := uc.getPayment(paymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	intent, err := uc.provider.Void(ctx, payment.ProviderRef)
+	if err != nil {
+		return nil, err
+	}
+
+	return payment, uc.applyIntent(ctx, payment, intent)
+}
+
+// SettleCancellation moves money to match a cancellation outcome: keep the
+// penalty, give back the rest. An uncaptured authorization is captured for
+// the penalty only, or voided when there is none. No more than was
+// authorized is captured; anything owed beyond it, e.g. for extras added
+// after payment, is settled at the hotel.
+func (uc *PaymentUsecase) SettleCancellation(ctx context.Context, bookingID int, penalty, refund money.Money) error {
+	payment, err := uc.paymentRepo.GetOpenForBooking(bookingID)
+	if err != nil {
+		return err
+	}
+	if payment == nil {
+		return nil
+	}
+
+	return uc.settle(ctx, payment, penalty, refund)
+}
+
+// settle keeps penalty of what the payment holds and gives back up to
+// refund of the rest.
+func (uc *PaymentUsecase) settle(ctx context.Context, payment *data.Payment, penalty, refund money.Money) error {
+	var intent *payments.Intent
+	var err error
+	switch payment.Status {
+	case payments.StatusRequiresConfirmation:
+		intent, err = uc.provider.Void(ctx, payment.ProviderRef)
+	case payments.StatusAuthorized:
+		if penalty.IsZero() {
+			intent, err = uc.provider.Void(ctx, payment.ProviderRef)
+		} else {
+			intent, err = uc.provider.Capture(ctx, payment.ProviderRef, penalty.Min(payment.Amount))
+		}
+	case payments.StatusCaptured, payments.StatusPartiallyRefunded:
+		refundable, subErr := payment.Captured.Sub(payment.Refunded)
+		if subErr != nil {
+			return subErr
+		}
+		amount := refund.Min(refundable)
+		if amount.Amount <= 0 {
+			return nil
+		}
+		intent, err = uc.provider.Refund(ctx, payment.ProviderRef, amount)
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return uc.applyIntent(ctx, payment, intent)
+}
+
+// SettleStay takes payment for a finished stay: the amount due is kept and
+// anything paid beyond it, e.g. for nights not stayed, is given back.
+func (uc *PaymentUsecase) SettleStay(ctx context.Context, bookingID int, paid, due money.Money) error {
+	excess, err := paid.Sub(due)
+	if err != nil {
+		return err
+	}
+	return uc.SettleCancellation(ctx, bookingID, due, excess)
+}
+
+// HandleWebhook verifies and applies a provider notification. Deliveries are
+// deduplicated by event ID, so the provider may safely retry.
+func (uc *PaymentUsecase) HandleWebhook(payload []byte, signature string) error {
+	ctx := context.Background()
+
+	if err := payments.VerifySignature(uc.webhookSecret, payload, signature, webhookTolerance, time.Now()); err != nil {
+		return fmt.Errorf("%w: %v", apperror.ErrUnauthorized, err)
+	}
+
+	var event payments.Event
+	if err := json.Unmarshal(payload, &event); err != nil || event.ID == "" {
+		return fmt.Errorf("%w: malformed event", apperror.ErrInvalidRequest)
+	}
+
+	isNew, err := uc.paymentRepo.RecordEvent(uc.provider.Name(), event.ID, event.Type, payload)
+	if err != nil {
+		return err
+	}
+	if !isNew {
+		return nil
+	}
+
+	payment, err := uc.paymentRepo.GetByProviderRef(uc.provider.Name(), event.Intent.ID)
+	if err != nil {
+		return err
+	}
+	if payment == nil {
+		log.Printf("Ignoring %s for unknown payment %s", event.Type, event.Intent.ID)
+		return nil
+	}
+
+	return uc.applyIntent(ctx, payment, &event.Intent)
+}
+
+// ExpirePendingBookings releases rooms held by bookings that were never paid.
+func (uc *PaymentUsecase) ExpirePendingBookings(ctx context.Context, ttl time.Duration) error {
+	ids, err := uc.bookingRepo.ExpirePendingBookings(time.Now().Add(-ttl))
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if err := uc.SettleCancellation(ctx, id, money.Money{}, money.Money{}); err != nil {
+			log.Printf("Failed to void payment for expired booking %d: %v", id, err)
+		}
+
+		booking, err := uc.bookingRepo.GetBooking(id)
+		if err != nil || booking == nil {
+			log.Printf("Failed to load expired booking %d: %v", id, err)
+			continue
+		}
+		uc.bus.Publish(ctx, events.BookingCancelled, *booking)
+	}
+	return nil
+}
+
+// laterStatuses lists the states each payment state can still move to, so
+// that late or out-of-order webhooks never move a payment backwards or from
+// one outcome to another: a replayed void cannot undo a capture, nor a late
+// failure an authorization. States may be skipped when events arrive out of
+// order. Failed, voided and refunded payments are final.
+var laterStatuses = map[string][]string{
+	payments.StatusRequiresConfirmation: {
+		payments.StatusAuthorized, payments.StatusFailed, payments.StatusCaptured,
+		payments.StatusVoided, payments.StatusPartiallyRefunded, payments.StatusRefunded,
+	},
+	payments.StatusAuthorized: {
+		payments.StatusCaptured, payments.StatusVoided, payments.StatusPartiallyRefunded, payments.StatusRefunded,
+	},
+	payments.StatusCaptured:          {payments.StatusPartiallyRefunded, payments.StatusRefunded},
+	payments.StatusPartiallyRefunded: {payments.StatusRefunded},
+}
+
+func canMoveTo(from, to string) bool {
+	for _, status := range laterStatuses[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+func (uc *PaymentUsecase) applyIntent(ctx context.Context, payment *data.Payment, intent *payments.Intent) error {
+	if intent.Status == payment.Status {
+		if intent.Refunded.Amount <= payment.Refunded.Amount {
+			return nil
+		}
+	} else if !canMoveTo(payment.Status, intent.Status) {
+		return nil
+	}
+
+	previous := payment.Status
+	payment.Status = intent.Status
+	payment.Captured = money.New(intent.Captured.Amount, payment.Amount.Currency)
+	payment.Refunded = money.New(intent.Refunded.Amount, payment.Amount.Currency)
+	if err := uc.paymentRepo.UpdateState(payment); err != nil {
+		return err
+	}
+
+	paid := payment.Status == payments.StatusAuthorized || payment.Status == payments.StatusCaptured
+	if previous == payments.StatusRequiresConfirmation && paid {
+		return uc.confirmBooking(ctx, payment)
+	}
+	return nil
+}
+
+// confirmBooking confirms the booking once its payment goes through. A
+// booking released while the guest was paying, e.g. because it expired,
+// stays released, and the payment is voided or refunded rather than left
+// holding the guest's money.
+func (uc *PaymentUsecase) confirmBooking(ctx context.Context, payment *data.Payment) error {
+	confirmed, err := uc.bookingRepo.ConfirmBooking(payment.BookingID)
+	if err != nil || confirmed {
+		return err
+	}
+
+	booking, err := uc.bookingRepo.GetBooking(payment.BookingID)
+	if err != nil {
+		return err
+	}
+	if booking != nil && booking.Status != data.BookingStatusCancelled {
+		return nil
+	}
+
+	log.Printf("Releasing payment %d for booking %d, which was cancelled before it was paid", payment.ID, payment.BookingID)
+	return uc.settle(ctx, payment, money.Money{}, payment.Amount)
+}
+
+func (uc *PaymentUsecase) getPayment(id int) (*data.Payment, error) {
+	payment, err := uc.paymentRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if payment == nil {
+		return nil, fmt.Errorf("%w: payment not found", apperror.ErrNotFound)
+	}
+	return payment, nil
+}
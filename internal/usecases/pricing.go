@@ -0,0 +1,26 @@
+package usecases
+
+import (
+	"time"
+
+	"hotel-booking-service/internal/data"
+	"hotel-booking-service/internal/pkg/money"
+)
+
+// nightsBetween counts calendar nights, ignoring the time of day, since
+// bookings are stored as DATE columns.
+func nightsBetween(fromDate, toDate time.Time) int {
+	from := time.Date(fromDate.Year(), fromDate.Month(), fromDate.Day(), 0, 0, 0, 0, time.UTC)
+	to := time.Date(toDate.Year(), toDate.Month(), toDate.Day(), 0, 0, 0, 0, time.UTC)
+	return int(to.Sub(from).Hours() / 24)
+}
+
+// quoteStay prices a stay in a room type as nights x nightly rate. The
+// nightly rate is already in whole minor units, so the total needs no
+// rounding.
+func quoteStay(roomType *data.RoomType, fromDate, toDate time.Time) (nightly, total money.Money, nights int) {
+	nights = nightsBetween(fromDate, toDate)
+	nightly = roomType.Price
+	total = nightly.Times(int64(nights))
+	return nightly, total, nights
+}
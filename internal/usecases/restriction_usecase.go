@@ -0,0 +1,234 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"hotel-booking-service/internal/data"
+	"hotel-booking-service/internal/pkg/apperror"
+	"hotel-booking-service/internal/repositories"
+)
+
+type RestrictionUsecase struct {
+	restrictionRepo *repositories.RestrictionRepository
+	hotelRepo       *repositories.HotelRepository
+	roomTypeRepo    *repositories.RoomTypeRepository
+}
+
+func NewRestrictionUsecase(
+	restrictionRepo *repositories.RestrictionRepository,
+	hotelRepo *repositories.HotelRepository,
+	roomTypeRepo *repositories.RoomTypeRepository,
+) *RestrictionUsecase {
+	return &RestrictionUsecase{
+		restrictionRepo: restrictionRepo,
+		hotelRepo:       hotelRepo,
+		roomTypeRepo:    roomTypeRepo,
+	}
+}
+
+func (uc *RestrictionUsecase) GetHotelRestrictions(hotelID int) ([]data.StayRestriction, error) {
+	return uc.restrictionRepo.GetByHotelID(hotelID)
+}
+
+func (uc *RestrictionUsecase) CreateRestriction(restriction data.StayRestriction) (*data.StayRestriction, error) {
+	if err := validateRestriction(restriction); err != nil {
+		return nil, err
+	}
+
+	hotel, err := uc.hotelRepo.GetByID(restriction.HotelID)
+	if err != nil {
+		return nil, err
+	}
+	if hotel == nil {
+		return nil, errors.New("hotel not found")
+	}
+
+	if restriction.RoomTypeID != nil {
+		roomType, err := uc.roomTypeRepo.GetByID(*restriction.RoomTypeID)
+		if err != nil {
+			return nil, err
+		}
+		if roomType == nil || roomType.HotelID != restriction.HotelID {
+			return nil, fmt.Errorf("%w: room type not found", apperror.ErrNotFound)
+		}
+	}
+
+	return uc.restrictionRepo.Create(&restriction)
+}
+
+func (uc *RestrictionUsecase) UpdateRestriction(restriction data.StayRestriction) (*data.StayRestriction, error) {
+	existing, err := uc.restrictionRepo.GetByID(restriction.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, fmt.Errorf("%w: stay restriction not found", apperror.ErrNotFound)
+	}
+
+	restriction.HotelID = existing.HotelID
+	restriction.RoomTypeID = existing.RoomTypeID
+	if err := validateRestriction(restriction); err != nil {
+		return nil, err
+	}
+
+	updated, err := uc.restrictionRepo.Update(&restriction)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, fmt.Errorf("%w: stay restriction not found", apperror.ErrNotFound)
+	}
+	return updated, nil
+}
+
+func (uc *RestrictionUsecase) DeleteRestriction(id int) error {
+	return uc.restrictionRepo.Delete(id)
+}
+
+// CheckStay lists the rules a stay in a room of the type breaks if booked
+// today.
+func (uc *RestrictionUsecase) CheckStay(roomType *data.RoomType, fromDate, toDate time.Time) ([]data.RestrictionViolation, error) {
+	rules, err := uc.restrictionRepo.GetForStay(roomType.HotelID, fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+	return evaluateRestrictions(rules, roomType.ID, fromDate, toDate, time.Now()), nil
+}
+
+// AnnotateRooms attaches the violated rules to each room, so availability
+// listings show why a free room cannot be booked. The rooms may belong to
+// any number of hotels; their rules are loaded in one query.
+func (uc *RestrictionUsecase) AnnotateRooms(rooms []data.Room, fromDate, toDate time.Time) error {
+	if len(rooms) == 0 {
+		return nil
+	}
+
+	seen := map[int]bool{}
+	var hotelIDs []int
+	for _, room := range rooms {
+		if !seen[room.HotelID] {
+			seen[room.HotelID] = true
+			hotelIDs = append(hotelIDs, room.HotelID)
+		}
+	}
+
+	rules, err := uc.restrictionRepo.GetForStayByHotels(hotelIDs, fromDate, toDate)
+	if err != nil {
+		return err
+	}
+
+	byHotel := map[int][]data.StayRestriction{}
+	for _, rule := range rules {
+		byHotel[rule.HotelID] = append(byHotel[rule.HotelID], rule)
+	}
+
+	today := time.Now()
+	for i := range rooms {
+		rooms[i].Restrictions = evaluateRestrictions(byHotel[rooms[i].HotelID], rooms[i].RoomTypeID, fromDate, toDate, today)
+	}
+	return nil
+}
+
+// restrictionError turns violations into a single booking error.
+func restrictionError(violations []data.RestrictionViolation) error {
+	messages := make([]string, len(violations))
+	for i, violation := range violations {
+		messages[i] = violation.Message
+	}
+	return fmt.Errorf("%w: %s", apperror.ErrInvalidRequest, strings.Join(messages, "; "))
+}
+
+func validateRestriction(restriction data.StayRestriction) error {
+	if restriction.ToDate.Before(restriction.FromDate) {
+		return fmt.Errorf("%w: to_date must not be before from_date", apperror.ErrInvalidRequest)
+	}
+	for _, day := range restriction.DaysOfWeek {
+		if day < 0 || day > 6 {
+			return fmt.Errorf("%w: days_of_week must be between 0 (Sunday) and 6 (Saturday)", apperror.ErrInvalidRequest)
+		}
+	}
+	if restriction.MinStay < 0 || restriction.MaxStay < 0 || restriction.MinLeadDays < 0 || restriction.MaxLeadDays < 0 {
+		return fmt.Errorf("%w: stay and lead-time limits cannot be negative", apperror.ErrInvalidRequest)
+	}
+	if restriction.MaxStay > 0 && restriction.MaxStay < restriction.MinStay {
+		return fmt.Errorf("%w: max_stay must not be below min_stay", apperror.ErrInvalidRequest)
+	}
+	if restriction.MaxLeadDays > 0 && restriction.MaxLeadDays < restriction.MinLeadDays {
+		return fmt.Errorf("%w: max_lead_days must not be below min_lead_days", apperror.ErrInvalidRequest)
+	}
+	if restriction.MinStay == 0 && restriction.MaxStay == 0 &&
+		!restriction.ClosedToArrival && !restriction.ClosedToDeparture &&
+		restriction.MinLeadDays == 0 && restriction.MaxLeadDays == 0 {
+		return fmt.Errorf("%w: restriction sets no rule", apperror.ErrInvalidRequest)
+	}
+	return nil
+}
+
+// evaluateRestrictions checks a stay in a room of roomTypeID against the
+// hotel's rules.
+// Every matching rule applies, so the strictest one decides.
+func evaluateRestrictions(rules []data.StayRestriction, roomTypeID int, fromDate, toDate, today time.Time) []data.RestrictionViolation {
+	arrival := dateOnly(fromDate)
+	departure := dateOnly(toDate)
+	nights := nightsBetween(arrival, departure)
+	lead := nightsBetween(today, arrival)
+
+	var violations []data.RestrictionViolation
+	add := func(rule data.StayRestriction, kind, format string, args ...interface{}) {
+		violations = append(violations, data.RestrictionViolation{
+			RestrictionID: rule.ID,
+			Rule:          kind,
+			Message:       fmt.Sprintf(format, args...),
+		})
+	}
+
+	for _, rule := range rules {
+		if rule.RoomTypeID != nil && *rule.RoomTypeID != roomTypeID {
+			continue
+		}
+
+		if restrictionCovers(rule, arrival) {
+			day := arrival.Format("2006-01-02")
+			if rule.ClosedToArrival {
+				add(rule, data.RestrictionClosedToArrival, "arrivals are not accepted on %s", day)
+			}
+			if rule.MinStay > 0 && nights < rule.MinStay {
+				add(rule, data.RestrictionMinStay, "stays arriving on %s must be at least %d nights", day, rule.MinStay)
+			}
+			if rule.MaxStay > 0 && nights > rule.MaxStay {
+				add(rule, data.RestrictionMaxStay, "stays arriving on %s can be at most %d nights", day, rule.MaxStay)
+			}
+			if rule.MinLeadDays > 0 && lead < rule.MinLeadDays {
+				add(rule, data.RestrictionMinLead, "stays arriving on %s must be booked at least %d days ahead", day, rule.MinLeadDays)
+			}
+			if rule.MaxLeadDays > 0 && lead > rule.MaxLeadDays {
+				add(rule, data.RestrictionMaxLead, "stays arriving on %s open for booking %d days ahead", day, rule.MaxLeadDays)
+			}
+		}
+
+		if rule.ClosedToDeparture && restrictionCovers(rule, departure) {
+			add(rule, data.RestrictionClosedToDeparture, "departures are not accepted on %s", departure.Format("2006-01-02"))
+		}
+	}
+
+	return violations
+}
+
+// restrictionCovers reports whether the rule is in force on the given day.
+func restrictionCovers(rule data.StayRestriction, day time.Time) bool {
+	if day.Before(dateOnly(rule.FromDate)) || day.After(dateOnly(rule.ToDate)) {
+		return false
+	}
+	if len(rule.DaysOfWeek) == 0 {
+		return true
+	}
+	for _, weekday := range rule.DaysOfWeek {
+		if time.Weekday(weekday) == day.Weekday() {
+			return true
+		}
+	}
+	return false
+}
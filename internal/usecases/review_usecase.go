@@ -0,0 +1,207 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"hotel-booking-service/internal/data"
+	"hotel-booking-service/internal/pkg/apperror"
+	"hotel-booking-service/internal/repositories"
+)
+
+const (
+	maxReviewCommentLen = 2000
+	maxReviewReplyLen   = 2000
+)
+
+// ReviewUsecase lets guests review stays they checked out of. Only
+// published reviews count towards a hotel's rating.
+type ReviewUsecase struct {
+	reviewRepo   *repositories.ReviewRepository
+	bookingRepo  *repositories.BookingRepository
+	roomTypeRepo *repositories.RoomTypeRepository
+	hotelRepo    *repositories.HotelRepository
+	userRepo     *repositories.UserRepository
+}
+
+func NewReviewUsecase(
+	reviewRepo *repositories.ReviewRepository,
+	bookingRepo *repositories.BookingRepository,
+	roomTypeRepo *repositories.RoomTypeRepository,
+	hotelRepo *repositories.HotelRepository,
+	userRepo *repositories.UserRepository,
+) *ReviewUsecase {
+	return &ReviewUsecase{
+		reviewRepo:   reviewRepo,
+		bookingRepo:  bookingRepo,
+		roomTypeRepo: roomTypeRepo,
+		hotelRepo:    hotelRepo,
+		userRepo:     userRepo,
+	}
+}
+
+// CreateReview records the guest's review of their booking. A booking can be
+// reviewed once, after check-out.
+func (uc *ReviewUsecase) CreateReview(userID, bookingID int, req data.CreateReviewRequest) (*data.Review, error) {
+	scores := []struct {
+		name  string
+		value int
+	}{
+		{"cleanliness", req.Cleanliness},
+		{"location", req.Location},
+		{"service", req.Service},
+	}
+	for _, score := range scores {
+		if score.value < 1 || score.value > 5 {
+			return nil, fmt.Errorf("%w: %s must be between 1 and 5", apperror.ErrInvalidRequest, score.name)
+		}
+	}
+
+	comment := strings.TrimSpace(req.Comment)
+	if utf8.RuneCountInString(comment) > maxReviewCommentLen {
+		return nil, fmt.Errorf("%w: comment must be at most %d characters", apperror.ErrInvalidRequest, maxReviewCommentLen)
+	}
+
+	booking, err := uc.bookingRepo.GetBooking(bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if booking == nil {
+		return nil, errors.New("booking not found")
+	}
+
+	if booking.UserID != userID {
+		return nil, errors.New("booking does not belong to this user")
+	}
+
+	if booking.Status != data.BookingStatusCheckedOut {
+		return nil, fmt.Errorf("%w: only stays that have checked out can be reviewed", apperror.ErrConflict)
+	}
+
+	roomType, err := uc.roomTypeRepo.GetByID(booking.RoomTypeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if roomType == nil {
+		return nil, fmt.Errorf("%w: room type not found", apperror.ErrNotFound)
+	}
+
+	review, err := uc.reviewRepo.Create(&data.Review{
+		BookingID:   booking.ID,
+		HotelID:     roomType.HotelID,
+		UserID:      userID,
+		Cleanliness: req.Cleanliness,
+		Location:    req.Location,
+		Service:     req.Service,
+		Comment:     comment,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if review == nil {
+		return nil, fmt.Errorf("%w: this booking has already been reviewed", apperror.ErrConflict)
+	}
+
+	return review, nil
+}
+
+// GetHotelReviews returns a hotel's published reviews, newest first.
+func (uc *ReviewUsecase) GetHotelReviews(hotelID int) ([]data.Review, error) {
+	hotel, err := uc.hotelRepo.GetByID(hotelID)
+	if err != nil {
+		return nil, err
+	}
+
+	if hotel == nil {
+		return nil, errors.New("hotel not found")
+	}
+
+	return uc.reviewRepo.GetForHotel(hotelID)
+}
+
+// ListReviews returns reviews in the given status, or published ones when
+// none is given, for moderation.
+func (uc *ReviewUsecase) ListReviews(status string) ([]data.Review, error) {
+	if status == "" {
+		status = data.ReviewPublished
+	}
+
+	if status != data.ReviewPublished && status != data.ReviewHidden {
+		return nil, fmt.Errorf("%w: status must be %q or %q", apperror.ErrInvalidRequest, data.ReviewPublished, data.ReviewHidden)
+	}
+
+	return uc.reviewRepo.GetByStatus(status)
+}
+
+// Reply posts the hotel's public answer to a review, replacing any earlier
+// reply. Only the hotel's staff can answer.
+func (uc *ReviewUsecase) Reply(staffID, reviewID int, reply string) (*data.Review, error) {
+	reply = strings.TrimSpace(reply)
+	if reply == "" {
+		return nil, fmt.Errorf("%w: reply is required", apperror.ErrInvalidRequest)
+	}
+
+	if utf8.RuneCountInString(reply) > maxReviewReplyLen {
+		return nil, fmt.Errorf("%w: reply must be at most %d characters", apperror.ErrInvalidRequest, maxReviewReplyLen)
+	}
+
+	review, err := uc.getReview(reviewID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkWorksAt(uc.userRepo, staffID, review.HotelID); err != nil {
+		return nil, err
+	}
+
+	if err := uc.reviewRepo.SetReply(reviewID, staffID, reply); err != nil {
+		return nil, err
+	}
+
+	return uc.reviewRepo.GetByID(reviewID)
+}
+
+// Moderate hides a review from the hotel's page and rating, or publishes it
+// again.
+func (uc *ReviewUsecase) Moderate(adminID, reviewID int, req data.ModerateReviewRequest) (*data.Review, error) {
+	if req.Status != data.ReviewPublished && req.Status != data.ReviewHidden {
+		return nil, fmt.Errorf("%w: status must be %q or %q", apperror.ErrInvalidRequest, data.ReviewPublished, data.ReviewHidden)
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if req.Status == data.ReviewHidden && reason == "" {
+		return nil, fmt.Errorf("%w: a reason is required to hide a review", apperror.ErrInvalidRequest)
+	}
+
+	if utf8.RuneCountInString(reason) > 255 {
+		return nil, fmt.Errorf("%w: reason must be at most 255 characters", apperror.ErrInvalidRequest)
+	}
+
+	if _, err := uc.getReview(reviewID); err != nil {
+		return nil, err
+	}
+
+	if err := uc.reviewRepo.Moderate(reviewID, adminID, req.Status, reason); err != nil {
+		return nil, err
+	}
+
+	return uc.reviewRepo.GetByID(reviewID)
+}
+
+func (uc *ReviewUsecase) getReview(id int) (*data.Review, error) {
+	review, err := uc.reviewRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if review == nil {
+		return nil, fmt.Errorf("%w: review not found", apperror.ErrNotFound)
+	}
+
+	return review, nil
+}
@@ -0,0 +1,39 @@
+package usecases
+
+import (
+	"fmt"
+
+	"hotel-booking-service/internal/data"
+	"hotel-booking-service/internal/pkg/apperror"
+	"hotel-booking-service/internal/repositories"
+)
+
+// worksAt reports whether the user may act for the hotel: admins for every
+// hotel, staff only for the one their account is tied to.
+func worksAt(user *data.User, hotelID int) bool {
+	if user == nil {
+		return false
+	}
+
+	switch user.Role {
+	case "admin":
+		return true
+	case "staff":
+		return user.HotelID != nil && *user.HotelID == hotelID
+	}
+	return false
+}
+
+// checkWorksAt looks the staff member up and refuses them unless they work
+// at the hotel.
+func checkWorksAt(userRepo *repositories.UserRepository, staffID, hotelID int) error {
+	user, err := userRepo.GetByID(staffID)
+	if err != nil {
+		return err
+	}
+
+	if !worksAt(user, hotelID) {
+		return fmt.Errorf("%w: you do not work at this hotel", apperror.ErrForbidden)
+	}
+	return nil
+}
@@ -29,11 +29,33 @@ func (uc *UserUsecase) GetAllUsers() ([]data.User, error) {
 	return uc.userRepo.GetAllUsers()
 }
 
+// UpdateUser saves the user if it is still at user.Version.
 func (uc *UserUsecase) UpdateUser(user *data.User) error {
-	return uc.userRepo.Update(user)
+	updated, err := uc.userRepo.Update(user)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return uc.missingOrStale(user.ID)
+	}
+	return nil
 }
 
 // Удаление пользователя
-func (uc *UserUsecase) DeleteUser(id int) error {
-	return uc.userRepo.Delete(id)
+func (uc *UserUsecase) DeleteUser(id, version int) error {
+	deleted, err := uc.userRepo.Delete(id, version)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return uc.missingOrStale(id)
+	}
+	return nil
+}
+
+func (uc *UserUsecase) missingOrStale(id int) error {
+	if _, err := uc.GetUserByID(id); err != nil {
+		return err
+	}
+	return errStale("user")
 }
\ No newline at end of file
@@ -0,0 +1,13 @@
+package usecases
+
+import (
+	"fmt"
+
+	"hotel-booking-service/internal/pkg/apperror"
+)
+
+// errStale reports a write refused because the resource is no longer at the
+// version the client read.
+func errStale(resource string) error {
+	return fmt.Errorf("%w: %s was changed by someone else; fetch it again and retry", apperror.ErrPreconditionFailed, resource)
+}
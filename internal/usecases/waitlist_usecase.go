@@ -0,0 +1,289 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"hotel-booking-service/internal/data"
+	"hotel-booking-service/internal/events"
+	"hotel-booking-service/internal/notifications"
+	"hotel-booking-service/internal/pkg/apperror"
+	"hotel-booking-service/internal/repositories"
+)
+
+type WaitlistUsecase struct {
+	waitlistRepo   *repositories.WaitlistRepository
+	roomRepo       *repositories.RoomRepository
+	roomTypeRepo   *repositories.RoomTypeRepository
+	hotelRepo      *repositories.HotelRepository
+	userRepo       *repositories.UserRepository
+	bookingUsecase *BookingUsecase
+	notifier       notifications.Notifier
+	offerTTL       time.Duration
+}
+
+func NewWaitlistUsecase(
+	waitlistRepo *repositories.WaitlistRepository,
+	roomRepo *repositories.RoomRepository,
+	roomTypeRepo *repositories.RoomTypeRepository,
+	hotelRepo *repositories.HotelRepository,
+	userRepo *repositories.UserRepository,
+	bookingUsecase *BookingUsecase,
+	notifier notifications.Notifier,
+	offerTTL time.Duration,
+) *WaitlistUsecase {
+	return &WaitlistUsecase{
+		waitlistRepo:   waitlistRepo,
+		roomRepo:       roomRepo,
+		roomTypeRepo:   roomTypeRepo,
+		hotelRepo:      hotelRepo,
+		userRepo:       userRepo,
+		bookingUsecase: bookingUsecase,
+		notifier:       notifier,
+		offerTTL:       offerTTL,
+	}
+}
+
+func (uc *WaitlistUsecase) Join(userID int, req data.CreateWaitlistRequest) (*data.WaitlistEntry, error) {
+	if nightsBetween(req.FromDate, req.ToDate) < 1 {
+		return nil, fmt.Errorf("%w: stay must be at least one night", apperror.ErrInvalidRequest)
+	}
+	if req.FromDate.Before(time.Now()) {
+		return nil, fmt.Errorf("%w: from date must be in the future", apperror.ErrInvalidRequest)
+	}
+	if req.Guests < 1 {
+		return nil, fmt.Errorf("%w: guests must be at least 1", apperror.ErrInvalidRequest)
+	}
+
+	hotel, err := uc.hotelRepo.GetByID(req.HotelID)
+	if err != nil {
+		return nil, err
+	}
+	if hotel == nil {
+		return nil, errors.New("hotel not found")
+	}
+
+	roomType, err := uc.roomTypeRepo.GetByID(req.RoomTypeID)
+	if err != nil {
+		return nil, err
+	}
+	if roomType == nil || roomType.HotelID != req.HotelID {
+		return nil, fmt.Errorf("%w: room type not found", apperror.ErrNotFound)
+	}
+	if roomType.Capacity < req.Guests {
+		return nil, fmt.Errorf("%w: %s sleeps at most %d guests", apperror.ErrInvalidRequest, roomType.Name, roomType.Capacity)
+	}
+
+	return uc.waitlistRepo.Create(&data.WaitlistEntry{
+		UserID:     userID,
+		HotelID:    req.HotelID,
+		RoomTypeID: &roomType.ID,
+		FromDate:   req.FromDate,
+		ToDate:     req.ToDate,
+		Guests:     req.Guests,
+	})
+}
+
+func (uc *WaitlistUsecase) GetUserEntries(userID int) ([]data.WaitlistEntry, error) {
+	return uc.waitlistRepo.GetByUserID(userID)
+}
+
+// Leave removes the guest from the waitlist. A held offer is passed on to
+// the next guest in line.
+func (uc *WaitlistUsecase) Leave(ctx context.Context, userID, entryID int) error {
+	entry, err := uc.getOwnEntry(userID, entryID)
+	if err != nil {
+		return err
+	}
+
+	cancelled, err := uc.waitlistRepo.Cancel(entryID, userID)
+	if err != nil {
+		return err
+	}
+	if !cancelled {
+		return fmt.Errorf("%w: waitlist entry is already %s", apperror.ErrConflict, entry.Status)
+	}
+
+	if entry.Status == data.WaitlistOffered && entry.OfferedRoomID != nil {
+		return uc.offerRoom(ctx, *entry.OfferedRoomID, entry.FromDate, entry.ToDate)
+	}
+	return nil
+}
+
+// Claim turns a live offer into a booking. If the booking cannot be made the
+// offer is put back, so the guest can retry until it expires.
+func (uc *WaitlistUsecase) Claim(userID, entryID int, currency string) (*data.Booking, error) {
+	if _, err := uc.getOwnEntry(userID, entryID); err != nil {
+		return nil, err
+	}
+
+	entry, err := uc.waitlistRepo.Claim(entryID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("%w: there is no open offer on this waitlist entry", apperror.ErrConflict)
+	}
+
+	booking, err := uc.bookingUsecase.CreateBooking(userID, 0, *entry.OfferedRoomID, entry.FromDate, entry.ToDate, currency, 0, nil)
+	if err != nil {
+		if restoreErr := uc.waitlistRepo.RestoreOffer(entry.ID); restoreErr != nil {
+			log.Printf("Failed to restore waitlist offer %d: %v", entry.ID, restoreErr)
+		}
+		return nil, err
+	}
+
+	if err := uc.waitlistRepo.SetBooking(entry.ID, booking.ID); err != nil {
+		log.Printf("Failed to link waitlist entry %d to booking %d: %v", entry.ID, booking.ID, err)
+	}
+	return booking, nil
+}
+
+// HandleBookingCancelled offers the unit of the room type the booking freed
+// to waitlisted guests.
+func (uc *WaitlistUsecase) HandleBookingCancelled(ctx context.Context, event events.Event) error {
+	booking, ok := event.Payload.(data.Booking)
+	if !ok {
+		return fmt.Errorf("unexpected payload %T for %s", event.Payload, event.Name)
+	}
+	return uc.offerUnit(ctx, booking.RoomTypeID, booking.FromDate, booking.ToDate)
+}
+
+// ExpireOffers closes lapsed offers and passes each unit to the next guest.
+func (uc *WaitlistUsecase) ExpireOffers(ctx context.Context) error {
+	expired, err := uc.waitlistRepo.ExpireOffers()
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range expired {
+		if entry.OfferedRoomID == nil {
+			continue
+		}
+		if err := uc.offerRoom(ctx, *entry.OfferedRoomID, entry.FromDate, entry.ToDate); err != nil {
+			log.Printf("Failed to re-offer room %d after waitlist entry %d expired: %v", *entry.OfferedRoomID, entry.ID, err)
+		}
+	}
+	return nil
+}
+
+// offerRoom passes on a room whose offer was dropped as a freed unit of its
+// type.
+func (uc *WaitlistUsecase) offerRoom(ctx context.Context, roomID int, fromDate, toDate time.Time) error {
+	room, err := uc.roomRepo.GetByID(roomID)
+	if err != nil || room == nil {
+		return err
+	}
+	return uc.offerUnit(ctx, room.RoomTypeID, fromDate, toDate)
+}
+
+// offerUnit walks the queue for a unit of the room type freed between
+// fromDate and toDate. Guests are offered, in the order they joined, any
+// room of the type that is free for their whole stay while a unit of the
+// type is left; each offer holds its room, so later guests only get what
+// nobody ahead of them is holding.
+func (uc *WaitlistUsecase) offerUnit(ctx context.Context, roomTypeID int, fromDate, toDate time.Time) error {
+	roomType, err := uc.roomTypeRepo.GetByID(roomTypeID)
+	if err != nil || roomType == nil {
+		return err
+	}
+
+	candidates, err := uc.waitlistRepo.FindCandidates(roomType, fromDate, toDate)
+	if err != nil {
+		return err
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	rooms, err := uc.roomRepo.GetRoomsByType(roomType.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range candidates {
+		units, err := uc.roomTypeRepo.AvailableUnits(roomType.ID, entry.FromDate, entry.ToDate)
+		if err != nil {
+			return err
+		}
+		if units < 1 {
+			continue
+		}
+
+		room, err := uc.freeRoom(rooms, entry.FromDate, entry.ToDate)
+		if err != nil {
+			return err
+		}
+		if room == nil {
+			continue
+		}
+
+		expiresAt := time.Now().Add(uc.offerTTL)
+		offered, err := uc.waitlistRepo.Offer(entry.ID, room.ID, expiresAt)
+		if err != nil {
+			return err
+		}
+		if !offered {
+			continue
+		}
+
+		uc.notifyOffer(ctx, entry, room, expiresAt)
+	}
+	return nil
+}
+
+// freeRoom returns the first of rooms that is free for the stay, or nil.
+func (uc *WaitlistUsecase) freeRoom(rooms []data.Room, fromDate, toDate time.Time) (*data.Room, error) {
+	for i := range rooms {
+		available, err := uc.roomRepo.CheckRoomAvailability(rooms[i].ID, fromDate, toDate)
+		if err != nil {
+			return nil, err
+		}
+		if available {
+			return &rooms[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (uc *WaitlistUsecase) notifyOffer(ctx context.Context, entry data.WaitlistEntry, room *data.Room, expiresAt time.Time) {
+	user, err := uc.userRepo.GetByID(entry.UserID)
+	if err != nil || user == nil {
+		log.Printf("Failed to look up user %d for waitlist offer %d: %v", entry.UserID, entry.ID, err)
+		return
+	}
+
+	msg := notifications.Message{
+		To:      user.Email,
+		Subject: "A room is available for your dates",
+		Body: fmt.Sprintf(
+			"Room %s is now free from %s to %s.\n\n"+
+				"It is held for you until %s. Claim it with POST /api/waitlist/%d/claim.",
+			room.Number,
+			entry.FromDate.Format("2006-01-02"),
+			entry.ToDate.Format("2006-01-02"),
+			expiresAt.UTC().Format("2006-01-02 15:04 MST"),
+			entry.ID,
+		),
+	}
+	if err := uc.notifier.Notify(ctx, msg); err != nil {
+		log.Printf("Failed to notify user %d about waitlist offer %d: %v", entry.UserID, entry.ID, err)
+	}
+}
+
+func (uc *WaitlistUsecase) getOwnEntry(userID, entryID int) (*data.WaitlistEntry, error) {
+	entry, err := uc.waitlistRepo.GetByID(entryID)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("%w: waitlist entry not found", apperror.ErrNotFound)
+	}
+	if entry.UserID != userID {
+		return nil, fmt.Errorf("%w: waitlist entry does not belong to this user", apperror.ErrForbidden)
+	}
+	return entry, nil
+}
@@ -0,0 +1,9 @@
+ALTER TABLE bookings DROP COLUMN currency;
+ALTER TABLE bookings DROP COLUMN total_amount;
+ALTER TABLE bookings DROP COLUMN nightly_amount;
+
+ALTER TABLE rooms ADD COLUMN price DECIMAL(10, 2);
+UPDATE rooms SET price = price_amount / 100.0;
+ALTER TABLE rooms ALTER COLUMN price SET NOT NULL;
+ALTER TABLE rooms DROP COLUMN price_currency;
+ALTER TABLE rooms DROP COLUMN price_amount;
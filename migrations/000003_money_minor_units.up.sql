@@ -0,0 +1,16 @@
+ALTER TABLE rooms ADD COLUMN price_amount BIGINT;
+UPDATE rooms SET price_amount = ROUND(price * 100)::BIGINT;
+ALTER TABLE rooms ALTER COLUMN price_amount SET NOT NULL;
+ALTER TABLE rooms DROP COLUMN price;
+ALTER TABLE rooms ADD COLUMN price_currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+ALTER TABLE bookings ADD COLUMN nightly_amount BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE bookings ADD COLUMN total_amount BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE bookings ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+UPDATE bookings b
+SET nightly_amount = r.price_amount,
+    total_amount = r.price_amount * GREATEST(b.to_date - b.from_date, 1),
+    currency = r.price_currency
+FROM rooms r
+WHERE r.id = b.room_id;
@@ -0,0 +1,10 @@
+ALTER TABLE bookings DROP COLUMN display_total_amount;
+ALTER TABLE bookings DROP COLUMN exchange_rate;
+ALTER TABLE bookings DROP COLUMN display_currency;
+
+DROP TABLE IF EXISTS exchange_rates;
+
+ALTER TABLE rooms ADD COLUMN price_currency CHAR(3) NOT NULL DEFAULT 'USD';
+UPDATE rooms r SET price_currency = h.currency FROM hotels h WHERE h.id = r.hotel_id;
+
+ALTER TABLE hotels DROP COLUMN currency;
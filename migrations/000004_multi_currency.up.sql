@@ -0,0 +1,20 @@
+ALTER TABLE hotels ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+UPDATE hotels h
+SET currency = r.price_currency
+FROM (SELECT DISTINCT ON (hotel_id) hotel_id, price_currency FROM rooms ORDER BY hotel_id, id) r
+WHERE r.hotel_id = h.id;
+
+ALTER TABLE rooms DROP COLUMN price_currency;
+
+CREATE TABLE exchange_rates (
+    base_currency CHAR(3) NOT NULL,
+    quote_currency CHAR(3) NOT NULL,
+    rate NUMERIC(20, 10) NOT NULL CHECK (rate > 0),
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (base_currency, quote_currency)
+);
+
+ALTER TABLE bookings ADD COLUMN display_currency CHAR(3);
+ALTER TABLE bookings ADD COLUMN exchange_rate NUMERIC(20, 10);
+ALTER TABLE bookings ADD COLUMN display_total_amount BIGINT;
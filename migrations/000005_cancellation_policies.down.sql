@@ -0,0 +1,6 @@
+ALTER TABLE bookings DROP COLUMN refund_amount;
+ALTER TABLE bookings DROP COLUMN penalty_amount;
+ALTER TABLE bookings DROP COLUMN cancelled_at;
+ALTER TABLE bookings DROP COLUMN cancellation_policy;
+
+DROP TABLE IF EXISTS cancellation_policies;
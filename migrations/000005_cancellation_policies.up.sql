@@ -0,0 +1,22 @@
+CREATE TABLE cancellation_policies (
+    id SERIAL PRIMARY KEY,
+    hotel_id INT NOT NULL REFERENCES hotels(id) ON DELETE CASCADE,
+    room_id INT REFERENCES rooms(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    free_cancellation_days INT NOT NULL DEFAULT 0 CHECK (free_cancellation_days >= 0),
+    penalty_type VARCHAR(20) NOT NULL DEFAULT 'none'
+        CHECK (penalty_type IN ('none', 'percent', 'first_night', 'full')),
+    penalty_percent INT NOT NULL DEFAULT 0 CHECK (penalty_percent BETWEEN 0 AND 100),
+    non_refundable BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_cancellation_policies_hotel_default
+    ON cancellation_policies (hotel_id) WHERE room_id IS NULL;
+CREATE UNIQUE INDEX idx_cancellation_policies_room
+    ON cancellation_policies (room_id) WHERE room_id IS NOT NULL;
+
+ALTER TABLE bookings ADD COLUMN cancellation_policy JSONB;
+ALTER TABLE bookings ADD COLUMN cancelled_at TIMESTAMP;
+ALTER TABLE bookings ADD COLUMN penalty_amount BIGINT;
+ALTER TABLE bookings ADD COLUMN refund_amount BIGINT;
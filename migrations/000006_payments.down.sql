@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS payment_events;
+DROP TABLE IF EXISTS payments;
+
+ALTER TABLE bookings ALTER COLUMN status SET DEFAULT 'confirmed';
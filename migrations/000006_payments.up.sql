@@ -0,0 +1,29 @@
+ALTER TABLE bookings ALTER COLUMN status SET DEFAULT 'pending';
+
+CREATE TABLE payments (
+    id SERIAL PRIMARY KEY,
+    booking_id INT NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    provider VARCHAR(50) NOT NULL,
+    provider_ref VARCHAR(255) NOT NULL,
+    amount BIGINT NOT NULL,
+    captured_amount BIGINT NOT NULL DEFAULT 0,
+    refunded_amount BIGINT NOT NULL DEFAULT 0,
+    currency CHAR(3) NOT NULL,
+    status VARCHAR(30) NOT NULL,
+    confirm_url TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (provider, provider_ref)
+);
+
+CREATE INDEX idx_payments_booking ON payments (booking_id);
+
+CREATE TABLE payment_events (
+    id SERIAL PRIMARY KEY,
+    provider VARCHAR(50) NOT NULL,
+    event_id VARCHAR(255) NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL,
+    received_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (provider, event_id)
+);
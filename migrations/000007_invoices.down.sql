@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS invoices_immutable ON invoices;
+DROP FUNCTION IF EXISTS invoices_immutable();
+
+DROP TABLE IF EXISTS invoices;
+DROP TABLE IF EXISTS invoice_sequences;
+
+ALTER TABLE hotels DROP COLUMN tax_rate_bp;
@@ -0,0 +1,40 @@
+ALTER TABLE hotels ADD COLUMN tax_rate_bp INT NOT NULL DEFAULT 0
+    CHECK (tax_rate_bp BETWEEN 0 AND 10000);
+
+CREATE TABLE invoice_sequences (
+    hotel_id INT NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    last_number INT NOT NULL,
+    PRIMARY KEY (hotel_id, kind)
+);
+
+-- Invoices are accounting records: the document is a full snapshot, and they
+-- deliberately carry no foreign keys so that deleting a hotel, room or user
+-- never takes issued invoices with it.
+CREATE TABLE invoices (
+    id SERIAL PRIMARY KEY,
+    hotel_id INT NOT NULL,
+    booking_id INT NOT NULL,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('invoice', 'credit_note')),
+    sequence_number INT NOT NULL,
+    number VARCHAR(50) NOT NULL UNIQUE,
+    credited_invoice_id INT REFERENCES invoices(id),
+    currency CHAR(3) NOT NULL,
+    total_amount BIGINT NOT NULL,
+    document JSONB NOT NULL,
+    issued_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (hotel_id, kind, sequence_number)
+);
+
+CREATE UNIQUE INDEX idx_invoices_booking ON invoices (booking_id) WHERE kind = 'invoice';
+CREATE INDEX idx_invoices_credited ON invoices (credited_invoice_id);
+
+CREATE FUNCTION invoices_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'issued invoices cannot be changed; issue a credit note instead';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER invoices_immutable
+    BEFORE UPDATE OR DELETE ON invoices
+    FOR EACH ROW EXECUTE FUNCTION invoices_immutable();
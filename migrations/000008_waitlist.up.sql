@@ -0,0 +1,18 @@
+CREATE TABLE waitlist_entries (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    hotel_id INT NOT NULL REFERENCES hotels(id) ON DELETE CASCADE,
+    room_id INT REFERENCES rooms(id) ON DELETE CASCADE,
+    from_date DATE NOT NULL,
+    to_date DATE NOT NULL,
+    guests INT NOT NULL CHECK (guests > 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'waiting'
+        CHECK (status IN ('waiting', 'offered', 'claimed', 'expired', 'cancelled')),
+    offered_room_id INT REFERENCES rooms(id) ON DELETE SET NULL,
+    offer_expires_at TIMESTAMP,
+    booking_id INT REFERENCES bookings(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_waitlist_queue ON waitlist_entries (hotel_id, status, created_at);
+CREATE INDEX idx_waitlist_offers ON waitlist_entries (offered_room_id) WHERE status = 'offered';
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_bookings_room_dates;
+
+DROP TABLE IF EXISTS booking_walks;
+DROP TABLE IF EXISTS overbooking_allowances;
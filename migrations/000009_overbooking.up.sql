@@ -0,0 +1,23 @@
+CREATE TABLE overbooking_allowances (
+    id SERIAL PRIMARY KEY,
+    hotel_id INT NOT NULL REFERENCES hotels(id) ON DELETE CASCADE,
+    from_date DATE NOT NULL,
+    to_date DATE NOT NULL,
+    percent INT NOT NULL CHECK (percent BETWEEN 0 AND 100),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (from_date <= to_date)
+);
+
+CREATE INDEX idx_overbooking_allowances_hotel ON overbooking_allowances (hotel_id, from_date, to_date);
+
+CREATE TABLE booking_walks (
+    id SERIAL PRIMARY KEY,
+    booking_id INT NOT NULL UNIQUE REFERENCES bookings(id) ON DELETE CASCADE,
+    partner_name VARCHAR(255) NOT NULL,
+    partner_reference VARCHAR(255) NOT NULL DEFAULT '',
+    notes TEXT NOT NULL DEFAULT '',
+    walked_by INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_bookings_room_dates ON bookings (room_id, from_date, to_date);
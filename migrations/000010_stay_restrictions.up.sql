@@ -0,0 +1,18 @@
+CREATE TABLE stay_restrictions (
+    id SERIAL PRIMARY KEY,
+    hotel_id INT NOT NULL REFERENCES hotels(id) ON DELETE CASCADE,
+    room_id INT REFERENCES rooms(id) ON DELETE CASCADE,
+    from_date DATE NOT NULL,
+    to_date DATE NOT NULL,
+    days_of_week INT[] NOT NULL DEFAULT '{}',
+    min_stay INT NOT NULL DEFAULT 0 CHECK (min_stay >= 0),
+    max_stay INT NOT NULL DEFAULT 0 CHECK (max_stay >= 0),
+    closed_to_arrival BOOLEAN NOT NULL DEFAULT FALSE,
+    closed_to_departure BOOLEAN NOT NULL DEFAULT FALSE,
+    min_lead_days INT NOT NULL DEFAULT 0 CHECK (min_lead_days >= 0),
+    max_lead_days INT NOT NULL DEFAULT 0 CHECK (max_lead_days >= 0),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (from_date <= to_date)
+);
+
+CREATE INDEX idx_stay_restrictions_hotel ON stay_restrictions (hotel_id, from_date, to_date);
@@ -0,0 +1,12 @@
+CREATE TABLE room_blocks (
+    id SERIAL PRIMARY KEY,
+    room_id INT NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    from_date DATE NOT NULL,
+    to_date DATE NOT NULL,
+    reason VARCHAR(255) NOT NULL,
+    created_by INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (from_date < to_date)
+);
+
+CREATE INDEX idx_room_blocks_room_dates ON room_blocks (room_id, from_date, to_date);
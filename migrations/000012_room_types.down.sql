@@ -0,0 +1,18 @@
+DROP INDEX IF EXISTS idx_bookings_type_dates;
+
+DELETE FROM bookings WHERE room_id IS NULL;
+ALTER TABLE bookings DROP COLUMN room_type_id;
+
+ALTER TABLE rooms ADD COLUMN capacity INT;
+ALTER TABLE rooms ADD COLUMN price_amount BIGINT;
+
+UPDATE rooms r
+SET capacity = t.capacity, price_amount = t.price_amount
+FROM room_types t
+WHERE t.id = r.room_type_id;
+
+ALTER TABLE rooms ALTER COLUMN capacity SET NOT NULL;
+ALTER TABLE rooms ALTER COLUMN price_amount SET NOT NULL;
+ALTER TABLE rooms DROP COLUMN room_type_id;
+
+DROP TABLE IF EXISTS room_types;
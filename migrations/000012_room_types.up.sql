@@ -0,0 +1,42 @@
+CREATE TABLE room_types (
+    id SERIAL PRIMARY KEY,
+    hotel_id INT NOT NULL REFERENCES hotels(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    capacity INT NOT NULL CHECK (capacity > 0),
+    price_amount BIGINT NOT NULL CHECK (price_amount > 0),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (hotel_id, name)
+);
+
+-- Every capacity and price a hotel sells today becomes one of its types.
+INSERT INTO room_types (hotel_id, name, capacity, price_amount)
+SELECT DISTINCT hotel_id,
+       capacity || ' guests, ' || to_char(price_amount / 100.0, 'FM999999990.00'),
+       capacity,
+       price_amount
+FROM rooms
+WHERE hotel_id IS NOT NULL;
+
+ALTER TABLE rooms ADD COLUMN room_type_id INT REFERENCES room_types(id) ON DELETE CASCADE;
+
+UPDATE rooms r
+SET room_type_id = t.id
+FROM room_types t
+WHERE t.hotel_id = r.hotel_id AND t.capacity = r.capacity AND t.price_amount = r.price_amount;
+
+ALTER TABLE rooms ALTER COLUMN room_type_id SET NOT NULL;
+ALTER TABLE rooms DROP COLUMN capacity;
+ALTER TABLE rooms DROP COLUMN price_amount;
+
+-- Bookings reserve a type; the room is assigned before arrival.
+ALTER TABLE bookings ADD COLUMN room_type_id INT REFERENCES room_types(id) ON DELETE CASCADE;
+
+UPDATE bookings b
+SET room_type_id = r.room_type_id
+FROM rooms r
+WHERE r.id = b.room_id;
+
+ALTER TABLE bookings ALTER COLUMN room_type_id SET NOT NULL;
+
+CREATE INDEX idx_bookings_type_dates ON bookings (room_type_id, from_date, to_date);
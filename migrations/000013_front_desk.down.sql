@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_bookings_departures;
+DROP INDEX IF EXISTS idx_bookings_arrivals;
+
+UPDATE bookings SET status = 'confirmed' WHERE status IN ('checked_in', 'checked_out');
+
+ALTER TABLE rooms DROP COLUMN IF EXISTS housekeeping_status;
+
+ALTER TABLE bookings
+    DROP COLUMN IF EXISTS checked_out_by,
+    DROP COLUMN IF EXISTS checked_out_at,
+    DROP COLUMN IF EXISTS guest_id_document,
+    DROP COLUMN IF EXISTS checked_in_by,
+    DROP COLUMN IF EXISTS checked_in_at;
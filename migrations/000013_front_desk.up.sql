@@ -0,0 +1,13 @@
+ALTER TABLE bookings
+    ADD COLUMN checked_in_at TIMESTAMP,
+    ADD COLUMN checked_in_by INT REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN guest_id_document VARCHAR(100),
+    ADD COLUMN checked_out_at TIMESTAMP,
+    ADD COLUMN checked_out_by INT REFERENCES users(id) ON DELETE SET NULL;
+
+ALTER TABLE rooms
+    ADD COLUMN housekeeping_status VARCHAR(20) NOT NULL DEFAULT 'clean'
+        CHECK (housekeeping_status IN ('clean', 'dirty'));
+
+CREATE INDEX idx_bookings_arrivals ON bookings (from_date);
+CREATE INDEX idx_bookings_departures ON bookings (to_date);
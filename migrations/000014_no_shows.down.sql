@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_bookings_status_arrival;
+
+UPDATE bookings SET status = 'cancelled' WHERE status = 'no_show';
+
+ALTER TABLE hotels DROP COLUMN IF EXISTS no_show_cutoff_hour;
@@ -0,0 +1,5 @@
+ALTER TABLE hotels
+    ADD COLUMN no_show_cutoff_hour INT NOT NULL DEFAULT 24
+        CHECK (no_show_cutoff_hour BETWEEN 16 AND 48);
+
+CREATE INDEX idx_bookings_status_arrival ON bookings (status, from_date);
@@ -0,0 +1,14 @@
+ALTER TABLE bookings ADD COLUMN confirmation_code VARCHAR(16);
+
+-- Existing bookings get codes in the same HBK-XXXXXX format the service
+-- generates; the correlated subquery makes each row draw its own.
+UPDATE bookings b
+SET confirmation_code = 'HBK-' || (
+    SELECT string_agg(substr('23456789ABCDEFGHJKMNPQRSTUVWXYZ', 1 + floor(random() * 31)::int, 1), '')
+    FROM generate_series(1, 6)
+    WHERE b.id IS NOT NULL
+);
+
+ALTER TABLE bookings
+    ALTER COLUMN confirmation_code SET NOT NULL,
+    ADD CONSTRAINT bookings_confirmation_code_key UNIQUE (confirmation_code);
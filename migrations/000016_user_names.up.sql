@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN name VARCHAR(255) NOT NULL DEFAULT '';
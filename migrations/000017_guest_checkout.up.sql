@@ -0,0 +1,3 @@
+-- Guests who book without an account get a users row with role 'guest' and
+-- no password. Registering with the same email turns it into a full account.
+ALTER TABLE users ADD COLUMN phone VARCHAR(50) NOT NULL DEFAULT '';
@@ -0,0 +1,16 @@
+-- Responses to mutating requests sent with an Idempotency-Key header, kept so
+-- that retries get the first response instead of repeating the request.
+-- status_code stays NULL while the first request is still being handled.
+CREATE TABLE idempotency_keys (
+    id SERIAL PRIMARY KEY,
+    scope VARCHAR(50) NOT NULL,
+    idempotency_key VARCHAR(255) NOT NULL,
+    fingerprint CHAR(64) NOT NULL,
+    status_code INT,
+    content_type VARCHAR(255) NOT NULL DEFAULT '',
+    response_body BYTEA,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (scope, idempotency_key)
+);
+
+CREATE INDEX idx_idempotency_keys_created_at ON idempotency_keys (created_at);
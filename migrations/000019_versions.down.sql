@@ -0,0 +1,10 @@
+DROP TRIGGER IF EXISTS users_bump_version ON users;
+DROP TRIGGER IF EXISTS bookings_bump_version ON bookings;
+DROP TRIGGER IF EXISTS rooms_bump_version ON rooms;
+DROP TRIGGER IF EXISTS hotels_bump_version ON hotels;
+DROP FUNCTION IF EXISTS bump_version();
+
+ALTER TABLE users DROP COLUMN IF EXISTS version;
+ALTER TABLE bookings DROP COLUMN IF EXISTS version;
+ALTER TABLE rooms DROP COLUMN IF EXISTS version;
+ALTER TABLE hotels DROP COLUMN IF EXISTS version;
@@ -0,0 +1,23 @@
+-- Row versions for optimistic concurrency. Every update bumps the version,
+-- whoever makes it, so clients sending If-Match with a version they read
+-- earlier are refused when the row has changed since.
+ALTER TABLE hotels ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE rooms ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE bookings ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE users ADD COLUMN version INT NOT NULL DEFAULT 1;
+
+CREATE FUNCTION bump_version() RETURNS trigger AS $$
+BEGIN
+    NEW.version := OLD.version + 1;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER hotels_bump_version BEFORE UPDATE ON hotels
+    FOR EACH ROW EXECUTE FUNCTION bump_version();
+CREATE TRIGGER rooms_bump_version BEFORE UPDATE ON rooms
+    FOR EACH ROW EXECUTE FUNCTION bump_version();
+CREATE TRIGGER bookings_bump_version BEFORE UPDATE ON bookings
+    FOR EACH ROW EXECUTE FUNCTION bump_version();
+CREATE TRIGGER users_bump_version BEFORE UPDATE ON users
+    FOR EACH ROW EXECUTE FUNCTION bump_version();
@@ -0,0 +1,4 @@
+ALTER TABLE bookings DROP COLUMN IF EXISTS loyalty_discount_amount;
+ALTER TABLE bookings DROP COLUMN IF EXISTS points_redeemed;
+
+DROP TABLE IF EXISTS loyalty_entries;
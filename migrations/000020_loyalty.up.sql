@@ -0,0 +1,22 @@
+-- Loyalty ledger. A member's balance is the sum of their entries. A
+-- cancelled booking's entries are undone by reversal entries pointing at
+-- the entries they cancel, so the history shows both.
+CREATE TABLE loyalty_entries (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    booking_id INT REFERENCES bookings(id) ON DELETE SET NULL,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('earn', 'redeem', 'reversal', 'expiry')),
+    points INT NOT NULL,
+    description VARCHAR(255) NOT NULL,
+    reverses_entry_id INT UNIQUE REFERENCES loyalty_entries(id),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_loyalty_entries_user ON loyalty_entries (user_id, created_at);
+CREATE INDEX idx_loyalty_entries_booking ON loyalty_entries (booking_id);
+-- A stay earns points once.
+CREATE UNIQUE INDEX idx_loyalty_entries_earn_once ON loyalty_entries (booking_id) WHERE kind = 'earn';
+
+-- Points redeemed against a booking and the discount they bought.
+ALTER TABLE bookings ADD COLUMN points_redeemed INT NOT NULL DEFAULT 0;
+ALTER TABLE bookings ADD COLUMN loyalty_discount_amount BIGINT;
@@ -0,0 +1,22 @@
+-- Guest reviews. Only a stay that checked out can be reviewed, once.
+-- Hidden reviews are kept for the record but left out of ratings.
+CREATE TABLE reviews (
+    id SERIAL PRIMARY KEY,
+    booking_id INT NOT NULL UNIQUE REFERENCES bookings(id) ON DELETE CASCADE,
+    hotel_id INT NOT NULL REFERENCES hotels(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    cleanliness SMALLINT NOT NULL CHECK (cleanliness BETWEEN 1 AND 5),
+    location SMALLINT NOT NULL CHECK (location BETWEEN 1 AND 5),
+    service SMALLINT NOT NULL CHECK (service BETWEEN 1 AND 5),
+    comment TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'published' CHECK (status IN ('published', 'hidden')),
+    moderation_reason VARCHAR(255) NOT NULL DEFAULT '',
+    moderated_by INT REFERENCES users(id) ON DELETE SET NULL,
+    moderated_at TIMESTAMP,
+    reply TEXT,
+    replied_by INT REFERENCES users(id) ON DELETE SET NULL,
+    replied_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_reviews_hotel ON reviews (hotel_id, status, created_at);
@@ -0,0 +1,3 @@
+ALTER TABLE bookings DROP COLUMN IF EXISTS extras_amount;
+DROP TABLE IF EXISTS booking_extras;
+DROP TABLE IF EXISTS extras;
@@ -0,0 +1,40 @@
+-- Extras a hotel sells alongside its rooms, e.g. breakfast, parking or a
+-- crib. daily_inventory limits how many can be sold for any one night;
+-- NULL means unlimited.
+CREATE TABLE extras (
+    id SERIAL PRIMARY KEY,
+    hotel_id INT NOT NULL REFERENCES hotels(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    pricing VARCHAR(20) NOT NULL CHECK (pricing IN ('per_stay', 'per_night', 'per_guest')),
+    price_amount BIGINT NOT NULL CHECK (price_amount >= 0),
+    daily_inventory INT CHECK (daily_inventory >= 0),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_extras_hotel ON extras (hotel_id);
+
+-- Extras on a booking, priced when they were added. booking_id is NULL
+-- while the extra is held for a booking still being made. Each line holds
+-- its quantity of the extra's inventory on every night from from_date up
+-- to, but not including, to_date.
+CREATE TABLE booking_extras (
+    id SERIAL PRIMARY KEY,
+    booking_id INT REFERENCES bookings(id) ON DELETE CASCADE,
+    extra_id INT NOT NULL REFERENCES extras(id) ON DELETE RESTRICT,
+    name VARCHAR(100) NOT NULL,
+    pricing VARCHAR(20) NOT NULL,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    from_date DATE NOT NULL,
+    to_date DATE NOT NULL,
+    unit_amount BIGINT NOT NULL,
+    total_amount BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_booking_extras_booking ON booking_extras (booking_id);
+CREATE INDEX idx_booking_extras_extra ON booking_extras (extra_id, from_date, to_date);
+
+-- The extras' share of the booking total.
+ALTER TABLE bookings ADD COLUMN extras_amount BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS message_attachments;
+DROP TABLE IF EXISTS booking_messages;
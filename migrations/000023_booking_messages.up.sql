@@ -0,0 +1,28 @@
+-- Messages between a guest and the hotel about a booking. from_party says
+-- which side wrote it; read_at is set when the other side first reads it.
+CREATE TABLE booking_messages (
+    id SERIAL PRIMARY KEY,
+    booking_id INT NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    sender_id INT REFERENCES users(id) ON DELETE SET NULL,
+    from_party VARCHAR(10) NOT NULL CHECK (from_party IN ('guest', 'hotel')),
+    body TEXT NOT NULL DEFAULT '',
+    read_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_booking_messages_booking ON booking_messages (booking_id, created_at);
+CREATE INDEX idx_booking_messages_unread ON booking_messages (booking_id) WHERE read_at IS NULL;
+
+-- Files sent with a message. The content lives on local disk under
+-- storage_key; the database keeps only what is needed to serve it.
+CREATE TABLE message_attachments (
+    id SERIAL PRIMARY KEY,
+    message_id INT NOT NULL REFERENCES booking_messages(id) ON DELETE CASCADE,
+    filename VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    storage_key VARCHAR(100) NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_message_attachments_message ON message_attachments (message_id);
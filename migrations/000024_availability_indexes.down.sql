@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_rooms_room_type;
+DROP INDEX IF EXISTS idx_rooms_hotel;
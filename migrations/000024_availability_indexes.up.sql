@@ -0,0 +1,4 @@
+-- Availability listings read the rooms of many hotels, and of their types,
+-- in one query.
+CREATE INDEX idx_rooms_hotel ON rooms (hotel_id);
+CREATE INDEX idx_rooms_room_type ON rooms (room_type_id);
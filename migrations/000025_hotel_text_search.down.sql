@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_hotels_search;
+ALTER TABLE hotels DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE hotels DROP COLUMN IF EXISTS description;
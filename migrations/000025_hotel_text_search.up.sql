@@ -0,0 +1,12 @@
+-- Hotels get a free-text description, and a search vector over their name,
+-- city and description for full-text search. Names weigh most and
+-- descriptions least when matches are ranked.
+ALTER TABLE hotels ADD COLUMN description TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE hotels ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', name), 'A') ||
+    setweight(to_tsvector('english', city), 'B') ||
+    setweight(to_tsvector('english', description), 'C')
+) STORED;
+
+CREATE INDEX idx_hotels_search ON hotels USING GIN (search_vector);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_hotels_coordinates;
+ALTER TABLE hotels DROP CONSTRAINT IF EXISTS hotels_coordinates_together;
+ALTER TABLE hotels DROP COLUMN IF EXISTS longitude;
+ALTER TABLE hotels DROP COLUMN IF EXISTS latitude;
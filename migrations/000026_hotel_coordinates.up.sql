@@ -0,0 +1,10 @@
+-- Where each hotel is, in decimal degrees, for searches by distance. Both
+-- are NULL for hotels whose position is not known. Distance searches narrow
+-- the candidates to a box of latitudes and longitudes with the index before
+-- working out distances.
+ALTER TABLE hotels
+    ADD COLUMN latitude DOUBLE PRECISION CHECK (latitude BETWEEN -90 AND 90),
+    ADD COLUMN longitude DOUBLE PRECISION CHECK (longitude BETWEEN -180 AND 180),
+    ADD CONSTRAINT hotels_coordinates_together CHECK ((latitude IS NULL) = (longitude IS NULL));
+
+CREATE INDEX idx_hotels_coordinates ON hotels (latitude, longitude) WHERE latitude IS NOT NULL;
@@ -0,0 +1,38 @@
+-- The amenity catalog. Hotel amenities (wifi, a pool) belong to the whole
+-- hotel; room amenities (a sea view, an accessible bathroom) to the room
+-- types that have them, and so to every room of those types. Searches
+-- filter by code.
+CREATE TABLE amenities (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(50) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    scope VARCHAR(10) NOT NULL CHECK (scope IN ('hotel', 'room')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE hotel_amenities (
+    hotel_id INT NOT NULL REFERENCES hotels(id) ON DELETE CASCADE,
+    amenity_id INT NOT NULL REFERENCES amenities(id) ON DELETE CASCADE,
+    PRIMARY KEY (hotel_id, amenity_id)
+);
+
+CREATE TABLE room_type_amenities (
+    room_type_id INT NOT NULL REFERENCES room_types(id) ON DELETE CASCADE,
+    amenity_id INT NOT NULL REFERENCES amenities(id) ON DELETE CASCADE,
+    PRIMARY KEY (room_type_id, amenity_id)
+);
+
+CREATE INDEX idx_hotel_amenities_amenity ON hotel_amenities (amenity_id);
+CREATE INDEX idx_room_type_amenities_amenity ON room_type_amenities (amenity_id);
+
+INSERT INTO amenities (code, name, scope) VALUES
+    ('wifi', 'Free Wi-Fi', 'hotel'),
+    ('pool', 'Swimming pool', 'hotel'),
+    ('parking', 'Parking', 'hotel'),
+    ('gym', 'Fitness centre', 'hotel'),
+    ('restaurant', 'Restaurant', 'hotel'),
+    ('pets_allowed', 'Pets allowed', 'hotel'),
+    ('sea_view', 'Sea view', 'room'),
+    ('accessible_bathroom', 'Accessible bathroom', 'room'),
+    ('air_conditioning', 'Air conditioning', 'room'),
+    ('balcony', 'Balcony', 'room');
@@ -0,0 +1,14 @@
+-- Type rules go back to the hotel-wide scope; rooms cannot be recovered.
+DROP INDEX idx_cancellation_policies_room_type;
+DROP INDEX idx_cancellation_policies_hotel_default;
+DELETE FROM cancellation_policies WHERE room_type_id IS NOT NULL;
+ALTER TABLE cancellation_policies DROP COLUMN room_type_id;
+ALTER TABLE cancellation_policies ADD COLUMN room_id INT REFERENCES rooms(id) ON DELETE CASCADE;
+CREATE UNIQUE INDEX idx_cancellation_policies_hotel_default
+    ON cancellation_policies (hotel_id) WHERE room_id IS NULL;
+CREATE UNIQUE INDEX idx_cancellation_policies_room
+    ON cancellation_policies (room_id) WHERE room_id IS NOT NULL;
+
+DELETE FROM stay_restrictions WHERE room_type_id IS NOT NULL;
+ALTER TABLE stay_restrictions DROP COLUMN room_type_id;
+ALTER TABLE stay_restrictions ADD COLUMN room_id INT REFERENCES rooms(id) ON DELETE CASCADE;
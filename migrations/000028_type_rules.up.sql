@@ -0,0 +1,32 @@
+-- Bookings reserve a room type, not a room, so per-room rules never matched
+-- them. Restrictions and cancellation policies now apply to a room type;
+-- rules set for one room move to its type.
+ALTER TABLE stay_restrictions ADD COLUMN room_type_id INT REFERENCES room_types(id) ON DELETE CASCADE;
+
+UPDATE stay_restrictions s
+SET room_type_id = r.room_type_id
+FROM rooms r
+WHERE r.id = s.room_id;
+
+ALTER TABLE stay_restrictions DROP COLUMN room_id;
+
+ALTER TABLE cancellation_policies ADD COLUMN room_type_id INT REFERENCES room_types(id) ON DELETE CASCADE;
+
+UPDATE cancellation_policies p
+SET room_type_id = r.room_type_id
+FROM rooms r
+WHERE r.id = p.room_id;
+
+-- A type can have one policy; where its rooms had several, the oldest wins.
+DELETE FROM cancellation_policies p
+USING cancellation_policies older
+WHERE p.room_type_id = older.room_type_id AND older.id < p.id;
+
+DROP INDEX idx_cancellation_policies_room;
+ALTER TABLE cancellation_policies DROP COLUMN room_id;
+
+DROP INDEX idx_cancellation_policies_hotel_default;
+CREATE UNIQUE INDEX idx_cancellation_policies_hotel_default
+    ON cancellation_policies (hotel_id) WHERE room_type_id IS NULL;
+CREATE UNIQUE INDEX idx_cancellation_policies_room_type
+    ON cancellation_policies (room_type_id) WHERE room_type_id IS NOT NULL;
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS room_types_bump_version ON room_types;
+ALTER TABLE room_types DROP COLUMN version;
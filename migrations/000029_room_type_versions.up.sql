@@ -0,0 +1,6 @@
+-- Room types carry capacity and price, so they get versions for optimistic
+-- concurrency like the other editable rows.
+ALTER TABLE room_types ADD COLUMN version INT NOT NULL DEFAULT 1;
+
+CREATE TRIGGER room_types_bump_version BEFORE UPDATE ON room_types
+    FOR EACH ROW EXECUTE FUNCTION bump_version();
@@ -0,0 +1,9 @@
+-- Room amenities a single room has on top of its type's, e.g. the one
+-- double on the top floor with a sea view.
+CREATE TABLE room_amenities (
+    room_id INT NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    amenity_id INT NOT NULL REFERENCES amenities(id) ON DELETE CASCADE,
+    PRIMARY KEY (room_id, amenity_id)
+);
+
+CREATE INDEX idx_room_amenities_amenity ON room_amenities (amenity_id);
@@ -0,0 +1,6 @@
+-- Staff work at one hotel, and only see and act on that hotel's bookings,
+-- rooms, reviews and messages. Staff without a hotel can do nothing at the
+-- front desk; admins work at every hotel and have none.
+ALTER TABLE users ADD COLUMN hotel_id INT REFERENCES hotels(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_users_hotel ON users (hotel_id) WHERE hotel_id IS NOT NULL;
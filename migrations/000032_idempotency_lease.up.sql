@@ -0,0 +1,3 @@
+-- When the request holding a key last started. A request still unfinished
+-- long after that died with its server, and a retry may take the key over.
+ALTER TABLE idempotency_keys ADD COLUMN reserved_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
@@ -0,0 +1,2 @@
+ALTER TABLE waitlist_entries ADD COLUMN room_id INT REFERENCES rooms(id) ON DELETE CASCADE;
+ALTER TABLE waitlist_entries DROP COLUMN room_type_id;
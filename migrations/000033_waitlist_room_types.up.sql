@@ -0,0 +1,14 @@
+-- Waitlist entries queue for a room type rather than a single room, so a
+-- unit of the type freed by any of its rooms can be offered. Entries that
+-- named a room wait for its type; older hotel-wide entries keep a NULL type
+-- and take any type that fits.
+ALTER TABLE waitlist_entries ADD COLUMN room_type_id INT REFERENCES room_types(id) ON DELETE CASCADE;
+
+UPDATE waitlist_entries w
+SET room_type_id = r.room_type_id
+FROM rooms r
+WHERE r.id = w.room_id;
+
+ALTER TABLE waitlist_entries DROP COLUMN room_id;
+
+CREATE INDEX idx_waitlist_room_types ON waitlist_entries (room_type_id, status, created_at);